@@ -23,4 +23,15 @@ const (
 	// AccountLinkingAction means that the event concerns changes in account linking
 	// status.
 	AccountLinkingAction
+	// PassThreadControlAction means that thread control was passed to the app
+	// through the handover protocol.
+	PassThreadControlAction
+	// RequestThreadControlAction means that a secondary receiver asked the app
+	// for thread control through the handover protocol.
+	RequestThreadControlAction
+	// PaymentAction means that a user paid with the Buy button.
+	PaymentAction
+	// PreCheckoutAction means that a user tapped the Pay button, before
+	// paying.
+	PreCheckoutAction
 )
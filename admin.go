@@ -0,0 +1,164 @@
+package messenger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recentErrorsSize is the number of errors kept for the admin API.
+const recentErrorsSize = 50
+
+// RecentError is an error which occurred while handling webhooks or sending
+// messages.
+type RecentError struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// errorRing keeps the most recent errors. The zero value is ready to use.
+type errorRing struct {
+	mu     sync.Mutex
+	errors []RecentError
+	next   int
+}
+
+func (e *errorRing) add(now time.Time, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	re := RecentError{Time: now, Message: err.Error()}
+	if len(e.errors) < recentErrorsSize {
+		e.errors = append(e.errors, re)
+		return
+	}
+
+	e.errors[e.next] = re
+	e.next = (e.next + 1) % recentErrorsSize
+}
+
+// list returns the recorded errors, oldest first.
+func (e *errorRing) list() []RecentError {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	list := make([]RecentError, 0, len(e.errors))
+	list = append(list, e.errors[e.next:]...)
+	return append(list, e.errors[:e.next]...)
+}
+
+// recordError keeps err for the admin API.
+func (m *Messenger) recordError(err error) {
+	m.recentErrors.add(m.now(), err)
+}
+
+// RecentErrors returns the most recent errors encountered by the Messenger,
+// oldest first.
+func (m *Messenger) RecentErrors() []RecentError {
+	return m.recentErrors.list()
+}
+
+// HandlerCounts returns the number of handlers registered per event type.
+func (m *Messenger) HandlerCounts() map[string]int {
+	return map[string]int{
+		"message":         len(m.messageHandlers),
+		"fallback":        len(m.fallbackHandlers),
+		"echo":            len(m.echoHandlers),
+		"delivery":        len(m.deliveryHandlers),
+		"read":            len(m.readHandlers),
+		"postback":        len(m.postBackHandlers),
+		"optin":           len(m.optInHandlers),
+		"referral":        len(m.referralHandlers),
+		"account_linking": len(m.accountLinkingHandlers),
+		"order":           len(m.orderHandlers),
+		"standby":         len(m.standbyHandlers),
+		"handover":        len(m.handoverHandlers),
+		"thread_request":  len(m.threadRequestHandlers),
+		"payment":         len(m.paymentHandlers),
+		"pre_checkout":    len(m.preCheckoutHandlers),
+	}
+}
+
+// AdminHandler returns an HTTP handler exposing runtime information about the
+// Messenger, meant to be served on a separate, private port:
+//
+//	GET  /handlers  number of registered handlers per event type
+//	GET  /errors    most recent errors
+//	GET  /stats     events per second, handler latencies, Outbox depth and
+//	                rate limit usage
+//	GET  /payloads  webhook requests captured according to CaptureRate
+//	GET  /unknown   events with fields which are not supported yet
+//	POST /send      send a test message, form values "psid" and "text"
+//
+// Every request must carry the header "Authorization: Bearer <token>". An
+// empty token denies all requests.
+func (m *Messenger) AdminHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/handlers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.HandlerCounts())
+	})
+
+	mux.HandleFunc("/errors", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.RecentErrors())
+	})
+
+	mux.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.EventStats())
+	})
+
+	mux.HandleFunc("/payloads", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.CapturedPayloads())
+	})
+
+	mux.HandleFunc("/unknown", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.UnknownEvents())
+	})
+
+	mux.HandleFunc("/send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+
+		psid, err := strconv.ParseInt(r.FormValue("psid"), 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid psid"})
+			return
+		}
+
+		err = m.Send(Recipient{ID: psid}, r.FormValue("text"), UpdateType)
+		if err != nil {
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"status": "sent"})
+	})
+
+	return adminAuth(token, mux)
+}
+
+// adminAuth only lets through requests bearing the given token.
+func adminAuth(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		given := strings.TrimPrefix(auth, "Bearer ")
+		if token == "" || given == auth || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
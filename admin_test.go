@@ -0,0 +1,82 @@
+package messenger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_AdminHandler(t *testing.T) {
+	m := New(Options{})
+	m.HandleMessage(func(Message, *Response) {})
+	for i := 0; i < recentErrorsSize+5; i++ {
+		m.recordError(fmt.Errorf("error %d", i))
+	}
+
+	h := m.AdminHandler("secret")
+
+	t.Run("unauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/handlers", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		req := httptest.NewRequest("GET", "/handlers", nil)
+		req.Header.Set("Authorization", "secret")
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("handlers", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/handlers", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var counts map[string]int
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &counts))
+		assert.Equal(t, 1, counts["message"])
+		assert.Equal(t, 0, counts["postback"])
+		assert.Equal(t, 0, counts["fallback"])
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/errors", nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var errs []RecentError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errs))
+		require.Len(t, errs, recentErrorsSize)
+		assert.Equal(t, "error 5", errs[0].Message)
+		assert.Equal(t, fmt.Sprintf("error %d", recentErrorsSize+4), errs[len(errs)-1].Message)
+	})
+}
+
+func TestMessenger_AdminStats(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	graph.status = http.StatusServiceUnavailable
+	graph.header = http.Header{"X-App-Usage": {`{"call_count":28,"total_time":25,"total_cputime":25}`}}
+	m := New(Options{HTTPClient: graph.client(), Outbox: NewOutbox(NewMemoryStore())})
+	assert.Equal(t, ErrQueued, m.Response(111).Text("hello", ResponseType))
+
+	req := httptest.NewRequest("GET", "/stats", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	m.AdminHandler("secret").ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var stats EventStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+	assert.Equal(t, 1, stats.OutboxDepth)
+	assert.Equal(t, `{"call_count":28,"total_time":25,"total_cputime":25}`, stats.RateLimits.AppUsage)
+	assert.Empty(t, stats.RateLimits.PageUsage)
+}
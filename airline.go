@@ -0,0 +1,199 @@
+package messenger
+
+// AirlineTimeLayout is the layout of the times of airline templates, in the
+// local time of the airport.
+const AirlineTimeLayout = "2006-01-02T15:04"
+
+// SendTemplateMessage is a message template whose payload is not a
+// StructuredMessagePayload, such as the airline templates.
+type SendTemplateMessage struct {
+	MessagingType MessagingType       `json:"messaging_type,omitempty"`
+	Recipient     Recipient           `json:"recipient"`
+	Message       TemplateMessageData `json:"message"`
+	Tag           string              `json:"tag,omitempty"`
+	PersonaID     string              `json:"persona_id,omitempty"`
+}
+
+// TemplateMessageData is the message of a SendTemplateMessage.
+type TemplateMessageData struct {
+	Attachment TemplateAttachment `json:"attachment"`
+	Metadata   string             `json:"metadata,omitempty"`
+}
+
+// TemplateAttachment is the attachment of a SendTemplateMessage.
+type TemplateAttachment struct {
+	// Type is always template.
+	Type string `json:"type"`
+	// Payload is the template, such as an AirlineItinerary.
+	Payload interface{} `json:"payload"`
+}
+
+// Validate checks that the message can be sent.
+func (m SendTemplateMessage) Validate() error {
+	return validateMessagingType(m.Recipient, m.MessagingType, m.Tag)
+}
+
+// AirlineItinerary is the payload of an airline itinerary template, a
+// summary of a booking.
+type AirlineItinerary struct {
+	// TemplateType is set by Response.AirlineItineraryTemplate.
+	TemplateType string `json:"template_type"`
+	// IntroMessage is shown above the itinerary.
+	IntroMessage string `json:"intro_message"`
+	// Locale is the locale of the itinerary, such as "en_US".
+	Locale string `json:"locale"`
+	// ThemeColor is the color of the template, such as "#009ddc".
+	ThemeColor string `json:"theme_color,omitempty"`
+	// PNRNumber is the booking number.
+	PNRNumber string `json:"pnr_number"`
+	// Passengers are the passengers of the booking.
+	Passengers []AirlinePassenger `json:"passenger_info"`
+	// Flights are the segments of the booking.
+	Flights []AirlineFlight `json:"flight_info"`
+	// PassengerSegments are the seats and products of each passenger on
+	// each segment.
+	PassengerSegments []AirlinePassengerSegment `json:"passenger_segment_info"`
+	// PriceInfo are additional lines of the price, such as fuel surcharges.
+	PriceInfo []AirlinePrice `json:"price_info,omitempty"`
+	// BasePrice is the price before taxes.
+	BasePrice float64 `json:"base_price,omitempty"`
+	// Tax is the amount of the taxes.
+	Tax float64 `json:"tax,omitempty"`
+	// TotalPrice is the price of the booking.
+	TotalPrice float64 `json:"total_price"`
+	// Currency is an ISO 4217 code such as "USD".
+	Currency string `json:"currency"`
+}
+
+// AirlinePassenger is a passenger of an AirlineItinerary.
+type AirlinePassenger struct {
+	// PassengerID identifies the passenger within the itinerary.
+	PassengerID  string `json:"passenger_id"`
+	TicketNumber string `json:"ticket_number,omitempty"`
+	Name         string `json:"name"`
+}
+
+// AirlineFlight is a flight of an airline template.
+type AirlineFlight struct {
+	// ConnectionID groups the segments of a connecting flight.
+	ConnectionID string `json:"connection_id,omitempty"`
+	// SegmentID identifies the segment within the itinerary.
+	SegmentID    string          `json:"segment_id,omitempty"`
+	FlightNumber string          `json:"flight_number"`
+	AircraftType string          `json:"aircraft_type,omitempty"`
+	Departure    AirlineAirport  `json:"departure_airport"`
+	Arrival      AirlineAirport  `json:"arrival_airport"`
+	Schedule     AirlineSchedule `json:"flight_schedule"`
+	TravelClass  string          `json:"travel_class,omitempty"`
+}
+
+// Travel classes of an AirlineFlight.
+const (
+	TravelClassEconomy  = "economy"
+	TravelClassBusiness = "business"
+	TravelClassFirst    = "first_class"
+)
+
+// AirlineAirport is the departure or arrival airport of a flight.
+type AirlineAirport struct {
+	// AirportCode is the IATA code of the airport, such as "SFO".
+	AirportCode string `json:"airport_code"`
+	City        string `json:"city"`
+	Terminal    string `json:"terminal,omitempty"`
+	Gate        string `json:"gate,omitempty"`
+}
+
+// AirlineSchedule are the times of a flight, formatted with
+// AirlineTimeLayout.
+type AirlineSchedule struct {
+	BoardingTime  string `json:"boarding_time,omitempty"`
+	DepartureTime string `json:"departure_time"`
+	ArrivalTime   string `json:"arrival_time,omitempty"`
+}
+
+// AirlinePassengerSegment is the seat and products of a passenger on a
+// segment.
+type AirlinePassengerSegment struct {
+	SegmentID   string               `json:"segment_id"`
+	PassengerID string               `json:"passenger_id"`
+	Seat        string               `json:"seat"`
+	SeatType    string               `json:"seat_type"`
+	ProductInfo []AirlineProductInfo `json:"product_info,omitempty"`
+}
+
+// AirlineProductInfo is a product bought by a passenger, such as a meal.
+type AirlineProductInfo struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// AirlinePrice is a line of the price of an AirlineItinerary.
+type AirlinePrice struct {
+	Title    string  `json:"title"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+// Update types of an AirlineUpdate.
+const (
+	AirlineUpdateDelay        = "delay"
+	AirlineUpdateGateChange   = "gate_change"
+	AirlineUpdateCancellation = "cancellation"
+)
+
+// AirlineUpdate is the payload of an airline flight update template, a
+// notification of a change to a flight.
+type AirlineUpdate struct {
+	// TemplateType is set by Response.AirlineUpdateTemplate.
+	TemplateType string `json:"template_type"`
+	// IntroMessage is shown above the update.
+	IntroMessage string `json:"intro_message,omitempty"`
+	// UpdateType is AirlineUpdateDelay, AirlineUpdateGateChange or
+	// AirlineUpdateCancellation.
+	UpdateType string `json:"update_type"`
+	// Locale is the locale of the update, such as "en_US".
+	Locale string `json:"locale"`
+	// ThemeColor is the color of the template, such as "#009ddc".
+	ThemeColor string `json:"theme_color,omitempty"`
+	// PNRNumber is the booking number.
+	PNRNumber string `json:"pnr_number,omitempty"`
+	// Flight is the flight as updated.
+	Flight AirlineFlight `json:"update_flight_info"`
+}
+
+// AirlineItineraryTemplate sends the summary of a booking.
+func (r *Response) AirlineItineraryTemplate(itinerary AirlineItinerary, messagingType MessagingType, tags ...string) error {
+	itinerary.TemplateType = "airline_itinerary"
+	return r.templateMessage(itinerary, messagingType, tags...)
+}
+
+// AirlineUpdateTemplate notifies the user of a change to their flight, such
+// as a delay or a gate change. Updates are usually sent outside of the 24
+// hour window, with the POST_PURCHASE_UPDATE tag.
+func (r *Response) AirlineUpdateTemplate(update AirlineUpdate, messagingType MessagingType, tags ...string) error {
+	update.TemplateType = "airline_update"
+	return r.templateMessage(update, messagingType, tags...)
+}
+
+// templateMessage sends a template whose payload is not a
+// StructuredMessagePayload.
+func (r *Response) templateMessage(payload interface{}, messagingType MessagingType, tags ...string) error {
+	var tag string
+	if len(tags) > 0 {
+		tag = tags[0]
+	}
+
+	m := SendTemplateMessage{
+		MessagingType: messagingType,
+		Recipient:     r.to,
+		Message: TemplateMessageData{
+			Attachment: TemplateAttachment{
+				Type:    "template",
+				Payload: payload,
+			},
+		},
+		Tag:       tag,
+		PersonaID: r.persona,
+	}
+	return r.DispatchMessage(&m)
+}
@@ -0,0 +1,114 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestResponse_AirlineItineraryTemplate(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	err := m.Response(fixturePSID).AirlineItineraryTemplate(AirlineItinerary{
+		IntroMessage: "Here is your flight itinerary.",
+		Locale:       "en_US",
+		PNRNumber:    "ABCDEF",
+		Passengers:   []AirlinePassenger{{PassengerID: "p001", Name: "Farbound Smith Jr"}},
+		Flights: []AirlineFlight{{
+			ConnectionID: "c001",
+			SegmentID:    "s001",
+			FlightNumber: "KL9123",
+			Departure:    AirlineAirport{AirportCode: "SFO", City: "San Francisco"},
+			Arrival:      AirlineAirport{AirportCode: "SLC", City: "Salt Lake City"},
+			Schedule:     AirlineSchedule{DepartureTime: "2016-01-02T19:45", ArrivalTime: "2016-01-02T21:20"},
+			TravelClass:  TravelClassBusiness,
+		}},
+		PassengerSegments: []AirlinePassengerSegment{{
+			SegmentID:   "s001",
+			PassengerID: "p001",
+			Seat:        "12A",
+			SeatType:    "Business",
+			ProductInfo: []AirlineProductInfo{{Title: "Lounge", Value: "Complimentary lounge access"}},
+		}},
+		TotalPrice: 14003,
+		Currency:   "USD",
+	}, UpdateType)
+	require.NoError(t, err)
+
+	require.Equal(t, 1, graph.count())
+	assert.JSONEq(t, `{
+		"messaging_type": "UPDATE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {"attachment": {"type": "template", "payload": {
+			"template_type": "airline_itinerary",
+			"intro_message": "Here is your flight itinerary.",
+			"locale": "en_US",
+			"pnr_number": "ABCDEF",
+			"passenger_info": [{"passenger_id": "p001", "name": "Farbound Smith Jr"}],
+			"flight_info": [{
+				"connection_id": "c001",
+				"segment_id": "s001",
+				"flight_number": "KL9123",
+				"departure_airport": {"airport_code": "SFO", "city": "San Francisco"},
+				"arrival_airport": {"airport_code": "SLC", "city": "Salt Lake City"},
+				"flight_schedule": {"departure_time": "2016-01-02T19:45", "arrival_time": "2016-01-02T21:20"},
+				"travel_class": "business"
+			}],
+			"passenger_segment_info": [{
+				"segment_id": "s001",
+				"passenger_id": "p001",
+				"seat": "12A",
+				"seat_type": "Business",
+				"product_info": [{"title": "Lounge", "value": "Complimentary lounge access"}]
+			}],
+			"total_price": 14003,
+			"currency": "USD"
+		}}}
+	}`, graph.bodies[0])
+
+	err = m.Response(fixturePSID).WithSurface(SurfaceInstagram).AirlineItineraryTemplate(AirlineItinerary{}, UpdateType)
+	assert.True(t, xerrors.Is(err, ErrUnsupportedOnSurface))
+	assert.Equal(t, 1, graph.count())
+}
+
+func TestResponse_AirlineUpdateTemplate(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	err := m.Response(fixturePSID).AirlineUpdateTemplate(AirlineUpdate{
+		IntroMessage: "Your flight is delayed",
+		UpdateType:   AirlineUpdateDelay,
+		Locale:       "en_US",
+		PNRNumber:    "CF23G2",
+		Flight: AirlineFlight{
+			FlightNumber: "KL123",
+			Departure:    AirlineAirport{AirportCode: "SFO", City: "San Francisco", Terminal: "T4", Gate: "G8"},
+			Arrival:      AirlineAirport{AirportCode: "AMS", City: "Amsterdam"},
+			Schedule:     AirlineSchedule{BoardingTime: "2015-12-26T10:30", DepartureTime: "2015-12-26T11:30"},
+		},
+	}, MessageTagType, "POST_PURCHASE_UPDATE")
+	require.NoError(t, err)
+
+	require.Equal(t, 1, graph.count())
+	assert.JSONEq(t, `{
+		"messaging_type": "MESSAGE_TAG",
+		"tag": "POST_PURCHASE_UPDATE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {"attachment": {"type": "template", "payload": {
+			"template_type": "airline_update",
+			"intro_message": "Your flight is delayed",
+			"update_type": "delay",
+			"locale": "en_US",
+			"pnr_number": "CF23G2",
+			"update_flight_info": {
+				"flight_number": "KL123",
+				"departure_airport": {"airport_code": "SFO", "city": "San Francisco", "terminal": "T4", "gate": "G8"},
+				"arrival_airport": {"airport_code": "AMS", "city": "Amsterdam"},
+				"flight_schedule": {"boarding_time": "2015-12-26T10:30", "departure_time": "2015-12-26T11:30"}
+			}
+		}}}
+	}`, graph.bodies[0])
+}
@@ -0,0 +1,148 @@
+package messenger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default thresholds of AlertOptions.
+const (
+	DefaultAlertThreshold   = 10
+	DefaultAlertWindow      = time.Minute
+	DefaultAlertMinInterval = 15 * time.Minute
+)
+
+// AlertKind is a kind of failure which raises alerts.
+type AlertKind string
+
+const (
+	// AlertSendFailures is raised when messages can not be sent.
+	AlertSendFailures AlertKind = "send_failures"
+	// AlertSignatureFailures is raised when webhook requests have invalid
+	// signatures.
+	AlertSignatureFailures AlertKind = "signature_failures"
+	// AlertDecodeErrors is raised when webhook requests can not be read or
+	// decoded.
+	AlertDecodeErrors AlertKind = "decode_errors"
+)
+
+// Alert reports repeated failures of the same kind.
+type Alert struct {
+	Kind AlertKind `json:"kind"`
+	// Count is the number of failures within Window.
+	Count  int           `json:"count"`
+	Window time.Duration `json:"window"`
+	Time   time.Time     `json:"time"`
+	// LastError is the most recent of the failures.
+	LastError string `json:"last_error"`
+}
+
+func (a Alert) String() string {
+	return fmt.Sprintf("messenger: %d %s within %s, last: %s", a.Count, a.Kind, a.Window, a.LastError)
+}
+
+// AlertFunc is called with the alerts raised by a Messenger.
+type AlertFunc func(Alert)
+
+// AlertOptions configures the alerts raised when failures pile up.
+type AlertOptions struct {
+	// Func, if set, is called with every alert.
+	Func AlertFunc
+	// WebhookURL, if set, is posted every alert as {"text": "..."}, the
+	// format of Slack incoming webhooks, with the HTTPClient of the
+	// Messenger. At most one alert of each kind is posted per MinInterval.
+	WebhookURL string
+	// MinInterval defaults to DefaultAlertMinInterval.
+	MinInterval time.Duration
+	// Threshold is the number of failures within Window which raises an
+	// alert. Defaults to DefaultAlertThreshold and DefaultAlertWindow.
+	Threshold int
+	Window    time.Duration
+}
+
+// alerter counts failures and raises alerts.
+type alerter struct {
+	opts AlertOptions
+
+	mu       sync.Mutex
+	failures map[AlertKind][]time.Time
+	posted   map[AlertKind]time.Time
+}
+
+func newAlerter(opts *AlertOptions) *alerter {
+	a := &alerter{
+		opts:     *opts,
+		failures: make(map[AlertKind][]time.Time),
+		posted:   make(map[AlertKind]time.Time),
+	}
+	if a.opts.Threshold <= 0 {
+		a.opts.Threshold = DefaultAlertThreshold
+	}
+	if a.opts.Window <= 0 {
+		a.opts.Window = DefaultAlertWindow
+	}
+	if a.opts.MinInterval <= 0 {
+		a.opts.MinInterval = DefaultAlertMinInterval
+	}
+	return a
+}
+
+// noteFailure counts a failure of the given kind, raising an alert once
+// Threshold of them happened within Window.
+func (m *Messenger) noteFailure(kind AlertKind, err error) {
+	a := m.alerts
+	if a == nil {
+		return
+	}
+
+	now := m.now()
+	a.mu.Lock()
+	times := append(a.failures[kind], now)
+	for len(times) > 0 && now.Sub(times[0]) > a.opts.Window {
+		times = times[1:]
+	}
+	if len(times) < a.opts.Threshold {
+		a.failures[kind] = times
+		a.mu.Unlock()
+		return
+	}
+
+	// Start counting again, so that an alert is raised per Threshold
+	// failures.
+	delete(a.failures, kind)
+	post := a.opts.WebhookURL != "" && now.Sub(a.posted[kind]) >= a.opts.MinInterval
+	if post {
+		a.posted[kind] = now
+	}
+	a.mu.Unlock()
+
+	alert := Alert{Kind: kind, Count: len(times), Window: a.opts.Window, Time: now, LastError: err.Error()}
+	if post {
+		go m.postAlert(alert)
+	}
+	if a.opts.Func != nil {
+		a.opts.Func(alert)
+	}
+}
+
+// postAlert posts alert to the webhook set in AlertOptions.
+func (m *Messenger) postAlert(alert Alert) {
+	data, err := json.Marshal(map[string]string{"text": alert.String()})
+	if err != nil {
+		return
+	}
+
+	resp, err := m.httpClient().Post(m.alerts.opts.WebhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		fmt.Println("could not post alert:", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Println("could not post alert: status", resp.StatusCode)
+	}
+}
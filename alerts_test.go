@@ -0,0 +1,79 @@
+package messenger
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_Alerts(t *testing.T) {
+	posted := make(chan string, 10)
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		posted <- body["text"]
+	}))
+	defer slack.Close()
+
+	clock := newFakeClock()
+	var alerts []Alert
+	m := New(Options{
+		Verify:    true,
+		AppSecret: "secret",
+		Clock:     clock,
+		Alerts: &AlertOptions{
+			Func:        func(a Alert) { alerts = append(alerts, a) },
+			WebhookURL:  slack.URL,
+			MinInterval: time.Hour,
+			Threshold:   3,
+			Window:      time.Minute,
+		},
+	})
+
+	body, err := ioutil.ReadFile("testdata/webhooks/message_text.json")
+	require.NoError(t, err)
+	unsigned := func() {
+		m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(string(body))))
+		clock.Advance(time.Second)
+	}
+
+	unsigned()
+	unsigned()
+	assert.Empty(t, alerts)
+	unsigned()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, AlertSignatureFailures, alerts[0].Kind)
+	assert.Equal(t, 3, alerts[0].Count)
+
+	select {
+	case text := <-posted:
+		assert.True(t, strings.HasPrefix(text, "messenger: 3 signature_failures within 1m0s"), text)
+	case <-time.After(5 * time.Second):
+		t.Fatal("alert not posted")
+	}
+
+	// Failures outside of the window do not count.
+	unsigned()
+	clock.Advance(time.Minute)
+	unsigned()
+	unsigned()
+	assert.Len(t, alerts, 1)
+
+	// Alerts keep being raised, but are posted at most once per interval.
+	unsigned()
+	require.Len(t, alerts, 2)
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("{")))
+	assert.Len(t, alerts, 2)
+	select {
+	case text := <-posted:
+		t.Fatal("alert posted twice:", text)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,185 @@
+package messenger
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DefaultAnalyticsMinUsers is the least number of users a postback must be
+// tapped by to appear in reports when AnalyticsOptions does not set its own.
+const DefaultAnalyticsMinUsers = 5
+
+// Buckets of AnalyticsReport.MessagesPerUser.
+var messageBuckets = []struct {
+	name string
+	max  int
+}{
+	{"1", 1},
+	{"2-5", 5},
+	{"6-10", 10},
+	{"11-20", 20},
+	{"21+", math.MaxInt32},
+}
+
+// AnalyticsOptions configure the aggregate counters of a Messenger. Reports
+// never contain user IDs, and can be made differentially private with
+// Epsilon.
+type AnalyticsOptions struct {
+	// Epsilon, if set, adds Laplace noise of scale 1/Epsilon to every count
+	// of the reports. Smaller values give more privacy and less accuracy.
+	Epsilon float64
+	// MinUsers is the least number of distinct users a postback must be
+	// tapped by to be reported. Defaults to DefaultAnalyticsMinUsers.
+	MinUsers int
+	// Export, if set, receives the report of each day once it is over, as
+	// seen when the next event arrives.
+	Export func(AnalyticsReport)
+}
+
+// AnalyticsReport aggregates the activity of a day.
+type AnalyticsReport struct {
+	// Day is the start of the day, in UTC.
+	Day time.Time `json:"day"`
+	// ActiveUsers is the number of users who sent a message or a postback.
+	ActiveUsers int `json:"active_users"`
+	// MessagesPerUser counts the users by number of messages sent, in the
+	// buckets "1", "2-5", "6-10", "11-20" and "21+".
+	MessagesPerUser map[string]int `json:"messages_per_user"`
+	// Postbacks counts the distinct users who tapped each postback payload.
+	Postbacks map[string]int `json:"postbacks"`
+}
+
+// analytics counts the activity of the current day.
+type analytics struct {
+	opts *AnalyticsOptions
+
+	mu        sync.Mutex
+	day       time.Time
+	messages  map[int64]int
+	postbacks map[string]map[int64]bool
+	rand      *rand.Rand
+}
+
+func newAnalytics(opts *AnalyticsOptions) *analytics {
+	return &analytics{
+		opts: opts,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// record counts an event of a user, exporting the report of the previous
+// day if it is over.
+func (a *analytics) record(now time.Time, psid int64, payload string, isPostBack bool) {
+	day := now.UTC().Truncate(24 * time.Hour)
+
+	a.mu.Lock()
+	var finished *AnalyticsReport
+	if !day.Equal(a.day) {
+		if a.messages != nil {
+			report := a.report()
+			finished = &report
+		}
+		a.day = day
+		a.messages = make(map[int64]int)
+		a.postbacks = make(map[string]map[int64]bool)
+	}
+
+	if _, ok := a.messages[psid]; !ok {
+		a.messages[psid] = 0
+	}
+	if isPostBack {
+		if a.postbacks[payload] == nil {
+			a.postbacks[payload] = make(map[int64]bool)
+		}
+		a.postbacks[payload][psid] = true
+	} else {
+		a.messages[psid]++
+	}
+	a.mu.Unlock()
+
+	if finished != nil && a.opts.Export != nil {
+		a.opts.Export(*finished)
+	}
+}
+
+// report builds the report of the current day, with a.mu held.
+func (a *analytics) report() AnalyticsReport {
+	r := AnalyticsReport{
+		Day:             a.day,
+		ActiveUsers:     a.noisy(len(a.messages)),
+		MessagesPerUser: make(map[string]int),
+		Postbacks:       make(map[string]int),
+	}
+
+	for _, count := range a.messages {
+		if count == 0 {
+			continue
+		}
+		for _, b := range messageBuckets {
+			if count <= b.max {
+				r.MessagesPerUser[b.name]++
+				break
+			}
+		}
+	}
+	for _, b := range messageBuckets {
+		r.MessagesPerUser[b.name] = a.noisy(r.MessagesPerUser[b.name])
+	}
+
+	minUsers := a.opts.MinUsers
+	if minUsers <= 0 {
+		minUsers = DefaultAnalyticsMinUsers
+	}
+	for payload, users := range a.postbacks {
+		if len(users) >= minUsers {
+			r.Postbacks[payload] = a.noisy(len(users))
+		}
+	}
+
+	return r
+}
+
+// noisy adds Laplace noise to a count, keeping it positive.
+func (a *analytics) noisy(count int) int {
+	if a.opts.Epsilon <= 0 {
+		return count
+	}
+
+	u := a.rand.Float64() - 0.5
+	noise := -math.Copysign(1, u) * math.Log(1-2*math.Abs(u)) / a.opts.Epsilon
+
+	noisy := int(math.Round(float64(count) + noise))
+	if noisy < 0 {
+		return 0
+	}
+	return noisy
+}
+
+// recordAnalytics counts the messages and postbacks of users.
+func (m *Messenger) recordAnalytics(a Action, info MessageInfo) {
+	if m.analytics == nil {
+		return
+	}
+
+	switch {
+	case a == TextAction && !info.Message.IsEcho:
+		m.analytics.record(m.now(), info.Sender.ID, "", false)
+	case a == PostBackAction:
+		m.analytics.record(m.now(), info.Sender.ID, info.PostBack.Payload, true)
+	}
+}
+
+// Analytics returns the report of the current day so far. It is empty
+// unless Options.Analytics is set.
+func (m *Messenger) Analytics() AnalyticsReport {
+	if m.analytics == nil {
+		return AnalyticsReport{}
+	}
+
+	m.analytics.mu.Lock()
+	defer m.analytics.mu.Unlock()
+
+	return m.analytics.report()
+}
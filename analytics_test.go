@@ -0,0 +1,69 @@
+package messenger
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_Analytics(t *testing.T) {
+	clock := newFakeClock()
+	var exported []AnalyticsReport
+	m := New(Options{
+		Clock: clock,
+		Analytics: &AnalyticsOptions{
+			MinUsers: 2,
+			Export:   func(r AnalyticsReport) { exported = append(exported, r) },
+		},
+	})
+
+	post := func(psid int, event string) {
+		body := fmt.Sprintf(`{"object":"page","entry":[{"id":"1","time":1,"messaging":[{"sender":{"id":"%d"},"recipient":{"id":"1"},"timestamp":1,%s}]}]}`, psid, event)
+		m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	}
+	text := `"message":{"mid":"m","text":"hi"}`
+	postback := func(payload string) string {
+		return fmt.Sprintf(`"postback":{"payload":%q}`, payload)
+	}
+
+	post(1, text)
+	for i := 0; i < 3; i++ {
+		post(2, text)
+	}
+	post(2, postback("MENU"))
+	post(2, postback("MENU"))
+	post(3, postback("MENU"))
+	post(3, postback("RARE"))
+	post(1, `"message":{"mid":"e","is_echo":true,"text":"hi"}`)
+
+	report := m.Analytics()
+	assert.Equal(t, time.Date(2018, 11, 24, 0, 0, 0, 0, time.UTC), report.Day)
+	assert.Equal(t, 3, report.ActiveUsers)
+	assert.Equal(t, map[string]int{"1": 1, "2-5": 1, "6-10": 0, "11-20": 0, "21+": 0}, report.MessagesPerUser)
+	assert.Equal(t, map[string]int{"MENU": 2}, report.Postbacks)
+	assert.Empty(t, exported)
+
+	clock.Advance(24 * time.Hour)
+	post(4, text)
+	require.Len(t, exported, 1)
+	assert.Equal(t, report, exported[0])
+	assert.Equal(t, 1, m.Analytics().ActiveUsers)
+}
+
+func TestAnalyticsNoise(t *testing.T) {
+	a := newAnalytics(&AnalyticsOptions{Epsilon: 0.5})
+
+	var total int
+	for i := 0; i < 1000; i++ {
+		n := a.noisy(100)
+		assert.True(t, n >= 0)
+		total += n
+	}
+	// The noise averages out.
+	assert.InDelta(t, 100, float64(total)/1000, 2)
+}
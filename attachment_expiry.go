@@ -0,0 +1,93 @@
+package messenger
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// MessageURL is the API endpoint for messages, followed by their mid.
+const MessageURL = "https://graph.facebook.com/v2.11/"
+
+// ExpiresAt returns when the URL of an incoming attachment stops working,
+// and whether it is known. Facebook CDN URLs carry their expiry, other
+// URLs are assumed not to expire.
+func (a Attachment) ExpiresAt() (time.Time, bool) {
+	u, err := url.Parse(a.Payload.URL)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// oe is the expiry of the signature of the URL, as hexadecimal seconds.
+	oe := u.Query().Get("oe")
+	if oe == "" {
+		return time.Time{}, false
+	}
+	sec, err := strconv.ParseInt(oe, 16, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
+
+// Expired reports whether the URL of an incoming attachment no longer works
+// at t.
+func (a Attachment) Expired(t time.Time) bool {
+	expiry, ok := a.ExpiresAt()
+	return ok && !t.Before(expiry)
+}
+
+// RefreshAttachments fetches the attachments of a message again, with new
+// URLs, for when they expired before it was processed. The attachments are
+// in the order of Message.Attachments.
+func (m *Messenger) RefreshAttachments(mid string) ([]Attachment, error) {
+	var resp struct {
+		Data []struct {
+			MimeType  string `json:"mime_type"`
+			Name      string `json:"name"`
+			FileURL   string `json:"file_url"`
+			ImageData *struct {
+				URL string `json:"url"`
+			} `json:"image_data"`
+			VideoData *struct {
+				URL string `json:"url"`
+			} `json:"video_data"`
+		} `json:"data"`
+	}
+	err := m.graphCall("GET", MessageURL+url.PathEscape(mid)+"/attachments", nil, &resp)
+	if err != nil {
+		return nil, xerrors.Errorf("could not refresh attachments of %s: %w", mid, err)
+	}
+
+	attachments := make([]Attachment, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		a := Attachment{Title: d.Name, Type: string(FileAttachment)}
+		switch {
+		case d.ImageData != nil:
+			a.Type, a.Payload.URL = string(ImageAttachment), d.ImageData.URL
+		case d.VideoData != nil:
+			a.Type, a.Payload.URL = string(VideoAttachment), d.VideoData.URL
+		case strings.HasPrefix(d.MimeType, "audio/"):
+			a.Type, a.Payload.URL = string(AudioAttachment), d.FileURL
+		default:
+			a.Payload.URL = d.FileURL
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+// FreshAttachments returns the attachments of msg, refreshing them with
+// RefreshAttachments if any of them expired.
+func (m *Messenger) FreshAttachments(msg Message) ([]Attachment, error) {
+	now := m.now()
+	for _, a := range msg.Attachments {
+		if a.Expired(now) {
+			return m.RefreshAttachments(msg.Mid)
+		}
+	}
+	return msg.Attachments, nil
+}
@@ -0,0 +1,57 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachment_ExpiresAt(t *testing.T) {
+	var msg Message
+	m := New(Options{})
+	m.HandleMessage(func(message Message, r *Response) { msg = message })
+	serveFixture(t, m, "message_attachments.json")
+	require.Len(t, msg.Attachments, 3)
+
+	expiry, ok := msg.Attachments[0].ExpiresAt()
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(0x5C9A1F2B, 0), expiry)
+	assert.False(t, msg.Attachments[0].Expired(expiry.Add(-time.Second)))
+	assert.True(t, msg.Attachments[0].Expired(expiry))
+
+	_, ok = msg.Attachments[2].ExpiresAt()
+	assert.False(t, ok)
+	assert.False(t, msg.Attachments[2].Expired(expiry.Add(time.Hour)))
+}
+
+func TestMessenger_FreshAttachments(t *testing.T) {
+	graph := newFakeGraph(`{"data":[
+		{"id":"1","mime_type":"image/png","name":"image.png","image_data":{"url":"https://example.com/image.png"}},
+		{"id":"2","mime_type":"audio/mpeg","name":"audio_clip.mp4","file_url":"https://example.com/audio_clip.mp4"},
+		{"id":"3","mime_type":"application/pdf","name":"document.pdf","file_url":"https://example.com/document.pdf"}
+	]}`)
+	clock := newFakeClock()
+	m := New(Options{HTTPClient: graph.client(), Clock: clock})
+
+	var msg Message
+	m.HandleMessage(func(message Message, r *Response) { msg = message })
+	serveFixture(t, m, "message_attachments.json")
+
+	attachments, err := m.FreshAttachments(msg)
+	require.NoError(t, err)
+	assert.Equal(t, msg.Attachments, attachments)
+	assert.Equal(t, 0, graph.count())
+
+	clock.Advance(200 * 24 * time.Hour)
+	attachments, err = m.FreshAttachments(msg)
+	require.NoError(t, err)
+	require.Equal(t, 1, graph.count())
+	assert.Equal(t, "/v2.11/m_attachments/attachments", graph.requests[0].URL.Path)
+	assert.Equal(t, []Attachment{
+		{Title: "image.png", Type: "image", Payload: Payload{URL: "https://example.com/image.png"}},
+		{Title: "audio_clip.mp4", Type: "audio", Payload: Payload{URL: "https://example.com/audio_clip.mp4"}},
+		{Title: "document.pdf", Type: "file", Payload: Payload{URL: "https://example.com/document.pdf"}},
+	}, attachments)
+}
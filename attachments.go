@@ -0,0 +1,54 @@
+package messenger
+
+// AttachmentHandler is a handler used for responding to an attachment of an
+// incoming message.
+type AttachmentHandler func(a Attachment, msg Message, r *Response)
+
+// HandleAttachment adds a new MessageHandler to the Messenger which will be
+// triggered for every message made only of attachments, without text.
+// Echoes are ignored.
+func (m *Messenger) HandleAttachment(f MessageHandler) {
+	m.HandleMessage(func(msg Message, r *Response) {
+		if !msg.IsEcho && msg.Text == "" && len(msg.Attachments) > 0 {
+			f(msg, r)
+		}
+	})
+}
+
+// HandleImage adds a new AttachmentHandler to the Messenger which will be
+// triggered for every image sent by a user.
+func (m *Messenger) HandleImage(f AttachmentHandler) {
+	m.handleAttachmentType(ImageAttachment, f)
+}
+
+// HandleAudio adds a new AttachmentHandler to the Messenger which will be
+// triggered for every audio clip, such as a voice note, sent by a user.
+func (m *Messenger) HandleAudio(f AttachmentHandler) {
+	m.handleAttachmentType(AudioAttachment, f)
+}
+
+// HandleVideo adds a new AttachmentHandler to the Messenger which will be
+// triggered for every video sent by a user.
+func (m *Messenger) HandleVideo(f AttachmentHandler) {
+	m.handleAttachmentType(VideoAttachment, f)
+}
+
+// HandleFile adds a new AttachmentHandler to the Messenger which will be
+// triggered for every file sent by a user.
+func (m *Messenger) HandleFile(f AttachmentHandler) {
+	m.handleAttachmentType(FileAttachment, f)
+}
+
+func (m *Messenger) handleAttachmentType(t AttachmentType, f AttachmentHandler) {
+	m.HandleMessage(func(msg Message, r *Response) {
+		if msg.IsEcho {
+			return
+		}
+
+		for _, a := range msg.Attachments {
+			if a.Type == string(t) {
+				f(a, msg, r)
+			}
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_HandleAttachment(t *testing.T) {
+	m := New(Options{})
+
+	var attachmentOnly []string
+	m.HandleAttachment(func(msg Message, r *Response) {
+		attachmentOnly = append(attachmentOnly, msg.Mid)
+	})
+
+	var images, audio, videos, files []string
+	m.HandleImage(func(a Attachment, msg Message, r *Response) { images = append(images, a.Payload.URL) })
+	m.HandleAudio(func(a Attachment, msg Message, r *Response) { audio = append(audio, a.Payload.URL) })
+	m.HandleVideo(func(a Attachment, msg Message, r *Response) { videos = append(videos, a.Payload.URL) })
+	m.HandleFile(func(a Attachment, msg Message, r *Response) { files = append(files, a.Payload.URL) })
+
+	for _, name := range []string{"message_text.json", "message_attachments.json", "message_echo.json"} {
+		serveFixture(t, m, name)
+	}
+
+	assert.Equal(t, []string{"m_attachments"}, attachmentOnly)
+	assert.Equal(t, []string{"https://scontent.xx.fbcdn.net/v/t1.15752-9/image.png?oh=abc&oe=5C9A1F2B"}, images)
+	assert.Equal(t, []string{"https://cdn.fbsbx.com/v/t59.3654-21/audio_clip.mp4?oh=def&oe=5C9A1F2B"}, audio)
+	assert.Empty(t, videos)
+	assert.Equal(t, []string{"https://cdn.fbsbx.com/v/t59.2708-21/document.pdf"}, files)
+}
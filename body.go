@@ -0,0 +1,61 @@
+package messenger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultMaxBodySize is the largest webhook body accepted when no
+// MaxBodySize is set in Options.
+const DefaultMaxBodySize = 10 << 20
+
+// ErrBodyTooLarge is returned when a webhook body exceeds the maximum size.
+var ErrBodyTooLarge = xerrors.New("request body too large")
+
+// readBody reads the body of a webhook request, transparently decompressing
+// gzip encoded bodies unless disabled. The size limit applies to the
+// decompressed body.
+func (m *Messenger) readBody(r *http.Request) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := m.readBodyTo(&buf, r); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readBodyTo is readBody appending the body to buf, which lets pooled
+// buffers be reused between requests.
+func (m *Messenger) readBodyTo(buf *bytes.Buffer, r *http.Request) error {
+	limit := m.maxBodySize
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+
+	var body io.Reader = r.Body
+	if !m.disableGzip && strings.EqualFold(r.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return xerrors.Errorf("could not decompress body: %w", err)
+		}
+		defer gz.Close()
+
+		body = gz
+		r.Header.Del("Content-Encoding")
+	}
+
+	n, err := buf.ReadFrom(io.LimitReader(body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return ErrBodyTooLarge
+	}
+
+	return nil
+}
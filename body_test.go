@@ -0,0 +1,72 @@
+package messenger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha1"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPayload = `{"object":"page","entry":[{"id":"1","time":1543095111999,"messaging":[{"sender":{"id":"111"},"recipient":{"id":"222"},"timestamp":1543095111999,"message":{"mid":"m1","text":"hello"}}]}]}`
+
+func gzipped(t *testing.T, data string) *bytes.Buffer {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return &buf
+}
+
+func TestMessenger_HandleGzip(t *testing.T) {
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(testPayload))
+	signature := fmt.Sprintf("sha1=%x", mac.Sum(nil))
+
+	t.Run("decompressed before verification", func(t *testing.T) {
+		m := New(Options{Verify: true, AppSecret: "secret"})
+
+		var texts []string
+		m.HandleMessage(func(msg Message, r *Response) {
+			texts = append(texts, msg.Text)
+		})
+
+		req := httptest.NewRequest("POST", "/", gzipped(t, testPayload))
+		req.Header.Set("Content-Encoding", "gzip")
+		req.Header.Set("X-Hub-Signature", signature)
+		m.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, []string{"hello"}, texts)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		m := New(Options{DisableGzip: true})
+
+		called := false
+		m.HandleMessage(func(msg Message, r *Response) {
+			called = true
+		})
+
+		req := httptest.NewRequest("POST", "/", gzipped(t, testPayload))
+		req.Header.Set("Content-Encoding", "gzip")
+		m.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.False(t, called)
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		m := New(Options{MaxBodySize: 16})
+
+		req := httptest.NewRequest("POST", "/", gzipped(t, testPayload))
+		req.Header.Set("Content-Encoding", "gzip")
+		_, err := m.readBody(req)
+
+		assert.Equal(t, ErrBodyTooLarge, err)
+	})
+}
@@ -0,0 +1,145 @@
+// Package bridge feeds webhook payloads received from a relay into a
+// Messenger, so that bots can be tried during development without exposing a
+// public webhook URL.
+//
+// The relay receives the webhook requests from Facebook, checks their
+// signature, and publishes their bodies as Server-Sent Events:
+//
+//	data: {"object":"page","entry":[...]}
+//
+// Run connects to the relay and calls ProcessPayload with the data of every
+// event, reconnecting when the connection drops.
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultRetry is how long Run waits before reconnecting, unless the relay
+// sets its own delay.
+const DefaultRetry = 3 * time.Second
+
+// Processor processes webhook payloads. *messenger.Messenger implements it.
+type Processor interface {
+	ProcessPayload(body []byte) error
+}
+
+// SSE is a relay publishing webhook payloads as Server-Sent Events.
+type SSE struct {
+	// URL is the event stream of the relay.
+	URL string
+	// Header is added to the requests to the relay, for instance to
+	// authenticate.
+	Header http.Header
+	// Client is used to connect to the relay. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+	// Retry is how long to wait before reconnecting. Defaults to
+	// DefaultRetry, or the delay sent by the relay.
+	Retry time.Duration
+}
+
+// Run feeds the payloads published by the relay into p until ctx is done.
+// Payloads which p fails to process are logged and skipped.
+func (s *SSE) Run(ctx context.Context, p Processor) error {
+	retry := s.Retry
+	if retry <= 0 {
+		retry = DefaultRetry
+	}
+
+	for {
+		err := s.stream(ctx, p, &retry)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Println("relay connection lost:", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+// stream reads the events of a single connection to the relay.
+func (s *SSE) stream(ctx context.Context, p Processor, retry *time.Duration) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.URL, nil)
+	if err != nil {
+		return err
+	}
+	for k, v := range s.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return readEvents(resp.Body, func(data string) {
+		if err := p.ProcessPayload([]byte(data)); err != nil {
+			fmt.Println("could not process payload:", err)
+		}
+	}, retry)
+}
+
+// readEvents calls dispatch with the data of every event read from r, and
+// updates retry when the stream sets it.
+func readEvents(r io.Reader, dispatch func(data string), retry *time.Duration) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 10<<20)
+
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if event := strings.Join(data, "\n"); event != "" {
+				dispatch(event)
+			}
+			data = data[:0]
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch field {
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				*retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return io.ErrUnexpectedEOF
+}
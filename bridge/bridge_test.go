@@ -0,0 +1,61 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/paked/messenger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const textPayload = `{"object":"page","entry":[{"id":"1067280970047460","time":1543095111999,"messaging":[{"sender":{"id":"1254459154682919"},"recipient":{"id":"1067280970047460"},"timestamp":1543095111999,"message":{"mid":"m_1","text":"%s"}}]}]}`
+
+func TestSSE_Run(t *testing.T) {
+	var connections int32
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		n := atomic.AddInt32(&connections, 1)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, ": connected\nretry: 10\n\n")
+		fmt.Fprintf(w, "data: "+textPayload+"\n\n", fmt.Sprint("hello ", n))
+		fmt.Fprintf(w, "data: not json\n\n")
+	}))
+	defer relay.Close()
+
+	m := messenger.New(messenger.Options{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var texts []string
+	m.HandleMessage(func(msg messenger.Message, r *messenger.Response) {
+		texts = append(texts, msg.Text)
+		if len(texts) == 2 {
+			cancel()
+		}
+	})
+
+	s := &SSE{URL: relay.URL, Header: http.Header{"Authorization": {"Bearer secret"}}, Retry: time.Minute}
+	err := s.Run(ctx, m)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"hello 1", "hello 2"}, texts)
+}
+
+func TestReadEvents(t *testing.T) {
+	var events []string
+	retry := DefaultRetry
+	err := readEvents(strings.NewReader("event: webhook\ndata: a\ndata: b\n\n: ping\n\nretry: 500\ndata\n\nid: 1\n"), func(data string) {
+		events = append(events, data)
+	}, &retry)
+
+	require.Error(t, err)
+	assert.Equal(t, []string{"a\nb"}, events)
+	assert.Equal(t, 500*time.Millisecond, retry)
+}
@@ -0,0 +1,161 @@
+package messenger
+
+import (
+	"sync"
+	"time"
+)
+
+// OpeningHours are the hours a business is open on a day of the week,
+// given as offsets from midnight. A Close before Open means the business
+// closes after midnight, on the following day.
+type OpeningHours struct {
+	Day   time.Weekday
+	Open  time.Duration
+	Close time.Duration
+}
+
+// Weekdays returns the same opening hours from Monday to Friday.
+func Weekdays(open, close time.Duration) []OpeningHours {
+	var hours []OpeningHours
+	for day := time.Monday; day <= time.Friday; day++ {
+		hours = append(hours, OpeningHours{Day: day, Open: open, Close: close})
+	}
+	return hours
+}
+
+// BusinessHours answer users automatically while the business is closed.
+// Each user is answered once per closing period.
+type BusinessHours struct {
+	// Location is the time zone of Hours. Defaults to UTC.
+	Location *time.Location
+	// Hours are when the business is open.
+	Hours []OpeningHours
+	// Message is sent to users writing while the business is closed.
+	Message string
+	// QuickReplies are sent along with Message.
+	QuickReplies []QuickReply
+	// SkipHandlers stops messages and postbacks from reaching their handlers
+	// while the business is closed.
+	SkipHandlers bool
+	// FollowUp, if set, is called for users answered while the business is
+	// closed, with the time it opens, for example to schedule a follow-up.
+	FollowUp func(psid int64, opening time.Time)
+	// FollowUpLabel, if set, is the Page Inbox label applied to the
+	// conversations of users answered while the business is closed.
+	FollowUpLabel string
+
+	mu       sync.Mutex
+	answered map[int64]time.Time
+}
+
+func (b *BusinessHours) location() *time.Location {
+	if b.Location == nil {
+		return time.UTC
+	}
+	return b.Location
+}
+
+// IsOpen reports whether the business is open at t.
+func (b *BusinessHours) IsOpen(t time.Time) bool {
+	t = t.In(b.location())
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	yesterday := (t.Weekday() + 6) % 7
+	for _, h := range b.Hours {
+		overnight := h.Close < h.Open
+		switch {
+		case h.Day == t.Weekday() && offset >= h.Open && (overnight || offset < h.Close):
+			return true
+		case overnight && h.Day == yesterday && offset < h.Close:
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpening returns when the business opens next after t, or the zero
+// time if it never does.
+func (b *BusinessHours) NextOpening(t time.Time) time.Time {
+	t = t.In(b.location())
+	var next time.Time
+
+	for days := 0; days <= 7; days++ {
+		d := t.AddDate(0, 0, days)
+		midnight := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location())
+
+		for _, h := range b.Hours {
+			opening := midnight.Add(h.Open)
+			if h.Day != d.Weekday() || !opening.After(t) {
+				continue
+			}
+			if next.IsZero() || opening.Before(next) {
+				next = opening
+			}
+		}
+		if !next.IsZero() {
+			return next
+		}
+	}
+	return next
+}
+
+// shouldAnswer reports whether psid has not been answered yet during the
+// closing period ending at opening. Users answered during closing periods
+// which are over by now are forgotten.
+func (b *BusinessHours) shouldAnswer(psid int64, now, opening time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.answered == nil {
+		b.answered = make(map[int64]time.Time)
+	}
+	for id, o := range b.answered {
+		if !o.After(now) {
+			delete(b.answered, id)
+		}
+	}
+	if b.answered[psid].Equal(opening) {
+		return false
+	}
+	b.answered[psid] = opening
+	return true
+}
+
+// answerOutOfHours answers an event while the business is closed, reporting
+// whether its handlers must be skipped.
+func (m *Messenger) answerOutOfHours(a Action, info MessageInfo) bool {
+	b := m.businessHours
+	if b == nil {
+		return false
+	}
+	if a != PostBackAction && (a != TextAction || info.Message.IsEcho) {
+		return false
+	}
+
+	now := m.now()
+	if b.IsOpen(now) {
+		return false
+	}
+
+	opening := b.NextOpening(now)
+	if b.shouldAnswer(info.Sender.ID, now, opening) {
+		r := m.Response(info.Sender.ID)
+		if err := r.TextWithReplies(b.Message, b.QuickReplies, ResponseType); err != nil {
+			m.recordError(err)
+		}
+
+		if b.FollowUpLabel != "" {
+			if id, err := m.labelID(b.FollowUpLabel); err != nil {
+				m.recordError(err)
+			} else if err := m.LabelUser(id, info.Sender.ID); err != nil {
+				m.recordError(err)
+			}
+		}
+		if b.FollowUp != nil {
+			b.FollowUp(info.Sender.ID, opening)
+		}
+	}
+
+	return b.SkipHandlers
+}
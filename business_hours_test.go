@@ -0,0 +1,84 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessHours(t *testing.T) {
+	b := &BusinessHours{Hours: Weekdays(9*time.Hour, 17*time.Hour)}
+
+	monday := time.Date(2018, 11, 26, 0, 0, 0, 0, time.UTC)
+	assert.False(t, b.IsOpen(monday.Add(8*time.Hour)))
+	assert.True(t, b.IsOpen(monday.Add(9*time.Hour)))
+	assert.False(t, b.IsOpen(monday.Add(17*time.Hour)))
+
+	assert.Equal(t, monday.Add(9*time.Hour), b.NextOpening(monday.Add(-time.Hour)))
+	assert.Equal(t, monday.Add(33*time.Hour), b.NextOpening(monday.Add(10*time.Hour)))
+	assert.Equal(t, monday.Add(7*24*time.Hour+9*time.Hour), b.NextOpening(monday.Add(4*24*time.Hour+18*time.Hour)))
+	assert.True(t, (&BusinessHours{}).NextOpening(monday).IsZero())
+}
+
+func TestBusinessHours_Overnight(t *testing.T) {
+	b := &BusinessHours{Hours: []OpeningHours{
+		{Day: time.Friday, Open: 20 * time.Hour, Close: 2 * time.Hour},
+	}}
+
+	friday := time.Date(2018, 11, 30, 0, 0, 0, 0, time.UTC)
+	assert.False(t, b.IsOpen(friday.Add(time.Hour)))
+	assert.False(t, b.IsOpen(friday.Add(19*time.Hour)))
+	assert.True(t, b.IsOpen(friday.Add(23*time.Hour)))
+	assert.True(t, b.IsOpen(friday.Add(25*time.Hour)))
+	assert.False(t, b.IsOpen(friday.Add(26*time.Hour)))
+	assert.Equal(t, friday.Add(20*time.Hour), b.NextOpening(friday))
+}
+
+func TestBusinessHours_ForgetsPastClosings(t *testing.T) {
+	b := &BusinessHours{}
+	now := time.Date(2018, 11, 24, 21, 0, 0, 0, time.UTC)
+	monday := time.Date(2018, 11, 26, 9, 0, 0, 0, time.UTC)
+
+	assert.True(t, b.shouldAnswer(1, now, monday))
+	assert.False(t, b.shouldAnswer(1, now, monday))
+	assert.Len(t, b.answered, 1)
+
+	tuesday := monday.Add(24 * time.Hour)
+	assert.True(t, b.shouldAnswer(2, monday.Add(10*time.Hour), tuesday))
+	assert.Equal(t, map[int64]time.Time{2: tuesday}, b.answered)
+}
+
+func TestMessenger_BusinessHours(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	clock := newFakeClock() // Saturday evening.
+
+	var followUps []time.Time
+	m := New(Options{
+		HTTPClient: graph.client(),
+		Clock:      clock,
+		BusinessHours: &BusinessHours{
+			Hours:   Weekdays(9*time.Hour, 17*time.Hour),
+			Message: "We are closed, we will get back to you on Monday.",
+			FollowUp: func(psid int64, opening time.Time) {
+				followUps = append(followUps, opening)
+			},
+		},
+	})
+
+	var handled int
+	m.HandleMessage(func(Message, *Response) { handled++ })
+
+	serveFixture(t, m, "message_text.json")
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 2, handled)
+	require.Equal(t, 1, graph.count())
+	assert.Contains(t, graph.bodies[0], "We are closed")
+	assert.Equal(t, []time.Time{time.Date(2018, 11, 26, 9, 0, 0, 0, time.UTC)}, followUps)
+
+	clock.Advance(37 * time.Hour) // Monday morning.
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 3, handled)
+	assert.Equal(t, 1, graph.count())
+}
@@ -0,0 +1,173 @@
+package messenger
+
+import (
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// MaxButtons is the maximum number of buttons of a button template.
+const MaxButtons = 3
+
+// Types of StructuredMessageButton.
+const (
+	ButtonPostback = "postback"
+	ButtonURL      = "web_url"
+	ButtonShare    = "element_share"
+	ButtonCall     = "phone_number"
+)
+
+// ErrTooManyButtons is returned when sending a button template with more
+// than MaxButtons buttons, or none.
+var ErrTooManyButtons = xerrors.Errorf("button template needs between 1 and %d buttons", MaxButtons)
+
+// NewPostbackButton creates a button sending a postback with payload when
+// tapped.
+func NewPostbackButton(title, payload string) StructuredMessageButton {
+	return StructuredMessageButton{Type: ButtonPostback, Title: title, Payload: payload}
+}
+
+// NewURLButton creates a button opening url when tapped.
+func NewURLButton(title, url string) StructuredMessageButton {
+	return StructuredMessageButton{Type: ButtonURL, Title: title, URL: url}
+}
+
+// NewShareButton creates a button letting users share the message it belongs
+// to, or shareContents instead when not nil. Facebook only accepts shared
+// contents made of a generic template element with a single web_url button.
+func NewShareButton(shareContents *StructuredMessageElement) (StructuredMessageButton, error) {
+	b := StructuredMessageButton{Type: ButtonShare}
+	if shareContents == nil {
+		return b, nil
+	}
+
+	b.ShareContents = &StructuredMessageData{
+		Attachment: StructuredMessageAttachment{
+			Type: "template",
+			Payload: StructuredMessagePayload{
+				TemplateType: "generic",
+				Elements:     &[]StructuredMessageElement{*shareContents},
+			},
+		},
+	}
+	if err := checkButton(b); err != nil {
+		return StructuredMessageButton{}, err
+	}
+	return b, nil
+}
+
+// NewCallButton creates a button calling phone when tapped. phone must be
+// in international format, such as "+1 (650) 555-0123" or "0044 20 7946
+// 0958"; use E164 to normalize national numbers first.
+func NewCallButton(title, phone string) (StructuredMessageButton, error) {
+	number, err := E164(phone, "")
+	if err != nil {
+		return StructuredMessageButton{}, err
+	}
+
+	b := StructuredMessageButton{Type: ButtonCall, Title: title, Payload: number}
+	if err := checkButton(b); err != nil {
+		return StructuredMessageButton{}, err
+	}
+	return b, nil
+}
+
+// E164 normalizes phone to the E.164 format expected by Facebook, such as
+// "+16505550123", ignoring spaces, dots, dashes and parentheses. Numbers
+// which do not start with "+" or "00" are national numbers: their trunk
+// prefix "0" is dropped and they are prefixed with callingCode, such as
+// "44" for the United Kingdom. An empty callingCode only accepts
+// international numbers.
+func E164(phone, callingCode string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	var digits strings.Builder
+	for i, c := range phone {
+		switch {
+		case c >= '0' && c <= '9':
+			digits.WriteRune(c)
+		case c == '+' && i == 0:
+		case strings.ContainsRune(" .-()", c):
+		default:
+			return "", xerrors.Errorf("invalid phone number %q", phone)
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(number, "00"):
+		number = number[2:]
+	case callingCode == "":
+		return "", xerrors.Errorf("phone number %q is not in international format", phone)
+	default:
+		number = strings.TrimPrefix(callingCode, "+") + strings.TrimPrefix(number, "0")
+	}
+
+	// E.164 numbers have up to 15 digits, and country codes do not start
+	// with 0.
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", xerrors.Errorf("invalid phone number %q", phone)
+	}
+	return "+" + number, nil
+}
+
+// TextWithButtons sends text with up to MaxButtons buttons below it, as a
+// button template, in response to a message.
+func (r *Response) TextWithButtons(text string, buttons ...StructuredMessageButton) error {
+	if len(buttons) == 0 || len(buttons) > MaxButtons {
+		return ErrTooManyButtons
+	}
+	for i, b := range buttons {
+		if err := checkButton(b); err != nil {
+			return xerrors.Errorf("button %d: %w", i, err)
+		}
+	}
+
+	return r.ButtonTemplate(text, &buttons, ResponseType)
+}
+
+// checkButton makes sure b has the fields required by its type.
+func checkButton(b StructuredMessageButton) error {
+	switch b.Type {
+	case ButtonPostback:
+		if b.Title == "" || b.Payload == "" {
+			return xerrors.New("postback button needs a title and a payload")
+		}
+	case ButtonURL:
+		if b.Title == "" || b.URL == "" {
+			return xerrors.New("web_url button needs a title and a URL")
+		}
+	case ButtonCall:
+		if b.Title == "" || !strings.HasPrefix(b.Payload, "+") {
+			return xerrors.New("phone_number button needs a title and a number in E.164 format")
+		}
+	case ButtonShare:
+		if b.ShareContents != nil {
+			return checkShareContents(b.ShareContents)
+		}
+	case "":
+		return xerrors.New("button has no type")
+	}
+	return nil
+}
+
+// checkShareContents makes sure the contents of a share button are accepted
+// by Facebook.
+func checkShareContents(d *StructuredMessageData) error {
+	p := d.Attachment.Payload
+	if d.Attachment.Type != "template" || p.TemplateType != "generic" {
+		return xerrors.New("share contents must be a generic template")
+	}
+	if p.Elements == nil || len(*p.Elements) != 1 {
+		return xerrors.New("share contents must have exactly one element")
+	}
+
+	e := (*p.Elements)[0]
+	if e.Title == "" {
+		return xerrors.New("shared element needs a title")
+	}
+	if len(e.Buttons) != 1 || e.Buttons[0].Type != ButtonURL {
+		return xerrors.New("shared element must have exactly one web_url button")
+	}
+	return checkButton(e.Buttons[0])
+}
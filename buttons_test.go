@@ -0,0 +1,120 @@
+package messenger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestResponse_TextWithButtons(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+	r := m.Response(fixturePSID)
+
+	require.NoError(t, r.TextWithButtons("What now?",
+		NewPostbackButton("Start over", "RESTART"),
+		NewURLButton("Help", "https://example.com/help"),
+	))
+	require.Equal(t, 1, graph.count())
+	assert.JSONEq(t, `{
+		"messaging_type": "RESPONSE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {"attachment": {"type": "template", "payload": {
+			"template_type": "button",
+			"text": "What now?",
+			"buttons": [
+				{"type": "postback", "title": "Start over", "payload": "RESTART"},
+				{"type": "web_url", "title": "Help", "url": "https://example.com/help"}
+			]
+		}}}
+	}`, graph.bodies[0])
+
+	assert.True(t, xerrors.Is(r.TextWithButtons("No buttons"), ErrTooManyButtons))
+	four := NewPostbackButton("A", "A")
+	assert.True(t, xerrors.Is(r.TextWithButtons("Four", four, four, four, four), ErrTooManyButtons))
+
+	err := r.TextWithButtons("Broken", NewPostbackButton("A", "A"), NewURLButton("B", ""))
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "button 1:"))
+	assert.Equal(t, 1, graph.count())
+}
+
+func TestNewShareButton(t *testing.T) {
+	b, err := NewShareButton(nil)
+	require.NoError(t, err)
+	assert.Equal(t, StructuredMessageButton{Type: ButtonShare}, b)
+
+	b, err = NewShareButton(&StructuredMessageElement{
+		Title:   "I took the quiz",
+		Buttons: []StructuredMessageButton{NewURLButton("Take it too", "https://example.com/quiz")},
+	})
+	require.NoError(t, err)
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "element_share",
+		"share_contents": {"attachment": {"type": "template", "payload": {
+			"template_type": "generic",
+			"elements": [{
+				"title": "I took the quiz",
+				"image_url": "",
+				"subtitle": "",
+				"buttons": [{"type": "web_url", "title": "Take it too", "url": "https://example.com/quiz"}]
+			}]
+		}}}
+	}`, string(data))
+
+	for name, e := range map[string]StructuredMessageElement{
+		"no title":           {Buttons: []StructuredMessageButton{NewURLButton("Go", "https://example.com")}},
+		"no button":          {Title: "Quiz"},
+		"postback":           {Title: "Quiz", Buttons: []StructuredMessageButton{NewPostbackButton("Go", "GO")}},
+		"two buttons":        {Title: "Quiz", Buttons: []StructuredMessageButton{NewURLButton("A", "https://a"), NewURLButton("B", "https://b")}},
+		"button without url": {Title: "Quiz", Buttons: []StructuredMessageButton{{Type: ButtonURL, Title: "Go"}}},
+	} {
+		e := e
+		_, err := NewShareButton(&e)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNewCallButton(t *testing.T) {
+	b, err := NewCallButton("Call us", "+1 (650) 555-0123")
+	require.NoError(t, err)
+	assert.Equal(t, StructuredMessageButton{Type: ButtonCall, Title: "Call us", Payload: "+16505550123"}, b)
+
+	_, err = NewCallButton("Call us", "020 7946 0958")
+	assert.Error(t, err)
+	_, err = NewCallButton("", "+16505550123")
+	assert.Error(t, err)
+}
+
+func TestE164(t *testing.T) {
+	for _, c := range []struct {
+		phone, callingCode, want string
+	}{
+		{"+1 (650) 555-0123", "", "+16505550123"},
+		{"0044 20 7946 0958", "", "+442079460958"},
+		{"020 7946 0958", "44", "+442079460958"},
+		{"020.7946.0958", "+44", "+442079460958"},
+		{"+44 20 7946 0958", "1", "+442079460958"},
+		{"020 7946 0958", "", ""},
+		{"+1 650 CALL NOW", "", ""},
+		{"+1 650", "", ""},
+		{"+1234567890123456", "", ""},
+		{"+0 650 555 0123", "", ""},
+		{"650+5550123", "1", ""},
+	} {
+		got, err := E164(c.phone, c.callingCode)
+		if c.want == "" {
+			assert.Error(t, err, c.phone)
+			continue
+		}
+		if assert.NoError(t, err, c.phone) {
+			assert.Equal(t, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package messenger
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultCaptureSize is the number of captured payloads kept when Options
+// does not set CaptureSize.
+const DefaultCaptureSize = 100
+
+// CapturedPayload is a webhook request captured for debugging.
+type CapturedPayload struct {
+	Time time.Time `json:"time"`
+	// Header holds the headers of the request needed to replay it, such as
+	// its signature.
+	Header http.Header `json:"header"`
+	// Body is the body of the request, decompressed.
+	Body string `json:"body"`
+}
+
+// capturedHeaders are the headers kept with captured payloads.
+var capturedHeaders = []string{"Content-Type", "X-Hub-Signature", "X-Hub-Signature-256"}
+
+// payloadCapture keeps a sample of the most recent webhook payloads. The zero
+// value captures nothing.
+type payloadCapture struct {
+	rate float64
+	size int
+
+	mu       sync.Mutex
+	payloads []CapturedPayload
+	next     int
+}
+
+// capture keeps body, the body of r, if it is part of the sample.
+func (m *Messenger) capture(r *http.Request, body []byte) {
+	c := &m.captures
+	if c.rate <= 0 || rand.Float64() >= c.rate {
+		return
+	}
+
+	p := CapturedPayload{Time: m.now(), Header: make(http.Header), Body: string(body)}
+	for _, k := range capturedHeaders {
+		if v := r.Header.Get(k); v != "" {
+			p.Header.Set(k, v)
+		}
+	}
+
+	size := c.size
+	if size <= 0 {
+		size = DefaultCaptureSize
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.payloads) < size {
+		c.payloads = append(c.payloads, p)
+		return
+	}
+	c.payloads[c.next] = p
+	c.next = (c.next + 1) % size
+}
+
+// CapturedPayloads returns the webhook payloads captured according to
+// Options.CaptureRate, oldest first. They contain the messages of users, and
+// must be handled accordingly.
+func (m *Messenger) CapturedPayloads() []CapturedPayload {
+	c := &m.captures
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	list := make([]CapturedPayload, 0, len(c.payloads))
+	list = append(list, c.payloads[c.next:]...)
+	return append(list, c.payloads[:c.next]...)
+}
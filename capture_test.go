@@ -0,0 +1,48 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_CapturePayloads(t *testing.T) {
+	m := New(Options{CaptureRate: 1, CaptureSize: 2})
+
+	post := func(body string) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("X-Hub-Signature-256", "sha256=abc")
+		req.Header.Set("Cookie", "secret")
+		m.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serveFixture(t, m, "message_text.json")
+	post(`{"object":"page","entry":[]}`)
+	post(`{"object":"page","entry":[{"messaging":[{"timestamp":"yesterday"}]}]}`)
+
+	payloads := m.CapturedPayloads()
+	require.Len(t, payloads, 2)
+	assert.Equal(t, `{"object":"page","entry":[]}`, payloads[0].Body)
+	assert.Equal(t, `{"object":"page","entry":[{"messaging":[{"timestamp":"yesterday"}]}]}`, payloads[1].Body)
+	assert.Equal(t, http.Header{"X-Hub-Signature-256": {"sha256=abc"}}, payloads[1].Header)
+
+	req := httptest.NewRequest("GET", "/payloads", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	m.AdminHandler("secret").ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var listed []CapturedPayload
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(t, listed, 2)
+}
+
+func TestMessenger_CapturePayloadsDisabled(t *testing.T) {
+	m := New(Options{})
+	serveFixture(t, m, "message_text.json")
+	assert.Empty(t, m.CapturedPayloads())
+}
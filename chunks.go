@@ -0,0 +1,76 @@
+package messenger
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxGenericTemplateElements is the maximum number of elements in a single
+// generic template.
+const MaxGenericTemplateElements = 10
+
+// PartialSendError is returned when an operation sending several messages
+// stops part way, either because a message could not be sent or because the
+// context was done.
+type PartialSendError struct {
+	// Succeeded are the indices of the chunks which were sent.
+	Succeeded []int
+	// Total is the number of chunks the operation was split into.
+	Total int
+	// Err is the error which stopped the operation.
+	Err error
+}
+
+func (e *PartialSendError) Error() string {
+	return fmt.Sprintf("sent %d of %d messages: %v", len(e.Succeeded), e.Total, e.Err)
+}
+
+// Unwrap returns the error which stopped the operation.
+func (e *PartialSendError) Unwrap() error {
+	return e.Err
+}
+
+// sendChunks calls send for each of the n chunks in order, checking ctx
+// before each of them.
+func sendChunks(ctx context.Context, n int, send func(i int) error) error {
+	var succeeded []int
+	for i := 0; i < n; i++ {
+		err := ctx.Err()
+		if err == nil {
+			err = send(i)
+		}
+		if err != nil {
+			return &PartialSendError{Succeeded: succeeded, Total: n, Err: err}
+		}
+
+		succeeded = append(succeeded, i)
+	}
+
+	return nil
+}
+
+// Carousel sends any number of elements as generic templates, split into as
+// many messages as needed. Sending stops once ctx is done; a PartialSendError
+// reports which messages were sent.
+func (r *Response) Carousel(ctx context.Context, elements []StructuredMessageElement, messagingType MessagingType, tags ...string) error {
+	n := (len(elements) + MaxGenericTemplateElements - 1) / MaxGenericTemplateElements
+
+	return sendChunks(ctx, n, func(i int) error {
+		end := (i + 1) * MaxGenericTemplateElements
+		if end > len(elements) {
+			end = len(elements)
+		}
+
+		chunk := elements[i*MaxGenericTemplateElements : end]
+		return r.GenericTemplate(&chunk, messagingType, tags...)
+	})
+}
+
+// Attachments sends several attachments of the same type, one message each.
+// Sending stops once ctx is done; a PartialSendError reports which
+// attachments were sent.
+func (r *Response) Attachments(ctx context.Context, dataType AttachmentType, urls []string, messagingType MessagingType, tags ...string) error {
+	return sendChunks(ctx, len(urls), func(i int) error {
+		return r.Attachment(dataType, urls[i], messagingType, tags...)
+	})
+}
@@ -0,0 +1,50 @@
+package messenger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestSendChunks(t *testing.T) {
+	t.Run("all sent", func(t *testing.T) {
+		sent := 0
+		err := sendChunks(context.Background(), 3, func(i int) error {
+			sent++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, sent)
+	})
+
+	t.Run("send failure", func(t *testing.T) {
+		failure := xerrors.New("facebook is down")
+		err := sendChunks(context.Background(), 3, func(i int) error {
+			if i == 1 {
+				return failure
+			}
+			return nil
+		})
+		require.IsType(t, &PartialSendError{}, err)
+		assert.Equal(t, []int{0}, err.(*PartialSendError).Succeeded)
+		assert.Equal(t, failure, err.(*PartialSendError).Err)
+	})
+
+	t.Run("deadline between chunks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := sendChunks(ctx, 3, func(i int) error {
+			if i == 1 {
+				cancel()
+			}
+			return nil
+		})
+		require.IsType(t, &PartialSendError{}, err)
+		assert.Equal(t, []int{0, 1}, err.(*PartialSendError).Succeeded)
+		assert.Equal(t, context.Canceled, err.(*PartialSendError).Err)
+	})
+}
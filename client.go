@@ -0,0 +1,99 @@
+package messenger
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/paked/messenger/graph"
+)
+
+// newProxyClient creates an HTTP client sending its requests through proxy,
+// which may be an http, https or socks5 URL.
+func newProxyClient(proxy *url.URL) *http.Client {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyURL(proxy)
+	return &http.Client{Transport: t}
+}
+
+// httpClient returns the client used for Graph API calls.
+func (m *Messenger) httpClient() *http.Client {
+	if m.client == nil {
+		return http.DefaultClient
+	}
+	return m.client
+}
+
+// Graph returns a client of the Graph API making its calls with the page
+// access token and the HTTP client of the Messenger, for the endpoints the
+// Messenger does not cover.
+func (m *Messenger) Graph() *graph.Client {
+	return &graph.Client{HTTPClient: m.httpClient(), Token: m.token}
+}
+
+// graphCall makes a Graph API call with the page access token, sending body
+// as JSON unless it is nil and decoding the response into v unless it is nil.
+func (m *Messenger) graphCall(method, endpoint string, body, v interface{}) error {
+	ctx := context.Background()
+	return graphFailed(ctx, "", m.Graph().Do(ctx, method, endpoint, nil, body, v))
+}
+
+// httpClient returns the client used for Graph API calls.
+func (r *Response) httpClient() *http.Client {
+	if r.client == nil {
+		return http.DefaultClient
+	}
+	return r.client
+}
+
+// graphCall makes a Graph API call about the recipient of r, with its
+// access token and HTTP client.
+func (r *Response) graphCall(method, endpoint string, body, v interface{}) error {
+	c := &graph.Client{HTTPClient: r.httpClient(), Token: r.accessToken()}
+	start := time.Now()
+	err := r.graphFailed(c.Do(r.Context(), method, endpoint, nil, body, v))
+	r.report.add(endpoint, time.Since(start), v, err)
+	return err
+}
+
+// WithHTTPClient returns a copy of r which makes its Graph API calls with
+// client.
+func (r *Response) WithHTTPClient(client *http.Client) *Response {
+	if r.messenger != nil {
+		client = r.messenger.withFailover(client)
+	}
+
+	c := r.clone()
+	c.client = client
+	return c
+}
+
+// WithPersona returns a copy of r which sends its messages as the persona
+// with the given ID.
+func (r *Response) WithPersona(personaID string) *Response {
+	c := r.clone()
+	c.persona = personaID
+	return c
+}
+
+// clone returns a copy of r, without its typing indicator.
+func (r *Response) clone() *Response {
+	return &Response{
+		token:     r.accessToken(),
+		to:        r.to,
+		messenger: r.messenger,
+		client:    r.client,
+		persona:   r.persona,
+		ctx:       r.ctx,
+		replies:   r.replies,
+		surface:   r.surface,
+		report:    r.report,
+	}
+}
+
+// WithProxy returns a copy of r which sends its Graph API calls through
+// proxy, overriding the proxy set in Options.
+func (r *Response) WithProxy(proxy *url.URL) *Response {
+	return r.WithHTTPClient(newProxyClient(proxy))
+}
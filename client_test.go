@@ -0,0 +1,125 @@
+package messenger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixturePSID is the ID of the user sending the webhook fixtures.
+const fixturePSID = 1254459154682919
+
+// serveFixture serves the webhook fixture name to the handler of m.
+func serveFixture(t *testing.T, m *Messenger, name string) {
+	f, err := os.Open("testdata/webhooks/" + name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", f))
+}
+
+// fakeGraph is an http.RoundTripper recording the requests made to the Graph
+// API and answering them with a canned response.
+type fakeGraph struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	status   int
+	response string
+	// header is added to the headers of the canned response.
+	header http.Header
+}
+
+func newFakeGraph(response string) *fakeGraph {
+	return &fakeGraph{status: http.StatusOK, response: response}
+}
+
+func (f *fakeGraph) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.bodies = append(f.bodies, string(body))
+	f.mu.Unlock()
+
+	header := http.Header{"Content-Type": {"application/json"}}
+	for k, v := range f.header {
+		header[k] = v
+	}
+	return &http.Response{
+		StatusCode: f.status,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(f.response)),
+		Request:    req,
+	}, nil
+}
+
+func (f *fakeGraph) client() *http.Client {
+	return &http.Client{Transport: f}
+}
+
+func (f *fakeGraph) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func TestMessenger_HTTPClient(t *testing.T) {
+	graph := newFakeGraph(`{"recipient_id":"111","message_id":"m1"}`)
+	m := New(Options{Token: "token", HTTPClient: graph.client()})
+
+	require.NoError(t, m.Response(111).Text("hello", ResponseType))
+	require.Equal(t, 1, graph.count())
+	assert.Equal(t, "graph.facebook.com", graph.requests[0].URL.Host)
+	assert.Equal(t, "token", graph.requests[0].URL.Query().Get("access_token"))
+	assert.JSONEq(t, `{"messaging_type":"RESPONSE","recipient":{"id":"111"},"message":{"text":"hello"}}`, graph.bodies[0])
+}
+
+func TestResponse_WithProxy(t *testing.T) {
+	var connected string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "CONNECT" {
+			connected = r.Host
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	m := New(Options{Token: "token"})
+	err = m.Response(111).WithProxy(proxyURL).Text("hello", ResponseType)
+
+	assert.Error(t, err)
+	assert.Equal(t, "graph.facebook.com:443", connected)
+}
+
+func TestNewResponse(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	r := NewResponse(ResponseOptions{
+		Token:      "token",
+		Recipient:  Recipient{ID: 111},
+		HTTPClient: graph.client(),
+		PersonaID:  "persona",
+	})
+
+	require.NoError(t, r.SenderAction(MarkSeenAction))
+	require.NoError(t, r.WithPersona("").Text("hello", UpdateType))
+
+	require.Equal(t, 2, graph.count())
+	assert.Equal(t, "token", graph.requests[0].URL.Query().Get("access_token"))
+	assert.JSONEq(t, `{"recipient":{"id":"111"},"sender_action":"mark_seen","persona_id":"persona"}`, graph.bodies[0])
+	assert.JSONEq(t, `{"messaging_type":"UPDATE","recipient":{"id":"111"},"message":{"text":"hello"}}`, graph.bodies[1])
+}
@@ -0,0 +1,49 @@
+package messenger
+
+import "time"
+
+// Clock tells the time. It allows tests to control the time seen by a
+// Messenger.
+type Clock interface {
+	Now() time.Time
+}
+
+// Ticker is implemented by Clocks which also drive the periodic work of a
+// Messenger, such as refreshing kept alive typing indicators. The system time
+// drives it for Clocks which do not.
+type Ticker interface {
+	// Tick returns a channel receiving a tick every d, and the function
+	// stopping the ticks.
+	Tick(d time.Duration) (<-chan time.Time, func())
+}
+
+// SystemClock is the Clock reading the system time.
+type SystemClock struct{}
+
+// Now returns the current local time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Tick ticks every d of system time.
+func (SystemClock) Tick(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(d)
+	return t.C, t.Stop
+}
+
+// now returns the current time according to the Clock set in Options.
+func (m *Messenger) now() time.Time {
+	if m.clock == nil {
+		return time.Now()
+	}
+	return m.clock.Now()
+}
+
+// tick ticks every d according to the Clock set in Options, if it is a
+// Ticker.
+func (m *Messenger) tick(d time.Duration) (<-chan time.Time, func()) {
+	if t, ok := m.clock.(Ticker); ok {
+		return t.Tick(d)
+	}
+	return SystemClock{}.Tick(d)
+}
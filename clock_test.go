@@ -0,0 +1,42 @@
+package messenger
+
+import (
+	"sync"
+	"time"
+)
+
+// fakeClock is a Clock which only moves forward when told to.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2018, 11, 24, 21, 31, 51, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// tickingClock is a fakeClock whose ticks are sent by the test.
+type tickingClock struct {
+	*fakeClock
+	ticks chan time.Time
+}
+
+func newTickingClock() tickingClock {
+	return tickingClock{fakeClock: newFakeClock(), ticks: make(chan time.Time)}
+}
+
+func (c tickingClock) Tick(time.Duration) (<-chan time.Time, func()) {
+	return c.ticks, func() {}
+}
@@ -0,0 +1,109 @@
+// Command new-bot creates the skeleton of a Messenger bot: commands, dialog
+// sessions persisted in a store, configuration from the environment, health
+// endpoints and a Dockerfile.
+//
+//	new-bot -module github.com/you/mybot mybot
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"golang.org/x/xerrors"
+)
+
+//go:embed templates
+var templates embed.FS
+
+// project is the data the templates are executed with.
+type project struct {
+	// Module is the module path of the bot.
+	Module string
+	// Name is the name of the binary.
+	Name string
+}
+
+func main() {
+	module := flag.String("module", "", "The module path of the bot (defaults to the directory name)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: new-bot [-module path] dir")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := flag.Arg(0)
+
+	p := project{Module: *module, Name: filepath.Base(dir)}
+	if p.Module == "" {
+		p.Module = p.Name
+	}
+
+	if err := generate(dir, p); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Created %s. Run \"go mod tidy\" in it to fetch the dependencies.\n", dir)
+}
+
+// generate writes the files of p into dir, which must not exist or be empty.
+func generate(dir string, p project) error {
+	if files, err := ioutil.ReadDir(dir); err == nil && len(files) > 0 {
+		return xerrors.Errorf("%s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	entries, err := templates.ReadDir("templates")
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".tmpl")
+
+		data, err := render(path.Join("templates", e.Name()), p)
+		if err != nil {
+			return xerrors.Errorf("%s: %w", name, err)
+		}
+		if strings.HasSuffix(name, ".go") {
+			if data, err = format.Source(data); err != nil {
+				return xerrors.Errorf("%s: %w", name, err)
+			}
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render executes the template file name with p.
+func render(name string, p project) ([]byte, error) {
+	t, err := template.ParseFS(templates, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
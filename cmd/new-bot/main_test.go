@@ -0,0 +1,38 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mybot")
+	require.NoError(t, generate(dir, project{Module: "example.com/mybot", Name: "mybot"}))
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+	assert.Equal(t, []string{"Dockerfile", "README.md", "config.go", "go.mod", "handlers.go", "main.go"}, names)
+
+	gomod, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(gomod), "module example.com/mybot\n"))
+
+	for _, name := range []string{"config.go", "handlers.go", "main.go"} {
+		_, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, 0)
+		assert.NoError(t, err, name)
+	}
+
+	// Existing projects are not overwritten.
+	assert.Error(t, generate(dir, project{Module: "example.com/mybot", Name: "mybot"}))
+}
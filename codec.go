@@ -0,0 +1,41 @@
+package messenger
+
+import "encoding/json"
+
+// Codec encodes and decodes JSON. It allows replacing encoding/json with a
+// faster, compatible implementation on the hot paths: decoding webhooks and
+// encoding sent messages. Implementations must honour the json.Marshaler and
+// json.Unmarshaler interfaces, as well as the encoding/json struct tags.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// StdCodec is the Codec using encoding/json.
+type StdCodec struct{}
+
+// Marshal calls json.Marshal.
+func (StdCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal calls json.Unmarshal.
+func (StdCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// jsonCodec returns the Codec set in Options.
+func (m *Messenger) jsonCodec() Codec {
+	if m.codec == nil {
+		return StdCodec{}
+	}
+	return m.codec
+}
+
+// jsonCodec returns the Codec of the Messenger which created r.
+func (r *Response) jsonCodec() Codec {
+	if r.messenger == nil {
+		return StdCodec{}
+	}
+	return r.messenger.jsonCodec()
+}
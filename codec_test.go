@@ -0,0 +1,98 @@
+package messenger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCodec is a Codec counting its calls.
+type countingCodec struct {
+	StdCodec
+	marshals, unmarshals int
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	c.marshals++
+	return c.StdCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	c.unmarshals++
+	return c.StdCodec.Unmarshal(data, v)
+}
+
+func TestMessenger_Codec(t *testing.T) {
+	codec := &countingCodec{}
+	graph := newFakeGraph(`{}`)
+	m := New(Options{Codec: codec, HTTPClient: graph.client()})
+
+	var texts []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		texts = append(texts, msg.Text)
+		require.NoError(t, r.Text("pong", ResponseType))
+	})
+	serveFixture(t, m, "message_text.json")
+
+	assert.Equal(t, []string{"hello, world!"}, texts)
+	assert.Equal(t, 1, codec.unmarshals)
+	assert.Equal(t, 1, codec.marshals)
+	assert.JSONEq(t, `{"messaging_type":"RESPONSE","recipient":{"id":"1254459154682919"},"message":{"text":"pong"}}`, graph.bodies[0])
+}
+
+func TestMessenger_CodecUnknownEvents(t *testing.T) {
+	codec := &countingCodec{}
+	m := New(Options{Codec: codec})
+
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 1, codec.unmarshals)
+
+	serveFixture(t, m, "reaction.json")
+	assert.Equal(t, 4, codec.unmarshals)
+	assert.Equal(t, []UnknownEvent{{Field: "reaction", Count: 1}}, m.UnknownEvents())
+}
+
+func BenchmarkHandle(b *testing.B) {
+	body, err := ioutil.ReadFile("testdata/webhooks/batched.json")
+	require.NoError(b, err)
+
+	m := New(Options{})
+	m.HandleMessage(func(Message, *Response) {})
+	h := m.Handler()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+	}
+}
+
+func BenchmarkDispatchMessage(b *testing.B) {
+	m := New(Options{HTTPClient: &http.Client{Transport: okTransport{}}})
+	r := m.Response(111)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.Text("hello, world!", ResponseType); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// okTransport answers every request with an empty success, without
+// recording anything.
+type okTransport struct{}
+
+func (okTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte(`{}`))),
+		Request:    req,
+	}, nil
+}
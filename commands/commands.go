@@ -0,0 +1,364 @@
+// Package commands routes "/command arg..." style messages to handlers, with
+// typed argument binding and automatically generated help.
+//
+//	type orderArgs struct {
+//		ID   int64  `arg:"id"`
+//		Note string `arg:"note,rest,optional"`
+//	}
+//
+//	router := commands.New()
+//	router.Handle(commands.Command{
+//		Name:        "order",
+//		Description: "Show an order",
+//		Args:        orderArgs{},
+//		Run: func(c commands.Call) {
+//			args := c.Args.(*orderArgs)
+//			...
+//		},
+//	})
+//	router.Register(client)
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/paked/messenger"
+	"golang.org/x/xerrors"
+)
+
+// Command is a command users can send.
+type Command struct {
+	// Name is what users type after the prefix to run the command.
+	Name string
+	// Description is shown in the help message.
+	Description string
+	// Args, if set, is a struct value whose fields the arguments of the
+	// command are bound to, in order. Fields are described by an "arg" tag
+	// holding the name of the argument followed by options: "optional" for
+	// arguments which can be left out, and "rest" for a final string field
+	// taking the remainder of the message. Supported field types are string,
+	// bool, the int types and the float types.
+	Args interface{}
+	// Run is called when the command is sent.
+	Run func(Call)
+}
+
+// Call is a command sent by a user.
+type Call struct {
+	// Message is the message the command was sent in.
+	Message messenger.Message
+	// Response is used to reply to the user.
+	Response *messenger.Response
+	// Args is a pointer to a new value of the type of Command.Args, holding
+	// the arguments of the command. Nil if the command has no Args.
+	Args interface{}
+}
+
+// Router dispatches commands to their handlers.
+type Router struct {
+	// Prefix starts every command. Defaults to "/".
+	Prefix string
+	// Unknown, if set, is called when a user sends a command which does not
+	// exist. Otherwise the help message is sent.
+	Unknown messenger.MessageHandler
+
+	commands map[string]*command
+	order    []string
+}
+
+type command struct {
+	Command
+	args []argument
+}
+
+type argument struct {
+	name     string
+	field    int
+	optional bool
+	rest     bool
+}
+
+// New creates a Router with a built-in "help" command.
+func New() *Router {
+	rt := &Router{
+		Prefix:   "/",
+		commands: make(map[string]*command),
+	}
+
+	rt.Handle(Command{
+		Name:        "help",
+		Description: "List the available commands",
+		Run: func(c Call) {
+			rt.SendHelp(c.Response, "")
+		},
+	})
+
+	return rt
+}
+
+// Handle registers a command, replacing any command with the same name.
+func (rt *Router) Handle(cmd Command) error {
+	if cmd.Name == "" || strings.ContainsAny(cmd.Name, " \t\n") {
+		return xerrors.Errorf("invalid command name %q", cmd.Name)
+	}
+	if cmd.Run == nil {
+		return xerrors.Errorf("command %s has no Run function", cmd.Name)
+	}
+
+	args, err := parseArgs(cmd.Args)
+	if err != nil {
+		return xerrors.Errorf("command %s: %w", cmd.Name, err)
+	}
+
+	if _, ok := rt.commands[cmd.Name]; !ok {
+		rt.order = append(rt.order, cmd.Name)
+	}
+	rt.commands[cmd.Name] = &command{Command: cmd, args: args}
+
+	return nil
+}
+
+// Commands returns the commands of the router with their prefix, such as
+// "/help", in the order they were registered.
+func (rt *Router) Commands() []string {
+	commands := make([]string, 0, len(rt.order))
+	for _, name := range rt.order {
+		commands = append(commands, rt.Prefix+name)
+	}
+	return commands
+}
+
+// Register adds the router to the message handlers of m, and its commands to
+// the HandlerTable of m.
+func (rt *Router) Register(m *messenger.Messenger) {
+	m.HandleMessage(rt.HandleMessage)
+	m.ListCommands(rt)
+}
+
+// HandleMessage is a messenger.MessageHandler running the command contained
+// in the message, if any. Messages which are not commands are ignored.
+func (rt *Router) HandleMessage(msg messenger.Message, r *messenger.Response) {
+	text := strings.TrimSpace(msg.Text)
+	if !strings.HasPrefix(text, rt.Prefix) {
+		return
+	}
+
+	name, rest := splitWord(strings.TrimPrefix(text, rt.Prefix))
+	cmd, ok := rt.commands[name]
+	if !ok {
+		if rt.Unknown != nil {
+			rt.Unknown(msg, r)
+			return
+		}
+
+		rt.SendHelp(r, fmt.Sprintf("Unknown command %s%s.", rt.Prefix, name))
+		return
+	}
+
+	args, err := cmd.bind(rest)
+	if err != nil {
+		r.Text(fmt.Sprintf("%s\nUsage: %s", err, rt.usage(cmd)), messenger.ResponseType)
+		return
+	}
+
+	cmd.Run(Call{
+		Message:  msg,
+		Response: r,
+		Args:     args,
+	})
+}
+
+// Help returns the help message listing every command, along with quick
+// replies to run the commands which take no required arguments.
+func (rt *Router) Help() (string, []messenger.QuickReply) {
+	var lines []string
+	var replies []messenger.QuickReply
+	for _, name := range rt.order {
+		cmd := rt.commands[name]
+		lines = append(lines, fmt.Sprintf("%s - %s", rt.usage(cmd), cmd.Description))
+
+		if cmd.requiredArgs() == 0 && len(replies) < messenger.MaxQuickReplies {
+			replies = append(replies, messenger.QuickReply{
+				ContentType: messenger.QuickReplyText,
+				Title:       rt.Prefix + name,
+				Payload:     rt.Prefix + name,
+			})
+		}
+	}
+
+	return strings.Join(lines, "\n"), replies
+}
+
+// SendHelp sends the help message, preceded by intro if not empty.
+func (rt *Router) SendHelp(r *messenger.Response, intro string) error {
+	text, replies := rt.Help()
+	if intro != "" {
+		text = intro + "\n" + text
+	}
+
+	return r.TextWithReplies(text, replies, messenger.ResponseType)
+}
+
+func (rt *Router) usage(cmd *command) string {
+	usage := rt.Prefix + cmd.Name
+	for _, a := range cmd.args {
+		if a.optional {
+			usage += " [" + a.name + "]"
+		} else {
+			usage += " <" + a.name + ">"
+		}
+	}
+	return usage
+}
+
+func (cmd *command) requiredArgs() int {
+	n := 0
+	for _, a := range cmd.args {
+		if !a.optional {
+			n++
+		}
+	}
+	return n
+}
+
+// parseArgs reads the description of the arguments from the fields of a
+// struct value.
+func parseArgs(v interface{}) ([]argument, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Struct {
+		return nil, xerrors.Errorf("Args must be a struct, got %s", t)
+	}
+
+	var args []argument
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("arg")
+		if !ok {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		a := argument{name: parts[0], field: i}
+		if a.name == "" {
+			a.name = strings.ToLower(f.Name)
+		}
+		if f.PkgPath != "" {
+			return nil, xerrors.Errorf("argument %s: field %s is unexported", a.name, f.Name)
+		}
+		for _, opt := range parts[1:] {
+			switch opt {
+			case "optional":
+				a.optional = true
+			case "rest":
+				a.rest = true
+			default:
+				return nil, xerrors.Errorf("unknown option %q on argument %s", opt, a.name)
+			}
+		}
+
+		if a.rest && (f.Type.Kind() != reflect.String || i != t.NumField()-1) {
+			return nil, xerrors.Errorf("argument %s: only the last field can be rest, and must be a string", a.name)
+		}
+		if len(args) > 0 && args[len(args)-1].optional && !a.optional {
+			return nil, xerrors.Errorf("argument %s: required arguments can not follow optional ones", a.name)
+		}
+		if _, err := convert("", f.Type); err == errUnsupported {
+			return nil, xerrors.Errorf("argument %s: unsupported type %s", a.name, f.Type)
+		}
+
+		args = append(args, a)
+	}
+
+	return args, nil
+}
+
+// bind parses the arguments of a command into a new value of its Args type.
+func (cmd *command) bind(text string) (interface{}, error) {
+	if cmd.Args == nil {
+		return nil, nil
+	}
+
+	v := reflect.New(reflect.TypeOf(cmd.Args))
+	for _, a := range cmd.args {
+		var word string
+		if a.rest {
+			word, text = strings.TrimSpace(text), ""
+		} else {
+			word, text = splitWord(text)
+		}
+
+		if word == "" {
+			if a.optional {
+				continue
+			}
+			return nil, xerrors.Errorf("Missing %s.", a.name)
+		}
+
+		field := v.Elem().Field(a.field)
+		value, err := convert(word, field.Type())
+		if err != nil {
+			return nil, xerrors.Errorf("Invalid %s %q.", a.name, word)
+		}
+		field.Set(value)
+	}
+
+	if strings.TrimSpace(text) != "" {
+		return nil, xerrors.Errorf("Too many arguments.")
+	}
+
+	return v.Interface(), nil
+}
+
+var errUnsupported = xerrors.New("unsupported type")
+
+// convert parses s into a value of type t.
+func convert(s string, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+
+	var err error
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, t.Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, t.Bits()); err == nil {
+			v.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, t.Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	default:
+		return v, errUnsupported
+	}
+
+	return v, err
+}
+
+// splitWord splits the first word off s.
+func splitWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexAny(s, " \t\n")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i+1:]
+}
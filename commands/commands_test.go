@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/paked/messenger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentTexts records the texts of the messages sent through it.
+type sentTexts []string
+
+func (s *sentTexts) RoundTrip(req *http.Request) (*http.Response, error) {
+	var m messenger.SendMessage
+	if err := json.NewDecoder(req.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	*s = append(*s, m.Message.Text)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+	}, nil
+}
+
+func TestRouter(t *testing.T) {
+	type orderArgs struct {
+		ID   int64  `arg:"id"`
+		Note string `arg:"note,rest,optional"`
+	}
+
+	var orders []orderArgs
+	rt := New()
+	require.NoError(t, rt.Handle(Command{
+		Name:        "order",
+		Description: "Show an order",
+		Args:        orderArgs{},
+		Run: func(c Call) {
+			orders = append(orders, *c.Args.(*orderArgs))
+		},
+	}))
+
+	var sent sentTexts
+	r := messenger.NewResponse(messenger.ResponseOptions{
+		Recipient:  messenger.Recipient{ID: 111},
+		HTTPClient: &http.Client{Transport: &sent},
+	})
+	send := func(text string) {
+		rt.HandleMessage(messenger.Message{Text: text}, r)
+	}
+
+	send("/order 42")
+	send("/order 43 leave at the door")
+	send("not a command")
+	assert.Equal(t, []orderArgs{{ID: 42}, {ID: 43, Note: "leave at the door"}}, orders)
+	assert.Empty(t, sent)
+
+	send("/order")
+	send("/order abc")
+	send("/refund 42")
+	send("/help")
+	assert.Equal(t, sentTexts{
+		"Missing id.\nUsage: /order <id> [note]",
+		"Invalid id \"abc\".\nUsage: /order <id> [note]",
+		"Unknown command /refund.\n/help - List the available commands\n/order <id> [note] - Show an order",
+		"/help - List the available commands\n/order <id> [note] - Show an order",
+	}, sent)
+
+	_, replies := rt.Help()
+	assert.Equal(t, []messenger.QuickReply{{ContentType: "text", Title: "/help", Payload: "/help"}}, replies)
+	assert.Equal(t, []string{"/help", "/order"}, rt.Commands())
+}
+
+func TestRouter_InvalidArgs(t *testing.T) {
+	rt := New()
+	run := func(Call) {}
+
+	assert.Error(t, rt.Handle(Command{Name: "bad", Args: 42, Run: run}))
+	assert.Error(t, rt.Handle(Command{Name: "bad", Args: struct {
+		C chan int `arg:"c"`
+	}{}, Run: run}))
+	assert.Error(t, rt.Handle(Command{Name: "bad", Args: struct {
+		A string `arg:"a,rest"`
+		B string `arg:"b"`
+	}{}, Run: run}))
+	assert.Error(t, rt.Handle(Command{Name: "bad", Args: struct {
+		unexported string `arg:"u"`
+	}{}, Run: run}))
+	assert.Error(t, rt.Handle(Command{Name: "two words", Run: run}))
+}
+
+func TestRouter_Register(t *testing.T) {
+	m := messenger.New(messenger.Options{})
+	New().Register(m)
+
+	table := m.Handlers()
+	assert.Equal(t, 1, table.Handlers["message"])
+	assert.Equal(t, []string{"/help"}, table.Commands)
+	assert.Contains(t, table.String(), "commands:\n  /help\n")
+}
@@ -0,0 +1,182 @@
+package messenger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// CommerceURL is the API endpoint for the Commerce orders of the page,
+// followed by the ID of an order. The Commerce API is not available in the
+// versions of the Graph API used by the rest of the package.
+// https://developers.facebook.com/docs/commerce-platform/order-management
+const CommerceURL = "https://graph.facebook.com/v8.0/"
+
+// commerceOrderField is the field of the changes to Commerce orders.
+const commerceOrderField = "commerce_order"
+
+// Events of an OrderEvent.
+const (
+	OrderCreated           = "ORDER_CREATED"
+	OrderEnabled           = "ORDER_ENABLED"
+	OrderFulfilled         = "ORDER_FULFILLED"
+	OrderMerchantCancelled = "ORDER_MERCHANT_CANCELLED"
+	OrderRefunded          = "ORDER_REFUNDED"
+)
+
+// Reasons of an OrderCancellation.
+const (
+	CancelReasonCustomerRequested = "CUSTOMER_REQUESTED"
+	CancelReasonOutOfStock        = "OUT_OF_STOCK"
+	CancelReasonInvalidAddress    = "INVALID_ADDRESS"
+	CancelReasonSuspiciousOrder   = "SUSPICIOUS_ORDER"
+	CancelReasonOther             = "CANCEL_REASON_OTHER"
+)
+
+// OrderEvent is a change to a Commerce order of the page.
+type OrderEvent struct {
+	// PageID is the ID of the page the order was placed with.
+	PageID string `json:"page_id"`
+	// OrderID is the ID of the order, to manage it with.
+	OrderID string `json:"order_id"`
+	// Event is what happened to the order, such as OrderCreated.
+	Event string `json:"event"`
+	// Time is when the change was sent.
+	Time time.Time `json:"-"`
+}
+
+// OrderHandler is a handler used to react to changes to Commerce orders,
+// such as acknowledging new ones.
+type OrderHandler func(OrderEvent)
+
+// OrderItem is a product of an order, and how many of it.
+type OrderItem struct {
+	RetailerID string `json:"retailer_id"`
+	Quantity   int    `json:"quantity"`
+}
+
+// OrderShipment is a shipment of items of an order.
+type OrderShipment struct {
+	Items        []OrderItem       `json:"items"`
+	TrackingInfo OrderTrackingInfo `json:"tracking_info"`
+}
+
+// OrderTrackingInfo tells buyers how to follow their shipment.
+type OrderTrackingInfo struct {
+	TrackingNumber string `json:"tracking_number"`
+	// Carrier is the code of the carrier, such as "FEDEX".
+	Carrier            string `json:"carrier"`
+	ShippingMethodName string `json:"shipping_method_name,omitempty"`
+}
+
+// OrderCancellation is the reason an order is cancelled.
+type OrderCancellation struct {
+	// Reason is one of the CancelReason constants.
+	Reason string
+	// Description is shown to the buyer.
+	Description string
+	// Restock puts the items of the order back in stock.
+	Restock bool
+}
+
+// HandleOrder adds a new OrderHandler to the Messenger which will be
+// triggered when a Commerce order of the page changes. The page must be
+// subscribed to the commerce_order field.
+func (m *Messenger) HandleOrder(f OrderHandler) {
+	m.orderHandlers = append(m.orderHandlers, f)
+}
+
+// AcknowledgeOrder moves a created order to in progress, so that it is no
+// longer cancelled automatically. reference is the ID of the order in the
+// systems of the merchant, or "".
+func (m *Messenger) AcknowledgeOrder(orderID, reference string) error {
+	body := map[string]string{}
+	if reference != "" {
+		body["merchant_order_reference"] = reference
+	}
+	return m.orderCall(orderID, "acknowledge_order", body)
+}
+
+// ShipOrder marks items of an order as shipped.
+func (m *Messenger) ShipOrder(orderID string, shipment OrderShipment) error {
+	return m.orderCall(orderID, "shipments", shipment)
+}
+
+// CancelOrder cancels an order which was not shipped.
+func (m *Messenger) CancelOrder(orderID string, cancellation OrderCancellation) error {
+	type cancelReason struct {
+		ReasonCode        string `json:"reason_code"`
+		ReasonDescription string `json:"reason_description,omitempty"`
+	}
+	return m.orderCall(orderID, "cancellations", struct {
+		CancelReason cancelReason `json:"cancel_reason"`
+		RestockItems bool         `json:"restock_items"`
+	}{
+		CancelReason: cancelReason{cancellation.Reason, cancellation.Description},
+		RestockItems: cancellation.Restock,
+	})
+}
+
+// orderCall manages an order with the Commerce API, sending request, a JSON
+// object, along with an idempotency key derived from the order and the
+// request, so that retrying a call with the same arguments, after a timeout
+// for instance, is applied once.
+func (m *Messenger) orderCall(orderID, edge string, request interface{}) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return xerrors.Errorf("could not encode %s of order %s: %w", edge, orderID, err)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return xerrors.Errorf("could not encode %s of order %s: %w", edge, orderID, err)
+	}
+	body["idempotency_key"], _ = json.Marshal(idempotencyKey(orderID, edge, data))
+
+	var resp struct {
+		Success bool `json:"success"`
+	}
+	err = m.graphCall("POST", CommerceURL+url.PathEscape(orderID)+"/"+edge, body, &resp)
+	if err != nil {
+		return xerrors.Errorf("could not update order %s: %w", orderID, err)
+	}
+	if !resp.Success {
+		return xerrors.Errorf("could not update order %s: %s was not successful", orderID, edge)
+	}
+	return nil
+}
+
+// dispatchChanges triggers the handlers of the changes of an entry.
+func (m *Messenger) dispatchChanges(ctx context.Context, entry Entry) {
+	for _, change := range entry.Changes {
+		if change.Field != commerceOrderField {
+			m.noteUnknown([]string{"changes." + change.Field}, change.Value)
+			logEvent(ctx, "Unknown change to", change.Field)
+			continue
+		}
+
+		var e OrderEvent
+		if err := json.Unmarshal(change.Value, &e); err != nil {
+			logEvent(ctx, "could not decode order event:", err)
+			continue
+		}
+		e.Time = time.Unix(0, entry.Time*int64(time.Millisecond))
+
+		for _, f := range m.orderHandlers {
+			f(e)
+		}
+	}
+}
+
+// idempotencyKey returns the key of a call to edge of an order with the
+// encoded request, the same every time the call is retried.
+func idempotencyKey(orderID, edge string, request []byte) string {
+	h := sha256.New()
+	h.Write([]byte(orderID + "/" + edge + "\n"))
+	h.Write(request)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
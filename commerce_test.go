@@ -0,0 +1,85 @@
+package messenger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_HandleOrder(t *testing.T) {
+	graph := newFakeGraph(`{"success":true}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	var events []OrderEvent
+	m.HandleOrder(func(e OrderEvent) {
+		events = append(events, e)
+		require.NoError(t, m.AcknowledgeOrder(e.OrderID, "ORDER-1"))
+	})
+	serveFixture(t, m, "commerce_order.json")
+
+	assert.Equal(t, []OrderEvent{{
+		PageID:  "1067280970047460",
+		OrderID: "64000841790004",
+		Event:   OrderCreated,
+		Time:    time.Unix(0, 1543095111999*int64(time.Millisecond)),
+	}}, events)
+
+	require.Equal(t, 1, graph.count())
+	assert.Equal(t, "/v8.0/64000841790004/acknowledge_order", graph.requests[0].URL.Path)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[0]), &body))
+	assert.Equal(t, "ORDER-1", body["merchant_order_reference"])
+	assert.Len(t, body["idempotency_key"], 32)
+}
+
+func TestMessenger_ShipAndCancelOrder(t *testing.T) {
+	graph := newFakeGraph(`{"success":true}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	require.NoError(t, m.ShipOrder("64000841790004", OrderShipment{
+		Items:        []OrderItem{{RetailerID: "SHOES-42", Quantity: 1}},
+		TrackingInfo: OrderTrackingInfo{TrackingNumber: "ZW9999", Carrier: "FEDEX"},
+	}))
+	require.NoError(t, m.CancelOrder("64000841790005", OrderCancellation{
+		Reason:      CancelReasonOutOfStock,
+		Description: "Sold out",
+		Restock:     true,
+	}))
+
+	require.Equal(t, 2, graph.count())
+	assert.Equal(t, "/v8.0/64000841790004/shipments", graph.requests[0].URL.Path)
+	var shipment struct {
+		OrderShipment
+		IdempotencyKey string `json:"idempotency_key"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[0]), &shipment))
+	assert.Equal(t, "ZW9999", shipment.TrackingInfo.TrackingNumber)
+	assert.NotEmpty(t, shipment.IdempotencyKey)
+
+	assert.Equal(t, "/v8.0/64000841790005/cancellations", graph.requests[1].URL.Path)
+	assert.Contains(t, graph.bodies[1], `"cancel_reason":{"reason_code":"OUT_OF_STOCK","reason_description":"Sold out"}`)
+	assert.Contains(t, graph.bodies[1], `"restock_items":true`)
+
+	require.NoError(t, m.ShipOrder("64000841790004", OrderShipment{
+		Items:        []OrderItem{{RetailerID: "SHOES-42", Quantity: 1}},
+		TrackingInfo: OrderTrackingInfo{TrackingNumber: "ZW9999", Carrier: "FEDEX"},
+	}))
+	require.NoError(t, m.ShipOrder("64000841790004", OrderShipment{
+		Items:        []OrderItem{{RetailerID: "SOCKS-7", Quantity: 2}},
+		TrackingInfo: OrderTrackingInfo{TrackingNumber: "ZW9998", Carrier: "FEDEX"},
+	}))
+	var retried, other struct {
+		IdempotencyKey string `json:"idempotency_key"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[2]), &retried))
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[3]), &other))
+	assert.Equal(t, shipment.IdempotencyKey, retried.IdempotencyKey)
+	assert.NotEqual(t, shipment.IdempotencyKey, other.IdempotencyKey)
+
+	graph = newFakeGraph(`{"success":false}`)
+	m = New(Options{HTTPClient: graph.client()})
+	assert.Error(t, m.AcknowledgeOrder("64000841790004", ""))
+}
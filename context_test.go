@@ -0,0 +1,49 @@
+package messenger
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type contextKey struct{}
+
+func TestMessenger_RequestContext(t *testing.T) {
+	m := New(Options{})
+
+	var got interface{}
+	m.HandleMessage(func(msg Message, r *Response) {
+		got = r.Context().Value(contextKey{})
+	})
+
+	f, err := os.Open("testdata/webhooks/message_text.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	req := httptest.NewRequest("POST", "/", f)
+	req = req.WithContext(context.WithValue(req.Context(), contextKey{}, "request"))
+	m.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "request", got)
+}
+
+func TestMessenger_DispatchContext(t *testing.T) {
+	m := New(Options{})
+
+	var got interface{}
+	m.HandlePostBack(func(p PostBack, r *Response) {
+		got = r.Context().Value(contextKey{})
+	})
+
+	rec := &Receive{Entry: []Entry{{Messaging: []MessageInfo{{
+		Sender:   Sender{ID: fixturePSID},
+		PostBack: &PostBack{Payload: "START"},
+	}}}}}
+	m.DispatchContext(context.WithValue(context.Background(), contextKey{}, "dispatch"), rec)
+
+	assert.Equal(t, "dispatch", got)
+}
@@ -0,0 +1,74 @@
+package messenger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// correlationMetadataPrefix starts the correlation ID in the metadata of
+// sent messages.
+const correlationMetadataPrefix = "cid:"
+
+type correlationKey struct{}
+
+// CorrelationIDFromContext returns the correlation ID of the webhook event
+// being handled, set when Options.CorrelationIDs is, or "".
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// CorrelationIDFromMetadata returns the correlation ID carried by the
+// metadata of a message, such as that of an echo, or "".
+func CorrelationIDFromMetadata(metadata string) string {
+	for _, field := range strings.Fields(metadata) {
+		if strings.HasPrefix(field, correlationMetadataPrefix) {
+			return strings.TrimPrefix(field, correlationMetadataPrefix)
+		}
+	}
+	return ""
+}
+
+// withCorrelationID returns ctx with a new correlation ID, when the
+// Messenger generates them.
+func (m *Messenger) withCorrelationID(ctx context.Context) context.Context {
+	if !m.correlationIDs {
+		return ctx
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationKey{}, hex.EncodeToString(b))
+}
+
+// tagCorrelation adds the correlation ID of ctx to the metadata of msg,
+// after the token of echo confirmations. Metadata set by the caller is left
+// as it is.
+func tagCorrelation(ctx context.Context, msg interface{}) {
+	id := CorrelationIDFromContext(ctx)
+	metadata := metadataOf(msg)
+	if id == "" || metadata == nil {
+		return
+	}
+
+	switch {
+	case *metadata == "":
+		*metadata = correlationMetadataPrefix + id
+	case strings.HasPrefix(*metadata, echoMetadataPrefix):
+		*metadata += " " + correlationMetadataPrefix + id
+	}
+}
+
+// logEvent prints a log line, prefixed with the correlation ID of ctx if it
+// has one.
+func logEvent(ctx context.Context, a ...interface{}) {
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		a = append([]interface{}{"[" + id + "]"}, a...)
+	}
+	fmt.Println(a...)
+}
@@ -0,0 +1,66 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_CorrelationIDs(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	var confirmed []EchoConfirmation
+	m := New(Options{
+		HTTPClient:      graph.client(),
+		CorrelationIDs:  true,
+		OnEchoConfirmed: func(c EchoConfirmation) { confirmed = append(confirmed, c) },
+	})
+
+	var ids []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		if msg.IsEcho {
+			return
+		}
+		ids = append(ids, CorrelationIDFromContext(r.Context()))
+		require.NoError(t, r.Text("pong", ResponseType))
+	})
+	serveFixture(t, m, "batched.json")
+
+	require.Len(t, ids, 2)
+	assert.Len(t, ids[0], 16)
+	assert.NotEqual(t, ids[0], ids[1])
+
+	require.Equal(t, 2, graph.count())
+	var sent SendMessage
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[0]), &sent))
+	assert.True(t, strings.HasPrefix(sent.Message.Metadata, echoMetadataPrefix))
+	assert.Equal(t, ids[0], CorrelationIDFromMetadata(sent.Message.Metadata))
+
+	// The echo is still recognised with the correlation ID in its metadata.
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(echoWebhook(sent.Message.Metadata))))
+	assert.Len(t, confirmed, 1)
+}
+
+func TestMessenger_CorrelationIDsDisabled(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	var ids []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		ids = append(ids, CorrelationIDFromContext(r.Context()))
+		require.NoError(t, r.Text("pong", ResponseType))
+	})
+	serveFixture(t, m, "message_text.json")
+
+	assert.Equal(t, []string{""}, ids)
+	assert.JSONEq(t, `{"messaging_type":"RESPONSE","recipient":{"id":"1254459154682919"},"message":{"text":"pong"}}`, graph.bodies[0])
+}
+
+func TestCorrelationIDFromMetadata(t *testing.T) {
+	assert.Equal(t, "abc", CorrelationIDFromMetadata("cid:abc"))
+	assert.Equal(t, "abc", CorrelationIDFromMetadata("messenger-echo:0123 cid:abc"))
+	assert.Equal(t, "", CorrelationIDFromMetadata("mine"))
+}
@@ -0,0 +1,279 @@
+// Package dialog runs conversations defined as finite state machines: each
+// state prompts the user when it is entered and decides on the next state
+// from their answer. Sessions are persisted in a messenger.Store so that
+// conversations survive restarts.
+package dialog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paked/messenger"
+	"golang.org/x/xerrors"
+)
+
+// End is the state returned by a transition to end the flow.
+const End = ""
+
+// DefaultCancelWords are the messages which interrupt a flow when it does
+// not set its own.
+var DefaultCancelWords = []string{"cancel", "stop"}
+
+// State is a step of a Flow.
+type State struct {
+	// Name identifies the state within its flow.
+	Name string
+	// OnEnter is called when the conversation enters the state, usually to
+	// ask the user a question.
+	OnEnter func(s *Session, r *messenger.Response)
+	// OnInput is called with the answer of the user and returns the name of
+	// the next state. Returning the name of the current state stays in it
+	// without calling OnEnter again, and returning End ends the flow.
+	OnInput func(s *Session, msg messenger.Message, r *messenger.Response) string
+}
+
+// Flow is a conversation made of states.
+type Flow struct {
+	// Name identifies the flow.
+	Name string
+	// Start is the name of the first state.
+	Start string
+	// States are the states of the flow.
+	States []State
+	// CancelWords are the messages, compared case-insensitively, which
+	// interrupt the flow. Defaults to DefaultCancelWords.
+	CancelWords []string
+	// OnCancel is called when the user interrupts the flow.
+	OnCancel func(s *Session, r *messenger.Response)
+	// Timeout, if set, is how long the user has to answer before the flow is
+	// abandoned.
+	Timeout time.Duration
+	// OnTimeout is called when the user answers after Timeout. The answer is
+	// then left for other handlers.
+	OnTimeout func(s *Session, r *messenger.Response)
+	// OnComplete is called when the flow reaches End.
+	OnComplete func(s *Session, r *messenger.Response)
+}
+
+func (f *Flow) state(name string) (State, bool) {
+	for _, s := range f.States {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return State{}, false
+}
+
+func (f *Flow) isCancel(text string) bool {
+	words := f.CancelWords
+	if words == nil {
+		words = DefaultCancelWords
+	}
+
+	text = strings.TrimSpace(text)
+	for _, w := range words {
+		if strings.EqualFold(text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Session is the progress of a user through a flow.
+type Session struct {
+	// PSID is the page-scoped ID of the user.
+	PSID int64 `json:"psid,string"`
+	// Flow is the name of the flow.
+	Flow string `json:"flow"`
+	// State is the name of the current state.
+	State string `json:"state"`
+	// Data holds the answers collected so far.
+	Data map[string]string `json:"data"`
+	// Updated is when the session last changed state.
+	Updated time.Time `json:"updated"`
+}
+
+// Manager runs flows, keeping track of where each user is.
+type Manager struct {
+	// Clock is used for timeouts. Defaults to messenger.SystemClock.
+	Clock messenger.Clock
+	// OnError, if set, is called with the errors of HandleMessage, which can
+	// not return them. Otherwise they are printed.
+	OnError func(err error, r *messenger.Response)
+
+	store messenger.Store
+	mu    sync.RWMutex
+	flows map[string]*Flow
+}
+
+// NewManager creates a Manager persisting sessions in store.
+func NewManager(store messenger.Store) *Manager {
+	return &Manager{
+		Clock: messenger.SystemClock{},
+		store: store,
+		flows: make(map[string]*Flow),
+	}
+}
+
+// Register adds a flow to the Manager.
+func (m *Manager) Register(f *Flow) error {
+	if _, ok := f.state(f.Start); !ok {
+		return xerrors.Errorf("flow %s: unknown start state %q", f.Name, f.Start)
+	}
+	for _, s := range f.States {
+		if s.OnInput == nil {
+			return xerrors.Errorf("flow %s: state %s has no OnInput", f.Name, s.Name)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.flows[f.Name] = f
+	return nil
+}
+
+// Start puts the user in the first state of a flow, abandoning any flow they
+// were in.
+func (m *Manager) Start(flow string, psid int64, r *messenger.Response) error {
+	f, ok := m.flow(flow)
+	if !ok {
+		return xerrors.Errorf("unknown flow %q", flow)
+	}
+
+	s := &Session{
+		PSID: psid,
+		Flow: flow,
+		Data: make(map[string]string),
+	}
+	return m.enter(f, s, f.Start, r)
+}
+
+// Session returns the session of the user, or nil if they are not in a
+// flow.
+func (m *Manager) Session(psid int64) (*Session, error) {
+	data, err := m.store.Get(sessionKey(psid))
+	if err == messenger.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Handle feeds a message to the flow the sender is in, reporting whether it
+// was consumed. Messages of users who are not in a flow are not consumed.
+func (m *Manager) Handle(msg messenger.Message, r *messenger.Response) (bool, error) {
+	s, err := m.Session(msg.Sender.ID)
+	if err != nil || s == nil {
+		return false, err
+	}
+
+	f, ok := m.flow(s.Flow)
+	if !ok {
+		return false, m.store.Delete(sessionKey(s.PSID))
+	}
+
+	if f.Timeout > 0 && m.Clock.Now().Sub(s.Updated) > f.Timeout {
+		if err := m.store.Delete(sessionKey(s.PSID)); err != nil {
+			return false, err
+		}
+		if f.OnTimeout != nil {
+			f.OnTimeout(s, r)
+		}
+		return false, nil
+	}
+
+	if f.isCancel(msg.Text) {
+		if err := m.store.Delete(sessionKey(s.PSID)); err != nil {
+			return true, err
+		}
+		if f.OnCancel != nil {
+			f.OnCancel(s, r)
+		}
+		return true, nil
+	}
+
+	state, ok := f.state(s.State)
+	if !ok {
+		return false, m.store.Delete(sessionKey(s.PSID))
+	}
+
+	next := state.OnInput(s, msg, r)
+	switch next {
+	case End:
+		if err := m.store.Delete(sessionKey(s.PSID)); err != nil {
+			return true, err
+		}
+		if f.OnComplete != nil {
+			f.OnComplete(s, r)
+		}
+		return true, nil
+	case s.State:
+		return true, m.save(s)
+	default:
+		return true, m.enter(f, s, next, r)
+	}
+}
+
+// HandleMessage is a messenger.MessageHandler feeding messages to flows.
+func (m *Manager) HandleMessage(msg messenger.Message, r *messenger.Response) {
+	_, err := m.Handle(msg, r)
+	if err == nil {
+		return
+	}
+
+	if m.OnError != nil {
+		m.OnError(err, r)
+	} else {
+		fmt.Println("could not handle message in dialog:", err)
+	}
+}
+
+func (m *Manager) enter(f *Flow, s *Session, name string, r *messenger.Response) error {
+	state, ok := f.state(name)
+	if !ok {
+		return xerrors.Errorf("flow %s: unknown state %q", f.Name, name)
+	}
+
+	s.State = name
+	s.Updated = m.Clock.Now()
+	if err := m.save(s); err != nil {
+		return err
+	}
+
+	if state.OnEnter != nil {
+		state.OnEnter(s, r)
+	}
+	return nil
+}
+
+func (m *Manager) save(s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return m.store.Set(sessionKey(s.PSID), data)
+}
+
+func (m *Manager) flow(name string) (*Flow, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.flows[name]
+	return f, ok
+}
+
+func sessionKey(psid int64) string {
+	return "dialog:" + strconv.FormatInt(psid, 10)
+}
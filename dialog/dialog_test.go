@@ -0,0 +1,135 @@
+package dialog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/paked/messenger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func newTestManager(t *testing.T, prompts *[]string) (*Manager, *fakeClock) {
+	prompt := func(text string) func(*Session, *messenger.Response) {
+		return func(*Session, *messenger.Response) {
+			*prompts = append(*prompts, text)
+		}
+	}
+
+	clock := &fakeClock{t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := NewManager(messenger.NewMemoryStore())
+	m.Clock = clock
+	require.NoError(t, m.Register(&Flow{
+		Name:  "booking",
+		Start: "size",
+		States: []State{
+			{
+				Name:    "size",
+				OnEnter: prompt("How many people?"),
+				OnInput: func(s *Session, msg messenger.Message, r *messenger.Response) string {
+					if msg.Text == "" {
+						return "size"
+					}
+					s.Data["size"] = msg.Text
+					return "time"
+				},
+			},
+			{
+				Name:    "time",
+				OnEnter: prompt("What time?"),
+				OnInput: func(s *Session, msg messenger.Message, r *messenger.Response) string {
+					s.Data["time"] = msg.Text
+					return End
+				},
+			},
+		},
+		Timeout:   time.Hour,
+		OnCancel:  prompt("cancelled"),
+		OnTimeout: prompt("timed out"),
+		OnComplete: func(s *Session, r *messenger.Response) {
+			*prompts = append(*prompts, "booked "+s.Data["size"]+" at "+s.Data["time"])
+		},
+	}))
+	return m, clock
+}
+
+func message(text string) messenger.Message {
+	return messenger.Message{Sender: messenger.Sender{ID: 111}, Text: text}
+}
+
+func TestManager(t *testing.T) {
+	var prompts []string
+	m, _ := newTestManager(t, &prompts)
+
+	ok, err := m.Handle(message("hi"), nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, m.Start("booking", 111, nil))
+	for _, text := range []string{"", "4", "8pm"} {
+		ok, err := m.Handle(message(text), nil)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	assert.Equal(t, []string{"How many people?", "What time?", "booked 4 at 8pm"}, prompts)
+
+	s, err := m.Session(111)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+}
+
+func TestManagerCancel(t *testing.T) {
+	var prompts []string
+	m, _ := newTestManager(t, &prompts)
+
+	require.NoError(t, m.Start("booking", 111, nil))
+	ok, err := m.Handle(message(" Cancel "), nil)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"How many people?", "cancelled"}, prompts)
+
+	s, err := m.Session(111)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+}
+
+func TestManagerTimeout(t *testing.T) {
+	var prompts []string
+	m, clock := newTestManager(t, &prompts)
+
+	require.NoError(t, m.Start("booking", 111, nil))
+	clock.t = clock.t.Add(2 * time.Hour)
+
+	ok, err := m.Handle(message("4"), nil)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []string{"How many people?", "timed out"}, prompts)
+}
+
+func TestRegisterUnknownStart(t *testing.T) {
+	m := NewManager(messenger.NewMemoryStore())
+	err := m.Register(&Flow{Name: "empty", Start: "nowhere"})
+	assert.Error(t, err)
+}
+
+// brokenStore is a messenger.Store whose reads fail.
+type brokenStore struct{ *messenger.MemoryStore }
+
+func (brokenStore) Get(string) ([]byte, error) { return nil, xerrors.New("store down") }
+
+func TestManagerHandleMessageError(t *testing.T) {
+	m := NewManager(brokenStore{messenger.NewMemoryStore()})
+	var errs []error
+	m.OnError = func(err error, r *messenger.Response) {
+		errs = append(errs, err)
+	}
+
+	m.HandleMessage(message("hello"), nil)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "store down")
+}
@@ -0,0 +1,295 @@
+package dialog
+
+import (
+	"encoding/json"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/paked/messenger"
+	"golang.org/x/xerrors"
+)
+
+// DefaultMaxRetries is how many invalid answers a Form accepts for a
+// question when it does not set its own limit.
+const DefaultMaxRetries = 3
+
+// ErrInvalidAnswer is returned by validators rejecting an answer.
+var ErrInvalidAnswer = xerrors.New("invalid answer")
+
+// Validator checks the answer of a user and returns the value to store.
+type Validator func(msg messenger.Message) (string, error)
+
+// Question is a question of a Form.
+type Question struct {
+	// Field is the name of the struct field the answer is stored in, either
+	// as set by its `form` tag or as written in Go.
+	Field string
+	// Prompt is the question sent to the user.
+	Prompt string
+	// Validate checks the answer. Defaults to NonEmpty.
+	Validate Validator
+	// Retry is sent after an invalid answer. Defaults to asking the
+	// question again.
+	Retry string
+}
+
+// Form asks the user a series of questions and fills a struct with the
+// answers.
+type Form struct {
+	// Name identifies the form, and the flow which runs it.
+	Name string
+	// Target is the struct to fill, for example Booking{}. Each completion
+	// gets its own copy.
+	Target interface{}
+	// Questions are asked in order.
+	Questions []Question
+	// MaxRetries is how many invalid answers are accepted for a question
+	// before the form is abandoned. Defaults to DefaultMaxRetries.
+	MaxRetries int
+	// OnComplete receives a pointer to the filled struct.
+	OnComplete func(psid int64, v interface{}, r *messenger.Response)
+	// OnAbandon is called when the user gave too many invalid answers or
+	// cancelled the form.
+	OnAbandon func(psid int64, r *messenger.Response)
+}
+
+// abandonedKey marks a session which ended because of invalid answers.
+const abandonedKey = "_abandoned"
+
+// RegisterForm registers the flow running a form. It is started like any
+// other flow, with Start and the name of the form.
+func (m *Manager) RegisterForm(f *Form) error {
+	t := reflect.TypeOf(f.Target)
+	if t == nil || t.Kind() != reflect.Struct {
+		return xerrors.Errorf("form %s: target must be a struct, got %T", f.Name, f.Target)
+	}
+	if len(f.Questions) == 0 {
+		return xerrors.Errorf("form %s: no questions", f.Name)
+	}
+
+	maxRetries := f.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = DefaultMaxRetries
+	}
+
+	flow := &Flow{
+		Name:  f.Name,
+		Start: f.Questions[0].Field,
+		OnCancel: func(s *Session, r *messenger.Response) {
+			if f.OnAbandon != nil {
+				f.OnAbandon(s.PSID, r)
+			}
+		},
+		OnComplete: func(s *Session, r *messenger.Response) {
+			if s.Data[abandonedKey] != "" {
+				if f.OnAbandon != nil {
+					f.OnAbandon(s.PSID, r)
+				}
+				return
+			}
+
+			v := reflect.New(t)
+			for _, q := range f.Questions {
+				// Answers were checked against the field when given.
+				setField(v.Elem(), q.Field, s.Data[q.Field])
+			}
+			if f.OnComplete != nil {
+				f.OnComplete(s.PSID, v.Interface(), r)
+			}
+		},
+	}
+
+	for i, q := range f.Questions {
+		if q.Field == "" {
+			return xerrors.Errorf("form %s: question %d has no field", f.Name, i)
+		}
+		if _, ok := field(reflect.New(t).Elem(), q.Field); !ok {
+			return xerrors.Errorf("form %s: unknown field %q", f.Name, q.Field)
+		}
+
+		next := End
+		if i+1 < len(f.Questions) {
+			next = f.Questions[i+1].Field
+		}
+		flow.States = append(flow.States, questionState(t, q, next, maxRetries))
+	}
+
+	return m.Register(flow)
+}
+
+func questionState(t reflect.Type, q Question, next string, maxRetries int) State {
+	validate := q.Validate
+	if validate == nil {
+		validate = NonEmpty
+	}
+	retry := q.Retry
+	if retry == "" {
+		retry = q.Prompt
+	}
+	retriesKey := "_retries:" + q.Field
+
+	return State{
+		Name: q.Field,
+		OnEnter: func(s *Session, r *messenger.Response) {
+			r.Text(q.Prompt, messenger.ResponseType)
+		},
+		OnInput: func(s *Session, msg messenger.Message, r *messenger.Response) string {
+			value, err := validate(msg)
+			if err == nil {
+				err = setField(reflect.New(t).Elem(), q.Field, value)
+			}
+			if err == nil {
+				s.Data[q.Field] = value
+				delete(s.Data, retriesKey)
+				return next
+			}
+
+			retries, _ := strconv.Atoi(s.Data[retriesKey])
+			if retries >= maxRetries {
+				s.Data[abandonedKey] = q.Field
+				return End
+			}
+			s.Data[retriesKey] = strconv.Itoa(retries + 1)
+
+			r.Text(retry, messenger.ResponseType)
+			return q.Field
+		},
+	}
+}
+
+// field finds the struct field called name by its `form` tag or Go name.
+func field(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if sf.Tag.Get("form") == name || sf.Name == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setField converts value to the type of the named field and sets it.
+func setField(v reflect.Value, name, value string) error {
+	f, ok := field(v, name)
+	if !ok {
+		return xerrors.Errorf("unknown field %q", name)
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	default:
+		return xerrors.Errorf("field %s: unsupported type %s", name, f.Type())
+	}
+	return nil
+}
+
+// NonEmpty accepts any answer with text.
+func NonEmpty(msg messenger.Message) (string, error) {
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		return "", ErrInvalidAnswer
+	}
+	return text, nil
+}
+
+// Email accepts an email address, preferring the one found by the built-in
+// NLP when it is enabled.
+func Email(msg messenger.Message) (string, error) {
+	if value, ok := nlpEntity(msg, "email"); ok {
+		return value, nil
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(msg.Text))
+	if err != nil || !strings.Contains(addr.Address, ".") {
+		return "", ErrInvalidAnswer
+	}
+	return addr.Address, nil
+}
+
+// Phone accepts a phone number, preferring the one found by the built-in NLP
+// when it is enabled. Spaces, dashes, dots and parentheses are removed.
+func Phone(msg messenger.Message) (string, error) {
+	text := strings.TrimSpace(msg.Text)
+	if value, ok := nlpEntity(msg, "phone_number"); ok {
+		text = value
+	}
+
+	number := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, text)
+
+	digits := strings.TrimPrefix(number, "+")
+	if len(digits) < 7 || len(digits) > 15 {
+		return "", ErrInvalidAnswer
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidAnswer
+		}
+	}
+	return number, nil
+}
+
+// nlpEntity returns the most confident value of a built-in NLP entity. Both
+// the legacy names ("email") and the Wit.ai ones ("wit$email:email") match.
+func nlpEntity(msg messenger.Message, name string) (string, bool) {
+	if len(msg.NLP) == 0 {
+		return "", false
+	}
+
+	var nlp struct {
+		Entities map[string][]struct {
+			Confidence float64     `json:"confidence"`
+			Value      interface{} `json:"value"`
+		} `json:"entities"`
+	}
+	if err := json.Unmarshal(msg.NLP, &nlp); err != nil {
+		return "", false
+	}
+
+	var (
+		best       string
+		confidence = -1.0
+	)
+	for key, entities := range nlp.Entities {
+		if key != name && !strings.HasSuffix(key, ":"+name) {
+			continue
+		}
+		for _, e := range entities {
+			value, ok := e.Value.(string)
+			if ok && e.Confidence > confidence {
+				best, confidence = value, e.Confidence
+			}
+		}
+	}
+	return best, confidence >= 0
+}
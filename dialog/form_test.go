@@ -0,0 +1,99 @@
+package dialog
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/paked/messenger"
+	"github.com/paked/messenger/messengertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentTexts returns the texts of the messages sent to graph.
+func sentTexts(t *testing.T, graph *messengertest.GraphServer) []string {
+	var texts []string
+	for _, body := range graph.Sent() {
+		var m messenger.SendMessage
+		require.NoError(t, json.Unmarshal([]byte(body), &m))
+		texts = append(texts, m.Message.Text)
+	}
+	return texts
+}
+
+type signup struct {
+	Email string `form:"email"`
+	Phone string `form:"phone"`
+	Age   int    `form:"age"`
+}
+
+func TestForm(t *testing.T) {
+	var (
+		done      []signup
+		abandoned int
+	)
+	m := NewManager(messenger.NewMemoryStore())
+	require.NoError(t, m.RegisterForm(&Form{
+		Name:   "signup",
+		Target: signup{},
+		Questions: []Question{
+			{Field: "email", Prompt: "Email?", Validate: Email, Retry: "That is not an email."},
+			{Field: "phone", Prompt: "Phone?", Validate: Phone},
+			{Field: "age", Prompt: "Age?"},
+		},
+		MaxRetries: 2,
+		OnComplete: func(psid int64, v interface{}, r *messenger.Response) {
+			done = append(done, *v.(*signup))
+		},
+		OnAbandon: func(psid int64, r *messenger.Response) {
+			abandoned++
+		},
+	}))
+
+	graph := messengertest.NewGraphServer()
+	defer graph.Close()
+	r := messenger.NewResponse(messenger.ResponseOptions{
+		Recipient:  messenger.Recipient{ID: 111},
+		HTTPClient: graph.Client(),
+	})
+
+	require.NoError(t, m.Start("signup", 111, r))
+	m.HandleMessage(message("nope"), r)
+	m.HandleMessage(messenger.Message{
+		Sender: messenger.Sender{ID: 111},
+		Text:   "it's jo@example.com",
+		NLP: json.RawMessage(`{"entities":{"wit$email:email":[
+			{"confidence":0.5,"value":"other@example.com"},
+			{"confidence":0.9,"value":"jo@example.com"}
+		]}}`),
+	}, r)
+	m.HandleMessage(message("+61 (4) 1234-5678"), r)
+	m.HandleMessage(message("forty"), r)
+	m.HandleMessage(message("40"), r)
+
+	assert.Equal(t, []string{"Email?", "That is not an email.", "Phone?", "Age?", "Age?"}, sentTexts(t, graph))
+	assert.Equal(t, []signup{{Email: "jo@example.com", Phone: "+61412345678", Age: 40}}, done)
+	assert.Equal(t, 0, abandoned)
+
+	require.NoError(t, m.Start("signup", 111, r))
+	m.HandleMessage(message("nope"), r)
+	m.HandleMessage(message("still nope"), r)
+	// MaxRetries invalid answers are accepted, the next one abandons.
+	assert.Equal(t, 0, abandoned)
+	m.HandleMessage(message("nope again"), r)
+	assert.Equal(t, 1, abandoned)
+
+	s, err := m.Session(111)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+}
+
+func TestRegisterFormUnknownField(t *testing.T) {
+	m := NewManager(messenger.NewMemoryStore())
+	err := m.RegisterForm(&Form{
+		Name:      "signup",
+		Target:    signup{},
+		Questions: []Question{{Field: "name", Prompt: "Name?"}},
+	})
+	assert.Error(t, err)
+}
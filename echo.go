@@ -0,0 +1,166 @@
+package messenger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultEchoWindow is how long echoes of sent messages are waited for when
+// Options does not set its own window.
+const DefaultEchoWindow = time.Minute
+
+// echoMetadataPrefix starts the metadata attached to messages whose echo is
+// waited for.
+const echoMetadataPrefix = "messenger-echo:"
+
+// EchoConfirmation is the confirmation, by its echo, that a message sent by
+// the Messenger appeared in the thread.
+type EchoConfirmation struct {
+	// Recipient is who the message was sent to.
+	Recipient Recipient
+	// Mid is the ID of the message.
+	Mid string
+	// Sent is when the message was sent.
+	Sent time.Time
+	// Confirmed is when the echo arrived.
+	Confirmed time.Time
+}
+
+// echoTracker remembers the messages whose echo is waited for, by the token
+// in their metadata.
+type echoTracker struct {
+	onConfirmed func(EchoConfirmation)
+	window      time.Duration
+
+	mu      sync.Mutex
+	pending map[string]pendingEcho
+}
+
+type pendingEcho struct {
+	to   Recipient
+	sent time.Time
+}
+
+// tagForEcho attaches a token to the metadata of msg, unless it already has
+// metadata, so that its echo can be recognised.
+func (m *Messenger) tagForEcho(to Recipient, msg interface{}) {
+	if m.echoes.onConfirmed == nil {
+		return
+	}
+
+	metadata := metadataOf(msg)
+	if metadata == nil || *metadata != "" {
+		return
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return
+	}
+	token := hex.EncodeToString(b)
+	*metadata = echoMetadataPrefix + token
+
+	now := m.now()
+	window := m.echoes.window
+	if window <= 0 {
+		window = DefaultEchoWindow
+	}
+
+	m.echoes.mu.Lock()
+	defer m.echoes.mu.Unlock()
+
+	if m.echoes.pending == nil {
+		m.echoes.pending = make(map[string]pendingEcho)
+	}
+	for t, p := range m.echoes.pending {
+		if now.Sub(p.sent) > window {
+			delete(m.echoes.pending, t)
+		}
+	}
+	m.echoes.pending[token] = pendingEcho{to: to, sent: now}
+}
+
+// untagForEcho stops waiting for the echo of a message which was not sent.
+func (m *Messenger) untagForEcho(msg interface{}) {
+	metadata := metadataOf(msg)
+	if metadata == nil || !strings.HasPrefix(*metadata, echoMetadataPrefix) {
+		return
+	}
+
+	m.echoes.mu.Lock()
+	defer m.echoes.mu.Unlock()
+
+	delete(m.echoes.pending, echoToken(*metadata))
+}
+
+// confirmEcho reports the echo of a message sent by the Messenger, returning
+// whether it was one.
+func (m *Messenger) confirmEcho(info MessageInfo) bool {
+	msg := info.Message
+	if m.echoes.onConfirmed == nil || !msg.IsEcho || !strings.HasPrefix(msg.Metadata, echoMetadataPrefix) {
+		return false
+	}
+
+	token := echoToken(msg.Metadata)
+	m.echoes.mu.Lock()
+	p, ok := m.echoes.pending[token]
+	delete(m.echoes.pending, token)
+	m.echoes.mu.Unlock()
+
+	if !ok {
+		// The echo of a message sent by an earlier run, or after the window.
+		return true
+	}
+
+	m.echoes.onConfirmed(EchoConfirmation{
+		Recipient: p.to,
+		Mid:       msg.Mid,
+		Sent:      p.sent,
+		Confirmed: m.now(),
+	})
+	return true
+}
+
+// foreignEcho reports whether info is the echo of a message sent by an app
+// other than those in Options.EchoAppIDs, such as the Page inbox.
+func (m *Messenger) foreignEcho(info MessageInfo) bool {
+	if len(m.echoAppIDs) == 0 || !info.Message.IsEcho {
+		return false
+	}
+
+	for _, id := range m.echoAppIDs {
+		if info.Message.AppID == id {
+			return false
+		}
+	}
+	return true
+}
+
+// metadataOf returns the metadata field of a sent message, or nil if it has
+// none.
+func metadataOf(msg interface{}) *string {
+	switch msg := msg.(type) {
+	case *SendMessage:
+		return &msg.Message.Metadata
+	case *SendStructuredMessage:
+		return &msg.Message.Metadata
+	case *SendTemplateMessage:
+		return &msg.Message.Metadata
+	case *queuedMessage:
+		return &msg.metadata
+	}
+	return nil
+}
+
+// echoToken returns the token of echo confirmations in metadata, which may
+// be followed by a correlation ID.
+func echoToken(metadata string) string {
+	token := strings.TrimPrefix(metadata, echoMetadataPrefix)
+	if i := strings.IndexByte(token, ' '); i >= 0 {
+		token = token[:i]
+	}
+	return token
+}
@@ -0,0 +1,85 @@
+package messenger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func echoWebhook(metadata string) string {
+	return fmt.Sprintf(`{"object":"page","entry":[{"id":"1067280970047460","time":1543095111999,"messaging":[{"sender":{"id":"1067280970047460"},"recipient":{"id":"1254459154682919"},"timestamp":1543095111999,"message":{"is_echo":true,"app_id":1517776481860111,"metadata":%q,"mid":"m_echo","text":"Hi"}}]}]}`, metadata)
+}
+
+func TestMessenger_OnEchoConfirmed(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	clock := newFakeClock()
+
+	var confirmed []EchoConfirmation
+	m := New(Options{
+		HTTPClient:      graph.client(),
+		Clock:           clock,
+		OnEchoConfirmed: func(c EchoConfirmation) { confirmed = append(confirmed, c) },
+	})
+
+	var echoes []Message
+	m.HandleMessage(func(msg Message, r *Response) { echoes = append(echoes, msg) })
+
+	require.NoError(t, m.Response(fixturePSID).Text("Hi", ResponseType))
+	var sent SendMessage
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[0]), &sent))
+	require.True(t, strings.HasPrefix(sent.Message.Metadata, echoMetadataPrefix))
+
+	clock.Advance(time.Second)
+	post := func(body string) {
+		m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	}
+	post(echoWebhook(sent.Message.Metadata))
+	post(echoWebhook("DEVELOPER_DEFINED_METADATA_STRING"))
+
+	require.Len(t, confirmed, 1)
+	assert.Equal(t, "m_echo", confirmed[0].Mid)
+	assert.Equal(t, Recipient{ID: fixturePSID}, confirmed[0].Recipient)
+	assert.Equal(t, time.Second, confirmed[0].Confirmed.Sub(confirmed[0].Sent))
+
+	require.Len(t, echoes, 1)
+	assert.Equal(t, "DEVELOPER_DEFINED_METADATA_STRING", echoes[0].Metadata)
+}
+
+func TestMessenger_EchoMetadataKept(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client(), OnEchoConfirmed: func(EchoConfirmation) {}})
+
+	msg := SendMessage{
+		MessagingType: ResponseType,
+		Recipient:     Recipient{ID: 111},
+		Message:       MessageData{Text: "Hi", Metadata: "mine"},
+	}
+	require.NoError(t, m.Response(111).DispatchMessage(&msg))
+	assert.Contains(t, graph.bodies[0], `"metadata":"mine"`)
+}
+
+func TestMessenger_EchoAppIDs(t *testing.T) {
+	for _, test := range []struct {
+		appIDs []int64
+		texts  []string
+	}{
+		{nil, []string{"Thanks for your message!", "hello, world!"}},
+		{[]int64{1517776481860111}, []string{"Thanks for your message!", "hello, world!"}},
+		{[]int64{42}, []string{"hello, world!"}},
+	} {
+		m := New(Options{EchoAppIDs: test.appIDs})
+
+		var texts []string
+		m.HandleMessage(func(msg Message, r *Response) { texts = append(texts, msg.Text) })
+
+		serveFixture(t, m, "message_echo.json")
+		serveFixture(t, m, "message_text.json")
+		assert.Equal(t, test.texts, texts, "%v", test.appIDs)
+	}
+}
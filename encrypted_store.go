@@ -0,0 +1,192 @@
+package messenger
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// SecretsProvider supplies secrets, such as encryption keys, by name. It lets
+// keys be kept in a secret manager rather than in the code or configuration
+// of the bot.
+type SecretsProvider interface {
+	// Secret returns the secret called name.
+	Secret(name string) ([]byte, error)
+}
+
+// SecretsFunc is a function used as a SecretsProvider.
+type SecretsFunc func(name string) ([]byte, error)
+
+// Secret calls f.
+func (f SecretsFunc) Secret(name string) ([]byte, error) {
+	return f(name)
+}
+
+// ErrDecrypt is returned by an EncryptedStore when a value cannot be
+// decrypted, because it was tampered with or its key is wrong.
+var ErrDecrypt = xerrors.New("cannot decrypt value")
+
+// envelopeVersion is the first byte of the values of an EncryptedStore.
+const envelopeVersion = 1
+
+// dataKeySize is the size of the AES-256 key generated for every value.
+const dataKeySize = 32
+
+// EncryptedStore is a Store encrypting the values of another Store with
+// AES-GCM, so that sessions and queued messages can be persisted encrypted.
+// Keys are not encrypted, since stores list them by prefix.
+//
+// Every value is encrypted with its own random data key, itself encrypted
+// with the key encryption key named KeyName, obtained from the
+// SecretsProvider. The name is stored with the value, so that values written
+// before KeyName is changed remain readable as long as their key is still
+// provided. Key encryption keys must be 16, 24 or 32 bytes long.
+type EncryptedStore struct {
+	// Store persists the encrypted values.
+	Store Store
+	// Secrets provides the key encryption keys.
+	Secrets SecretsProvider
+	// KeyName is the name of the key encrypting new values.
+	KeyName string
+}
+
+// NewEncryptedStore creates an EncryptedStore encrypting the values of store
+// with the key keyName of secrets.
+func NewEncryptedStore(store Store, secrets SecretsProvider, keyName string) *EncryptedStore {
+	return &EncryptedStore{Store: store, Secrets: secrets, KeyName: keyName}
+}
+
+// Get returns the decrypted value of key, or ErrNotFound.
+func (s *EncryptedStore) Get(key string) ([]byte, error) {
+	data, err := s.Store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(key, data)
+}
+
+// Set encrypts value and stores it under key.
+func (s *EncryptedStore) Set(key string, value []byte) error {
+	data, err := s.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return s.Store.Set(key, data)
+}
+
+// Delete removes key.
+func (s *EncryptedStore) Delete(key string) error {
+	return s.Store.Delete(key)
+}
+
+// Keys returns the keys starting with prefix, in lexical order.
+func (s *EncryptedStore) Keys(prefix string) ([]string, error) {
+	return s.Store.Keys(prefix)
+}
+
+// seal encrypts the value of key. The envelope is the version, the key name
+// preceded by its length, the sealed data key and the sealed value. The store
+// key is authenticated with the value so that values cannot be swapped.
+func (s *EncryptedStore) seal(key string, value []byte) ([]byte, error) {
+	if len(s.KeyName) > 255 {
+		return nil, xerrors.Errorf("key name %q too long", s.KeyName)
+	}
+
+	kek, err := s.keyCipher(s.KeyName)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	dek, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data := append([]byte{envelopeVersion, byte(len(s.KeyName))}, s.KeyName...)
+	data, err = sealWithNonce(kek, data, dataKey, []byte(s.KeyName))
+	if err != nil {
+		return nil, err
+	}
+	return sealWithNonce(dek, data, value, []byte(key))
+}
+
+// open decrypts an envelope written by seal.
+func (s *EncryptedStore) open(key string, data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != envelopeVersion || len(data) < 2+int(data[1]) {
+		return nil, ErrDecrypt
+	}
+	name := string(data[2 : 2+int(data[1])])
+	data = data[2+len(name):]
+
+	kek, err := s.keyCipher(name)
+	if err != nil {
+		return nil, err
+	}
+
+	sealedKeySize := kek.NonceSize() + dataKeySize + kek.Overhead()
+	if len(data) < sealedKeySize {
+		return nil, ErrDecrypt
+	}
+	dataKey, err := openWithNonce(kek, data[:sealedKeySize], []byte(name))
+	if err != nil {
+		return nil, err
+	}
+	dek, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return openWithNonce(dek, data[sealedKeySize:], []byte(key))
+}
+
+// keyCipher returns the cipher of the key encryption key called name.
+func (s *EncryptedStore) keyCipher(name string) (cipher.AEAD, error) {
+	secret, err := s.Secrets.Secret(name)
+	if err != nil {
+		return nil, xerrors.Errorf("get key %q: %w", name, err)
+	}
+
+	aead, err := newGCM(secret)
+	if err != nil {
+		return nil, xerrors.Errorf("key %q: %w", name, err)
+	}
+	return aead, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealWithNonce appends a random nonce and the sealed plaintext to dst.
+func sealWithNonce(aead cipher.AEAD, dst, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(append(dst, nonce...), nonce, plaintext, additional), nil
+}
+
+// openWithNonce opens data written by sealWithNonce.
+func openWithNonce(aead cipher.AEAD, data, additional []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, sealed, additional)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,84 @@
+package messenger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func testSecrets(keys map[string][]byte) SecretsProvider {
+	return SecretsFunc(func(name string) ([]byte, error) {
+		key, ok := keys[name]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		return key, nil
+	})
+}
+
+func TestEncryptedStore(t *testing.T) {
+	keys := map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)}
+	backing := NewMemoryStore()
+	s := NewEncryptedStore(backing, testSecrets(keys), "v1")
+
+	_, err := s.Get("a")
+	assert.Equal(t, ErrNotFound, err)
+
+	require.NoError(t, s.Set("a", []byte("secret session")))
+	got, err := s.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret session"), got)
+
+	raw, err := backing.Get("a")
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, []byte("secret session")))
+
+	// Values written with a previous key stay readable.
+	keys["v2"] = bytes.Repeat([]byte{2}, 16)
+	s.KeyName = "v2"
+	require.NoError(t, s.Set("b", []byte("new")))
+	got, err = s.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret session"), got)
+
+	keyNames, err := s.Keys("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keyNames)
+
+	require.NoError(t, s.Delete("a"))
+	_, err = s.Get("a")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestEncryptedStore_Tampering(t *testing.T) {
+	backing := NewMemoryStore()
+	s := NewEncryptedStore(backing, testSecrets(map[string][]byte{"k": bytes.Repeat([]byte{1}, 32)}), "k")
+	require.NoError(t, s.Set("a", []byte("1")))
+	require.NoError(t, s.Set("b", []byte("2")))
+
+	// A value moved to another key is rejected.
+	raw, err := backing.Get("a")
+	require.NoError(t, err)
+	require.NoError(t, backing.Set("b", raw))
+	_, err = s.Get("b")
+	assert.Equal(t, ErrDecrypt, err)
+
+	raw[len(raw)-1] ^= 1
+	require.NoError(t, backing.Set("a", raw))
+	_, err = s.Get("a")
+	assert.Equal(t, ErrDecrypt, err)
+
+	require.NoError(t, backing.Set("a", []byte{envelopeVersion}))
+	_, err = s.Get("a")
+	assert.Equal(t, ErrDecrypt, err)
+}
+
+func TestEncryptedStore_MissingKey(t *testing.T) {
+	s := NewEncryptedStore(NewMemoryStore(), testSecrets(nil), "k")
+
+	err := s.Set("a", []byte("1"))
+	assert.True(t, xerrors.Is(err, ErrNotFound))
+}
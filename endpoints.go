@@ -0,0 +1,153 @@
+package messenger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoints is a profile of the infrastructure the Messenger makes its Graph
+// API calls to, set with Options.Endpoints, so that a whole bot switches
+// between live and test infrastructure by changing a single option.
+type Endpoints struct {
+	// Name identifies the profile, such as "production".
+	Name string
+	// GraphURL is the base URL of the Graph API replacing
+	// https://graph.facebook.com, such as the URL of a
+	// messengertest.GraphServer. Empty means Facebook.
+	GraphURL string
+	// Recording, if set, receives every Graph API call and its answer as a
+	// line of JSON, see RecordedCall. Calls contain the data of users.
+	Recording io.Writer
+}
+
+// ProductionEndpoints makes Graph API calls to Facebook. This is the
+// default.
+func ProductionEndpoints() Endpoints {
+	return Endpoints{Name: "production"}
+}
+
+// MockEndpoints makes Graph API calls to the fake Graph API at graphURL,
+// such as a messengertest.GraphServer.
+func MockEndpoints(graphURL string) Endpoints {
+	return Endpoints{Name: "mock", GraphURL: graphURL}
+}
+
+// RecordingEndpoints makes Graph API calls to Facebook, writing them to w
+// as they are made.
+func RecordingEndpoints(w io.Writer) Endpoints {
+	return Endpoints{Name: "recording", Recording: w}
+}
+
+// RecordedCall is a Graph API call written by RecordingEndpoints.
+type RecordedCall struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	// URL is the URL called, without the access token.
+	URL     string `json:"url"`
+	Request string `json:"request,omitempty"`
+	// Status is the HTTP status of the answer, or 0 when the call failed.
+	Status   int    `json:"status"`
+	Response string `json:"response,omitempty"`
+	// Error is why the call failed.
+	Error string `json:"error,omitempty"`
+}
+
+// endpointTransport sends Graph API requests to the endpoints of a profile.
+type endpointTransport struct {
+	graph *url.URL
+	base  http.RoundTripper
+	now   func() time.Time
+
+	mu        sync.Mutex
+	recording io.Writer
+}
+
+func (t *endpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.graph != nil && req.URL.Host == DefaultGraphHost {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = t.graph.Scheme
+		req.URL.Host = t.graph.Host
+		req.URL.Path = strings.TrimSuffix(t.graph.Path, "/") + req.URL.Path
+		req.Host = t.graph.Host
+	}
+	if t.recording == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	call := RecordedCall{Time: t.now(), Method: req.Method, URL: redactToken(req.URL)}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := ioutil.ReadAll(body)
+			call.Request = string(data)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		call.Error = err.Error()
+		t.record(call)
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+	call.Status = resp.StatusCode
+	call.Response = string(data)
+	if err != nil {
+		call.Error = err.Error()
+	}
+	t.record(call)
+	return resp, err
+}
+
+func (t *endpointTransport) record(call RecordedCall) {
+	data, err := json.Marshal(call)
+	if err != nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recording.Write(append(data, '\n'))
+}
+
+// redactToken returns u without its access token.
+func redactToken(u *url.URL) string {
+	redacted := *u
+	q := redacted.Query()
+	if q.Get("access_token") != "" {
+		q.Set("access_token", "REDACTED")
+		redacted.RawQuery = q.Encode()
+	}
+	return redacted.String()
+}
+
+// withEndpoints returns a copy of client which makes its calls to the
+// endpoints set in Options.
+func (m *Messenger) withEndpoints(client *http.Client) *http.Client {
+	t := &endpointTransport{base: client.Transport, now: m.now, recording: m.endpoints.Recording}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	if m.endpoints.GraphURL != "" {
+		// Options.Validate reports invalid URLs.
+		t.graph, _ = url.Parse(m.endpoints.GraphURL)
+	}
+
+	c := *client
+	c.Transport = t
+	return &c
+}
+
+// Endpoints returns the endpoints profile the Messenger uses.
+func (m *Messenger) Endpoints() Endpoints {
+	return m.endpoints
+}
@@ -0,0 +1,62 @@
+package messenger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_MockEndpoints(t *testing.T) {
+	var paths []string
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"recipient_id":"1254459154682919","message_id":"m1"}`))
+	}))
+	defer graph.Close()
+
+	m := New(Options{Token: "token", Endpoints: MockEndpoints(graph.URL + "/graph")})
+	assert.Equal(t, "mock", m.Endpoints().Name)
+
+	require.NoError(t, m.Response(fixturePSID).Text("hello", ResponseType))
+	assert.Equal(t, []string{"/graph/v2.11/me/messages"}, paths)
+}
+
+func TestMessenger_RecordingEndpoints(t *testing.T) {
+	graph := newFakeGraph(`{"recipient_id":"1254459154682919","message_id":"m1"}`)
+	var recording bytes.Buffer
+	m := New(Options{
+		Token:      "token",
+		HTTPClient: graph.client(),
+		Clock:      newFakeClock(),
+		Endpoints:  RecordingEndpoints(&recording),
+	})
+
+	require.NoError(t, m.Response(fixturePSID).Text("hello", ResponseType))
+	require.Equal(t, 1, graph.count())
+	assert.Equal(t, `{"messaging_type":"RESPONSE","recipient":{"id":"1254459154682919"},"message":{"text":"hello"}}`, graph.bodies[0])
+
+	lines := bufio.NewScanner(&recording)
+	require.True(t, lines.Scan())
+	var call RecordedCall
+	require.NoError(t, json.Unmarshal(lines.Bytes(), &call))
+	assert.Equal(t, newFakeClock().Now(), call.Time.UTC())
+	assert.Equal(t, "POST", call.Method)
+	assert.Equal(t, "https://graph.facebook.com/v2.11/me/messages?access_token=REDACTED", call.URL)
+	assert.JSONEq(t, graph.bodies[0], call.Request)
+	assert.Equal(t, http.StatusOK, call.Status)
+	assert.JSONEq(t, `{"recipient_id":"1254459154682919","message_id":"m1"}`, call.Response)
+	assert.False(t, lines.Scan())
+}
+
+func TestMessenger_ProductionEndpoints(t *testing.T) {
+	m := New(Options{})
+	assert.Equal(t, ProductionEndpoints(), m.Endpoints())
+	_, rewritten := m.httpClient().Transport.(*rateLimitTransport).base.(*endpointTransport)
+	assert.False(t, rewritten)
+}
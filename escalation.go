@@ -0,0 +1,191 @@
+package messenger
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// HumanAgentTag is the message tag allowing human agents to answer a user up
+// to seven days after their last message.
+const HumanAgentTag = "HUMAN_AGENT"
+
+// escalationPrefix prefixes the keys of escalated conversations in the Store.
+const escalationPrefix = "escalation:"
+
+// ErrNoMessenger is returned by operations of a Response which need the
+// Messenger it was created by.
+var ErrNoMessenger = xerrors.New("response was not created by a Messenger")
+
+// Escalation is a conversation handed over to human agents.
+type Escalation struct {
+	// PSID is the page-scoped ID of the user.
+	PSID int64 `json:"psid"`
+	// Queue is the queue of agents the conversation was escalated to.
+	Queue string `json:"queue"`
+	// Time is when the conversation was escalated.
+	Time time.Time `json:"time"`
+}
+
+// AgentQueue hands escalated conversations over to an agent console of your
+// own.
+type AgentQueue interface {
+	Enqueue(e Escalation) error
+}
+
+// AgentQueueFunc is an adapter to allow the use of ordinary functions as an
+// AgentQueue.
+type AgentQueueFunc func(e Escalation) error
+
+// Enqueue calls f(e).
+func (f AgentQueueFunc) Enqueue(e Escalation) error {
+	return f(e)
+}
+
+// EscalationOptions configure how conversations are handed over to human
+// agents.
+type EscalationOptions struct {
+	// AgentQueue, if set, receives escalated conversations, which then stay
+	// with the page. Otherwise thread control is passed to TargetAppID.
+	AgentQueue AgentQueue
+	// TargetAppID is the app receiving thread control. Defaults to
+	// InboxPageID, the Page Inbox.
+	TargetAppID int64
+	// OnEscalate is called once a conversation has been escalated.
+	OnEscalate func(Escalation)
+	// OnRelease is called once the bot is back in control of a
+	// conversation.
+	OnRelease func(Escalation)
+	// Store persists escalated conversations, so that they survive restarts
+	// and are seen by every instance of the bot. Defaults to a MemoryStore.
+	Store Store
+	// MaxAge, if set, is how long a conversation stays escalated before it
+	// is released, in case the agents never release it.
+	MaxAge time.Duration
+}
+
+func escalationKey(psid int64) string {
+	return escalationPrefix + strconv.FormatInt(psid, 10)
+}
+
+// EscalateToAgent hands the conversation over to the agents of queue. Until
+// the agents release it, events from the user are not passed to handlers.
+//
+// Thread control is passed to the app set in Options.Escalation, with queue
+// as metadata, and taken back when that app passes it back. With an
+// AgentQueue, the conversation is released by calling
+// Messenger.ReleaseFromAgent.
+func (r *Response) EscalateToAgent(queue string) error {
+	m := r.messenger
+	if m == nil {
+		return ErrNoMessenger
+	}
+
+	e := Escalation{
+		PSID:  r.to.ID,
+		Queue: queue,
+		Time:  m.now(),
+	}
+
+	var err error
+	if m.escalation.AgentQueue != nil {
+		err = m.escalation.AgentQueue.Enqueue(e)
+	} else {
+		target := m.escalation.TargetAppID
+		if target == 0 {
+			target = InboxPageID
+		}
+		err = r.PassThreadControl(target, queue)
+	}
+	if err != nil {
+		return xerrors.Errorf("could not escalate to %s: %w", queue, err)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := m.escalation.Store.Set(escalationKey(e.PSID), data); err != nil {
+		return xerrors.Errorf("could not save escalation to %s: %w", queue, err)
+	}
+
+	if m.escalation.OnEscalate != nil {
+		m.escalation.OnEscalate(e)
+	}
+	return nil
+}
+
+// AgentText sends a message written by a human agent, tagged with
+// HumanAgentTag so that it may be sent outside of the 24 hour window.
+func (r *Response) AgentText(text string) error {
+	return r.Text(text, MessageTagType, HumanAgentTag)
+}
+
+// ReleaseFromAgent gives control of an escalated conversation back to the
+// bot. It is called automatically when thread control is passed back.
+func (m *Messenger) ReleaseFromAgent(psid int64) {
+	m.escalationsMu.Lock()
+	e, ok := m.escalationOf(psid)
+	if ok {
+		if err := m.escalation.Store.Delete(escalationKey(psid)); err != nil {
+			m.recordError(xerrors.Errorf("could not release escalation of %s: %w", m.psidHasher.Hash(psid), err))
+			ok = false
+		}
+	}
+	m.escalationsMu.Unlock()
+
+	if ok && m.escalation.OnRelease != nil {
+		m.escalation.OnRelease(e)
+	}
+}
+
+// Escalated returns the escalation of the conversation with a user, if it is
+// with human agents. Escalations older than EscalationOptions.MaxAge are
+// released.
+func (m *Messenger) Escalated(psid int64) (Escalation, bool) {
+	e, ok := m.escalationOf(psid)
+	if ok && m.escalation.MaxAge > 0 && m.now().Sub(e.Time) >= m.escalation.MaxAge {
+		m.ReleaseFromAgent(psid)
+		return Escalation{}, false
+	}
+	return e, ok
+}
+
+// escalationOf reads the escalation of the conversation with a user from the
+// Store.
+func (m *Messenger) escalationOf(psid int64) (Escalation, bool) {
+	var e Escalation
+	if m.escalation.Store == nil {
+		return e, false
+	}
+
+	data, err := m.escalation.Store.Get(escalationKey(psid))
+	if err == ErrNotFound {
+		return e, false
+	} else if err != nil {
+		m.recordError(xerrors.Errorf("could not read escalation of %s: %w", m.psidHasher.Hash(psid), err))
+		return e, false
+	}
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		m.recordError(xerrors.Errorf("could not decode escalation of %s: %w", m.psidHasher.Hash(psid), err))
+		return e, false
+	}
+	return e, true
+}
+
+func (m *Messenger) isEscalated(psid int64) bool {
+	_, ok := m.Escalated(psid)
+	return ok
+}
+
+// hasEscalations reports whether some conversations are with human agents.
+func (m *Messenger) hasEscalations() bool {
+	if m.escalation.Store == nil {
+		return false
+	}
+	keys, err := m.escalation.Store.Keys(escalationPrefix)
+	return err != nil || len(keys) > 0
+}
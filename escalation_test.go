@@ -0,0 +1,133 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_EscalateToAgentQueue(t *testing.T) {
+	var queued, released []Escalation
+	m := New(Options{
+		Clock: newFakeClock(),
+		Escalation: EscalationOptions{
+			AgentQueue: AgentQueueFunc(func(e Escalation) error {
+				queued = append(queued, e)
+				return nil
+			}),
+			OnRelease: func(e Escalation) {
+				released = append(released, e)
+			},
+		},
+	})
+
+	var handled int
+	m.HandleMessage(func(msg Message, r *Response) {
+		handled++
+	})
+
+	require.NoError(t, m.Response(fixturePSID).EscalateToAgent("billing"))
+	require.Len(t, queued, 1)
+	assert.Equal(t, Escalation{PSID: fixturePSID, Queue: "billing", Time: m.now()}, queued[0])
+
+	e, ok := m.Escalated(fixturePSID)
+	assert.True(t, ok)
+	assert.Equal(t, "billing", e.Queue)
+
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 0, handled)
+
+	m.ReleaseFromAgent(fixturePSID)
+	assert.Equal(t, queued, released)
+
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 1, handled)
+}
+
+func TestResponse_EscalateToAgentHandover(t *testing.T) {
+	graph := newFakeGraph(`{"success":true}`)
+	var escalated, released int
+	m := New(Options{
+		HTTPClient: graph.client(),
+		Escalation: EscalationOptions{
+			TargetAppID: 42,
+			OnEscalate:  func(Escalation) { escalated++ },
+			OnRelease:   func(Escalation) { released++ },
+		},
+	})
+
+	require.NoError(t, m.Response(fixturePSID).EscalateToAgent("billing"))
+	require.Equal(t, 1, graph.count())
+	assert.Equal(t, "/v2.6/me/pass_thread_control", graph.requests[0].URL.Path)
+	assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"target_app_id":42,"metadata":"billing"}`, graph.bodies[0])
+	assert.Equal(t, 1, escalated)
+
+	serveFixture(t, m, "pass_thread_control.json")
+	assert.Equal(t, 1, released)
+	_, ok := m.Escalated(fixturePSID)
+	assert.False(t, ok)
+}
+
+func TestMessenger_EscalationStore(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryStore()
+	queue := AgentQueueFunc(func(Escalation) error { return nil })
+
+	var released []Escalation
+	first := New(Options{Clock: clock, Escalation: EscalationOptions{AgentQueue: queue, Store: store}})
+	second := New(Options{Clock: clock, Escalation: EscalationOptions{
+		AgentQueue: queue,
+		Store:      store,
+		MaxAge:     time.Hour,
+		OnRelease:  func(e Escalation) { released = append(released, e) },
+	}})
+
+	require.NoError(t, first.Response(fixturePSID).EscalateToAgent("billing"))
+	e, ok := second.Escalated(fixturePSID)
+	require.True(t, ok)
+	assert.Equal(t, "billing", e.Queue)
+	assert.True(t, e.Time.Equal(clock.Now()))
+
+	clock.Advance(time.Hour)
+	_, ok = second.Escalated(fixturePSID)
+	assert.False(t, ok)
+	assert.Len(t, released, 1)
+
+	keys, err := store.Keys(escalationPrefix)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestResponse_EscalateToAgentStandalone(t *testing.T) {
+	r := NewResponse(ResponseOptions{Recipient: Recipient{ID: 111}})
+	assert.Equal(t, ErrNoMessenger, r.EscalateToAgent("billing"))
+}
+
+func TestMessenger_TakeThreadControlReleasesEscalation(t *testing.T) {
+	graph := newFakeGraph(`{"success":true}`)
+	var released int
+	m := New(Options{
+		HTTPClient: graph.client(),
+		Escalation: EscalationOptions{
+			TargetAppID: 42,
+			OnRelease:   func(Escalation) { released++ },
+		},
+	})
+
+	var handled int
+	m.HandleMessage(func(Message, *Response) { handled++ })
+
+	require.NoError(t, m.Response(fixturePSID).EscalateToAgent("billing"))
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 0, handled)
+
+	require.NoError(t, m.TakeThreadControl(Recipient{ID: fixturePSID}, "Agent timed out"))
+	assert.Equal(t, 1, released)
+	_, ok := m.Escalated(fixturePSID)
+	assert.False(t, ok)
+
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 1, handled)
+}
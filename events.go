@@ -0,0 +1,142 @@
+package messenger
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrEventProcessed is returned by EventHooks.BeginEvent for events which
+// have already been processed, so that their handlers are skipped.
+var ErrEventProcessed = xerrors.New("event already processed")
+
+// eventPrefix prefixes the keys of processed events in the Store.
+const eventPrefix = "event:"
+
+// Event is a webhook event being processed.
+type Event struct {
+	// ID identifies the event across deliveries by Facebook: the mid of
+	// messages and postbacks, or a key made of the sender, the timestamp and
+	// the action otherwise.
+	ID string
+	// Action is the kind of event.
+	Action Action
+	// PSID is the page-scoped ID of the sender.
+	PSID int64
+	// Time is when the event happened.
+	Time time.Time
+}
+
+// EventHooks wrap the processing of each webhook event, so that the side
+// effects of handlers can be committed in a transaction keyed by the event,
+// making them happen exactly once even when Facebook delivers an event twice.
+type EventHooks struct {
+	// BeginEvent is called before the handlers of an event run. The context
+	// it returns, carrying a transaction for instance, is the one handlers
+	// get from Response.Context and the one given to CommitEvent and
+	// AbortEvent. Returning ErrEventProcessed skips the event; other errors
+	// are recorded and skip it too.
+	BeginEvent func(ctx context.Context, e Event) (context.Context, error)
+	// CommitEvent is called once the handlers of an event have returned.
+	CommitEvent func(ctx context.Context, e Event) error
+	// AbortEvent is called instead of CommitEvent when a handler panics,
+	// before the panic carries on, or when a handler is abandoned, with an
+	// error wrapping ErrHandlerTimeout as reason once it timed out.
+	AbortEvent func(ctx context.Context, e Event, reason interface{})
+}
+
+// DedupEventHooks returns EventHooks skipping the events whose processing
+// was committed before, remembered in store. Keys are never removed, so a
+// store expiring old keys is advisable for long running bots.
+//
+// The check and the commit are separate calls to store and not atomic: two
+// deliveries of the same event processed concurrently can both run their
+// handlers. Bots needing a strict guarantee should take a lock or a unique
+// row keyed by Event.ID in BeginEvent instead.
+func DedupEventHooks(store Store) EventHooks {
+	return EventHooks{
+		BeginEvent: func(ctx context.Context, e Event) (context.Context, error) {
+			_, err := store.Get(eventPrefix + e.ID)
+			if err == nil {
+				return ctx, ErrEventProcessed
+			}
+			if err == ErrNotFound {
+				return ctx, nil
+			}
+			return ctx, err
+		},
+		CommitEvent: func(ctx context.Context, e Event) error {
+			return store.Set(eventPrefix+e.ID, []byte(e.Time.UTC().Format(time.RFC3339)))
+		},
+	}
+}
+
+// newEvent describes the event of info.
+func newEvent(a Action, info MessageInfo) Event {
+	e := Event{
+		Action: a,
+		PSID:   info.Sender.ID,
+		Time:   time.Unix(info.Timestamp/int64(time.Microsecond), 0),
+	}
+
+	switch {
+	case info.Message != nil && info.Message.Mid != "":
+		e.ID = info.Message.Mid
+	case info.PostBack != nil && info.PostBack.Mid != "":
+		e.ID = info.PostBack.Mid
+	default:
+		e.ID = strconv.FormatInt(info.Sender.ID, 10) + "-" +
+			strconv.FormatInt(info.Timestamp, 10) + "-" +
+			strconv.Itoa(int(a))
+	}
+	return e
+}
+
+// runEvent triggers the handlers of an event within the EventHooks.
+func (m *Messenger) runEvent(ctx context.Context, a Action, info MessageInfo) {
+	hooks := m.eventHooks
+	if hooks.BeginEvent == nil && hooks.CommitEvent == nil && hooks.AbortEvent == nil {
+		m.runHandlers(ctx, a, info)
+		return
+	}
+
+	e := newEvent(a, info)
+	if hooks.BeginEvent != nil {
+		begun, err := hooks.BeginEvent(ctx, e)
+		if err == ErrEventProcessed {
+			return
+		} else if err != nil {
+			m.recordError(xerrors.Errorf("could not begin event %s: %w", e.ID, err))
+			return
+		}
+		if begun != nil {
+			ctx = begun
+		}
+	}
+
+	if hooks.AbortEvent != nil {
+		defer func() {
+			if reason := recover(); reason != nil {
+				hooks.AbortEvent(ctx, e, reason)
+				panic(reason)
+			}
+		}()
+	}
+
+	if err := m.runHandlers(ctx, a, info); err != nil {
+		// The abandoned handler may still be running, so its side effects
+		// cannot be committed.
+		if hooks.AbortEvent != nil {
+			hooks.AbortEvent(ctx, e, err)
+		}
+		return
+	}
+
+	if hooks.CommitEvent != nil {
+		if err := hooks.CommitEvent(ctx, e); err != nil {
+			m.recordError(xerrors.Errorf("could not commit event %s: %w", e.ID, err))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package messenger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestMessenger_DedupEventHooks(t *testing.T) {
+	store := NewMemoryStore()
+	m := New(Options{EventHooks: DedupEventHooks(store)})
+
+	var messages, deliveries int
+	m.HandleMessage(func(Message, *Response) { messages++ })
+	m.HandleDelivery(func(Delivery, *Response) { deliveries++ })
+
+	for i := 0; i < 2; i++ {
+		serveFixture(t, m, "message_text.json")
+		serveFixture(t, m, "delivery.json")
+	}
+	assert.Equal(t, 1, messages)
+	assert.Equal(t, 1, deliveries)
+
+	keys, err := store.Keys(eventPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"event:1254459154682919-1543095111999-1",
+		"event:m_AG5Hz2Uq7tuwNEhXfYYKj8mJEM_QPpz5jdCK48PnKAjSdjfipqxqMvK8ma6AC8fplwlqLP_5cgXIbu7I3rBN0P",
+	}, keys)
+}
+
+type txKey struct{}
+
+func TestMessenger_EventHooks(t *testing.T) {
+	var calls []string
+	m := New(Options{EventHooks: EventHooks{
+		BeginEvent: func(ctx context.Context, e Event) (context.Context, error) {
+			calls = append(calls, "begin "+e.ID)
+			if e.Action == PostBackAction {
+				return ctx, xerrors.New("database down")
+			}
+			return context.WithValue(ctx, txKey{}, "tx "+e.ID), nil
+		},
+		CommitEvent: func(ctx context.Context, e Event) error {
+			calls = append(calls, "commit "+ctx.Value(txKey{}).(string))
+			return nil
+		},
+		AbortEvent: func(ctx context.Context, e Event, reason interface{}) {
+			calls = append(calls, "abort")
+		},
+	}})
+	m.HandleMessage(func(_ Message, r *Response) {
+		calls = append(calls, "handler "+r.Context().Value(txKey{}).(string))
+	})
+	m.HandlePostBack(func(PostBack, *Response) {
+		calls = append(calls, "postback")
+	})
+
+	serveFixture(t, m, "message_text.json")
+	serveFixture(t, m, "postback.json")
+
+	assert.Equal(t, []string{
+		"begin m_AG5Hz2Uq7tuwNEhXfYYKj8mJEM_QPpz5jdCK48PnKAjSdjfipqxqMvK8ma6AC8fplwlqLP_5cgXIbu7I3rBN0P",
+		"handler tx m_AG5Hz2Uq7tuwNEhXfYYKj8mJEM_QPpz5jdCK48PnKAjSdjfipqxqMvK8ma6AC8fplwlqLP_5cgXIbu7I3rBN0P",
+		"commit tx m_AG5Hz2Uq7tuwNEhXfYYKj8mJEM_QPpz5jdCK48PnKAjSdjfipqxqMvK8ma6AC8fplwlqLP_5cgXIbu7I3rBN0P",
+		"begin 1254459154682919-1543095111999-3",
+	}, calls)
+	require.Len(t, m.RecentErrors(), 1)
+
+	calls = nil
+	m.HandleMessage(func(Message, *Response) { panic("boom") })
+	assert.Panics(t, func() { serveFixture(t, m, "message_text.json") })
+	assert.Equal(t, "abort", calls[len(calls)-1])
+}
+
+func TestMessenger_EventHooksTimeout(t *testing.T) {
+	var calls []string
+	var reason interface{}
+	m := New(Options{
+		HandlerTimeout: 10 * time.Millisecond,
+		EventHooks: EventHooks{
+			CommitEvent: func(ctx context.Context, e Event) error {
+				calls = append(calls, "commit")
+				return nil
+			},
+			AbortEvent: func(ctx context.Context, e Event, r interface{}) {
+				calls = append(calls, "abort")
+				reason = r
+			},
+		},
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+	m.HandleMessage(func(_ Message, r *Response) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	m.HandleDelivery(func(Delivery, *Response) {})
+
+	serveFixture(t, m, "message_text.json")
+	serveFixture(t, m, "delivery.json")
+
+	assert.Equal(t, []string{"abort", "commit"}, calls)
+	err, ok := reason.(error)
+	require.True(t, ok)
+	assert.True(t, xerrors.Is(err, ErrHandlerTimeout))
+}
@@ -32,13 +32,23 @@ func main() {
 	}
 
 	// Create a new messenger client
-	client := messenger.New(messenger.Options{
+	client := newBot(messenger.Options{
 		Verify:      *verify,
 		AppSecret:   *appSecret,
 		VerifyToken: *verifyToken,
 		Token:       *pageToken,
 	})
 
+	addr := fmt.Sprintf("%s:%d", *host, *port)
+	log.Println("Serving messenger bot on", addr)
+	log.Fatal(http.ListenAndServe(addr, client.Handler()))
+}
+
+// newBot creates the messenger client and sets up its handlers. Its Handler
+// can be served by any http.Server, or an httptest.Server in tests.
+func newBot(opts messenger.Options) *messenger.Messenger {
+	client := messenger.New(opts)
+
 	// Setup a handler to be triggered when a message is received
 	client.HandleMessage(func(m messenger.Message, r *messenger.Response) {
 		fmt.Printf("%v (Sent, %v)\n", m.Text, m.Time.Format(time.UnixDate))
@@ -61,7 +71,5 @@ func main() {
 		fmt.Println("Read at:", m.Watermark().Format(time.UnixDate))
 	})
 
-	addr := fmt.Sprintf("%s:%d", *host, *port)
-	log.Println("Serving messenger bot on", addr)
-	log.Fatal(http.ListenAndServe(addr, client.Handler()))
+	return client
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/paked/messenger"
+	"github.com/paked/messenger/messengertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot(t *testing.T) {
+	graph := messengertest.NewGraphServer()
+	defer graph.Close()
+	graph.Respond("GET", "/1254459154682919", `{"first_name":"Peter"}`)
+
+	client := newBot(messenger.Options{
+		Verify:     true,
+		AppSecret:  "secret",
+		HTTPClient: graph.Client(),
+	})
+	bot := httptest.NewServer(client.Handler())
+	defer bot.Close()
+
+	p, ok := messengertest.Find("messenger_message_text")
+	require.True(t, ok)
+	resp, err := p.Deliver(bot.URL, "secret")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, []string{`{"messaging_type":"RESPONSE","recipient":{"id":"1254459154682919"},"message":{"text":"Hello, Peter!"}}`}, graph.Sent())
+}
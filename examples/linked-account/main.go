@@ -49,6 +49,19 @@ func main() {
 		Token:       *pageToken,
 	})
 
+	// Setup router
+	mux := newServer(client)
+
+	// Listen
+	addr := fmt.Sprintf("%s:%d", *host, *port)
+	log.Println("Serving messenger bot on", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
+
+// newServer sets up the handlers of client, and routes the webhooks and the
+// login form. It can be served by any http.Server, or an httptest.Server in
+// tests.
+func newServer(client *messenger.Messenger) http.Handler {
 	// Handle incoming messages
 	client.HandleMessage(func(m messenger.Message, r *messenger.Response) {
 		log.Printf("%v (Sent, %v)\n", m.Text, m.Time.Format(time.UnixDate))
@@ -101,10 +114,7 @@ func main() {
 		}
 	})
 
-	// Listen
-	addr := fmt.Sprintf("%s:%d", *host, *port)
-	log.Println("Serving messenger bot on", addr)
-	log.Fatal(http.ListenAndServe(addr, mux))
+	return mux
 }
 
 // loginButton will present to the user a button that can be used to
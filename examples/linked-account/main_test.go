@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paked/messenger"
+	"github.com/paked/messenger/messengertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	graph := messengertest.NewGraphServer()
+	defer graph.Close()
+	graph.Respond("GET", "/1254459154682919", `{"first_name":"Peter"}`)
+
+	client := messenger.New(messenger.Options{HTTPClient: graph.Client()})
+	server := httptest.NewServer(newServer(client))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + loginPath + "?account_linking_token=abc&redirect_uri=https://example.com")
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), `value="abc"`)
+
+	p, ok := messengertest.Find("messenger_message_text")
+	require.True(t, ok)
+	p.Body = []byte(strings.Replace(string(p.Body), "hello, world!", "login", 1))
+	resp, err = p.Deliver(server.URL+webhooksPath, "")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	sent := graph.Sent()
+	require.Len(t, sent, 1)
+	var message messenger.SendStructuredMessage
+	require.NoError(t, json.Unmarshal([]byte(sent[0]), &message))
+	assert.Equal(t, "Link your account.", message.Message.Attachment.Payload.Text)
+}
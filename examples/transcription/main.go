@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/paked/messenger"
+)
+
+var (
+	verifyToken = flag.String("verify-token", "mad-skrilla", "The token used to verify facebook (required)")
+	verify      = flag.Bool("should-verify", false, "Whether or not the app should verify itself")
+	pageToken   = flag.String("page-token", "not skrilla", "The token that is used to verify the page on facebook")
+	appSecret   = flag.String("app-secret", "", "The app secret from the facebook developer portal (required)")
+	sttURL      = flag.String("stt-url", "http://localhost:9000/transcribe", "The speech-to-text service used to transcribe voice notes")
+	host        = flag.String("host", "localhost", "The host used to serve the messenger bot")
+	port        = flag.Int("port", 8080, "The port used to serve the messenger bot")
+)
+
+// transcribe asks a speech-to-text service to transcribe the audio found at
+// the attachment's URL. The service is expected to reply with {"text": "..."}.
+func transcribe(a messenger.Attachment) (string, error) {
+	resp, err := http.Get(*sttURL + "?url=" + url.QueryEscape(a.Payload.URL))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	return result.Text, nil
+}
+
+func main() {
+	flag.Parse()
+
+	if *verifyToken == "" || *appSecret == "" || *pageToken == "" {
+		fmt.Println("missing arguments")
+		fmt.Println()
+		flag.Usage()
+
+		os.Exit(-1)
+	}
+
+	client := messenger.New(messenger.Options{
+		Verify:      *verify,
+		AppSecret:   *appSecret,
+		VerifyToken: *verifyToken,
+		Token:       *pageToken,
+		Transcriber: messenger.TranscriberFunc(transcribe),
+	})
+
+	// Voice notes are transcribed before this handler is triggered
+	client.HandleMessage(func(m messenger.Message, r *messenger.Response) {
+		if m.TranscribedText == "" {
+			return
+		}
+
+		r.Text(fmt.Sprintf("You said: %v", m.TranscribedText), messenger.ResponseType)
+	})
+
+	addr := fmt.Sprintf("%s:%d", *host, *port)
+	log.Println("Serving messenger bot on", addr)
+	log.Fatal(http.ListenAndServe(addr, client.Handler()))
+}
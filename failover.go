@@ -0,0 +1,116 @@
+package messenger
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultGraphHost is the host of the Graph API used by the endpoint
+// constants.
+const DefaultGraphHost = "graph.facebook.com"
+
+// graphHostCooldown is how long a Graph host which failed is avoided for.
+const graphHostCooldown = 30 * time.Second
+
+// failoverTransport sends Graph API requests to the first healthy host of a
+// list, failing over to the next one on network errors. A host which failed
+// is considered unhealthy until graphHostCooldown has passed.
+type failoverTransport struct {
+	hosts []string
+	base  http.RoundTripper
+	now   func() time.Time
+
+	mu        sync.Mutex
+	downUntil map[string]time.Time
+}
+
+func newFailoverTransport(hosts []string, base http.RoundTripper, now func() time.Time) *failoverTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &failoverTransport{
+		hosts:     hosts,
+		base:      base,
+		now:       now,
+		downUntil: make(map[string]time.Time),
+	}
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != DefaultGraphHost {
+		return t.base.RoundTrip(req)
+	}
+
+	var lastErr error
+	for _, host := range t.candidates() {
+		attempt := req.Clone(req.Context())
+		attempt.URL.Host = host
+		attempt.Host = host
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attempt.Body = body
+		} else if lastErr != nil {
+			// the body has already been consumed
+			break
+		}
+
+		resp, err := t.base.RoundTrip(attempt)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		t.markDown(host)
+
+		if req.Context().Err() != nil {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// candidates returns the healthy hosts in order of preference, followed by
+// the unhealthy ones as a last resort.
+func (t *failoverTransport) candidates() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	healthy := make([]string, 0, len(t.hosts))
+	var unhealthy []string
+	for _, host := range t.hosts {
+		if now.Before(t.downUntil[host]) {
+			unhealthy = append(unhealthy, host)
+			continue
+		}
+		healthy = append(healthy, host)
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+func (t *failoverTransport) markDown(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.downUntil[host] = t.now().Add(graphHostCooldown)
+}
+
+// withFailover returns a copy of client which fails over between the Graph
+// hosts set in Options, or client itself if there are none.
+func (m *Messenger) withFailover(client *http.Client) *http.Client {
+	if len(m.graphHosts) == 0 {
+		return client
+	}
+
+	c := *client
+	c.Transport = newFailoverTransport(m.graphHosts, client.Transport, m.now)
+	return &c
+}
@@ -0,0 +1,53 @@
+package messenger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+type hostRoundTripper struct {
+	down  map[string]bool
+	hosts []string
+}
+
+func (h *hostRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.hosts = append(h.hosts, req.URL.Host)
+	if h.down[req.URL.Host] {
+		return nil, xerrors.New("connection refused")
+	}
+	return newFakeGraph(`{}`).RoundTrip(req)
+}
+
+func TestMessenger_GraphHostFailover(t *testing.T) {
+	rt := &hostRoundTripper{down: map[string]bool{"graph-a.example.com": true}}
+	clock := newFakeClock()
+	m := New(Options{
+		HTTPClient: &http.Client{Transport: rt},
+		GraphHosts: []string{"graph-a.example.com", "graph-b.example.com"},
+		Clock:      clock,
+	})
+
+	require.NoError(t, m.Response(111).Text("first", ResponseType))
+	assert.Equal(t, []string{"graph-a.example.com", "graph-b.example.com"}, rt.hosts)
+
+	// the failed host is avoided until it cools down
+	rt.hosts = nil
+	require.NoError(t, m.Response(111).Text("second", ResponseType))
+	assert.Equal(t, []string{"graph-b.example.com"}, rt.hosts)
+
+	// and retried once it has
+	clock.Advance(graphHostCooldown)
+	rt.down["graph-a.example.com"] = false
+	rt.hosts = nil
+	require.NoError(t, m.Response(111).Text("again", ResponseType))
+	assert.Equal(t, []string{"graph-a.example.com"}, rt.hosts)
+
+	// everything down
+	rt.down["graph-a.example.com"] = true
+	rt.down["graph-b.example.com"] = true
+	assert.Error(t, m.Response(111).Text("third", ResponseType))
+}
@@ -0,0 +1,53 @@
+package messenger
+
+import (
+	"sync/atomic"
+
+	"golang.org/x/xerrors"
+)
+
+// HandleFallback adds a MessageHandler which is triggered when none of the
+// message handlers replied to a message, for instance to answer "Sorry, I
+// didn't get that". Fallback handlers run in the order they were added
+// until one of them replies.
+func (m *Messenger) HandleFallback(f MessageHandler) {
+	m.fallbackHandlers = append(m.fallbackHandlers, f)
+}
+
+// trackReplies makes r, and the copies made of it, count the messages they
+// send.
+func (r *Response) trackReplies() {
+	r.replies = new(int32)
+}
+
+// replied reports whether a message was sent since trackReplies was called.
+func (r *Response) replied() bool {
+	return r.replies != nil && atomic.LoadInt32(r.replies) > 0
+}
+
+// noteSent counts msg as a reply if it was sent, or queued to be sent.
+func (r *Response) noteSent(msg interface{}, err error) {
+	if r.replies == nil || isSenderAction(msg) {
+		return
+	}
+	if err == nil || xerrors.Is(err, ErrQueued) {
+		atomic.AddInt32(r.replies, 1)
+	}
+}
+
+// fallback runs the fallback handlers for message unless resp replied to it.
+// It returns the error of the first handler which was abandoned, see invoke.
+func (m *Messenger) fallback(message Message, resp *Response) error {
+	var abandoned error
+	for _, f := range m.fallbackHandlers {
+		if resp.replied() {
+			break
+		}
+
+		f := f
+		if err := m.invoke(TextAction, resp, func(r *Response) { f(message, r) }); abandoned == nil {
+			abandoned = err
+		}
+	}
+	return abandoned
+}
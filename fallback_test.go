@@ -0,0 +1,54 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_HandleFallback(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	m.HandleMessage(func(msg Message, r *Response) {
+		if msg.Text == "ping" {
+			r.Text("pong", ResponseType)
+		}
+	})
+	m.HandleMessage(func(msg Message, r *Response) {
+		// Sender actions are not replies.
+		r.SenderAction("mark_seen")
+	})
+
+	var calls []string
+	m.HandleFallback(func(msg Message, r *Response) {
+		calls = append(calls, "silent")
+	})
+	m.HandleFallback(func(msg Message, r *Response) {
+		calls = append(calls, "sorry")
+		r.WithPersona("42").Text("Sorry, I didn't get that.", ResponseType)
+	})
+	m.HandleFallback(func(msg Message, r *Response) {
+		calls = append(calls, "unreachable")
+	})
+
+	serveFixture(t, m, "message_text.json")
+
+	assert.Equal(t, []string{"silent", "sorry"}, calls)
+	assert.Equal(t, []string{"Sorry, I didn't get that."}, sentTexts(t, graph))
+}
+
+func TestMessenger_HandleFallbackReplied(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	m.HandleMessage(func(msg Message, r *Response) {
+		r.Text("hi", ResponseType)
+	})
+	m.HandleFallback(func(msg Message, r *Response) {
+		t.Error("fallback called although a handler replied")
+	})
+
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, []string{"hi"}, sentTexts(t, graph))
+}
@@ -0,0 +1,200 @@
+package messenger
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// localeFormat are the conventions of a locale for dates, times and amounts.
+type localeFormat struct {
+	date, time     string
+	decimal, group string
+	// symbolAfter puts currency symbols after amounts, as in "12,50 €".
+	symbolAfter bool
+}
+
+// localeFormats are the conventions of the supported locales. Missing
+// locales use the first supported locale of their language in lexical
+// order, and default to en_US.
+var localeFormats = map[string]localeFormat{
+	"en_US": {"01/02/2006", "3:04 PM", ".", ",", false},
+	"en_GB": {"02/01/2006", "15:04", ".", ",", false},
+	"fr_FR": {"02/01/2006", "15:04", ",", " ", true},
+	"de_DE": {"02.01.2006", "15:04", ",", ".", true},
+	"es_ES": {"02/01/2006", "15:04", ",", ".", true},
+	"it_IT": {"02/01/2006", "15:04", ",", ".", true},
+	"nl_NL": {"02-01-2006", "15:04", ",", ".", false},
+	"pt_BR": {"02/01/2006", "15:04", ",", ".", false},
+	"ja_JP": {"2006/01/02", "15:04", ".", ",", false},
+}
+
+// currencySymbols are the symbols of common ISO 4217 currencies. Others are
+// shown with their code.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"BRL": "R$",
+	"INR": "₹",
+}
+
+// zeroDecimalCurrencies are the currencies without minor units.
+var zeroDecimalCurrencies = map[string]bool{"JPY": true, "KRW": true, "VND": true}
+
+// Formatter formats times, dates and amounts in the locale and timezone of a
+// user, for receipts, reminders and flight templates. The zero Formatter
+// formats in en_US and UTC.
+type Formatter struct {
+	// Locale is the locale of the user, such as "fr_FR".
+	Locale string
+	// Location is the timezone of the user. Defaults to UTC.
+	Location *time.Location
+}
+
+// formatOf returns the conventions of locale.
+func formatOf(locale string) localeFormat {
+	if format, ok := localeFormats[locale]; ok {
+		return format
+	}
+
+	language := strings.SplitN(locale, "_", 2)[0] + "_"
+	var names []string
+	for name := range localeFormats {
+		if strings.HasPrefix(name, language) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		return localeFormats[names[0]]
+	}
+	return localeFormats["en_US"]
+}
+
+// NewFormatter creates a Formatter for locale, such as "fr_FR", and a
+// timezone given as an offset from UTC in hours, as in Profile.
+func NewFormatter(locale string, timezone float64) Formatter {
+	offset := int(math.Round(timezone * 3600))
+	name := "UTC"
+	if offset != 0 {
+		sign, abs := "+", offset
+		if offset < 0 {
+			sign, abs = "-", -offset
+		}
+		name = fmt.Sprintf("UTC%s%02d:%02d", sign, abs/3600, abs%3600/60)
+	}
+
+	return Formatter{
+		Locale:   locale,
+		Location: time.FixedZone(name, offset),
+	}
+}
+
+func (f Formatter) location() *time.Location {
+	if f.Location == nil {
+		return time.UTC
+	}
+	return f.Location
+}
+
+// Date formats the date of t, such as "11/24/2018" in en_US.
+func (f Formatter) Date(t time.Time) string {
+	return t.In(f.location()).Format(formatOf(f.Locale).date)
+}
+
+// Time formats the time of t, such as "9:31 PM" in en_US.
+func (f Formatter) Time(t time.Time) string {
+	return t.In(f.location()).Format(formatOf(f.Locale).time)
+}
+
+// DateTime formats the date and time of t, such as "11/24/2018 9:31 PM" in
+// en_US.
+func (f Formatter) DateTime(t time.Time) string {
+	return f.Date(t) + " " + f.Time(t)
+}
+
+// Amount formats amount in currency, an ISO 4217 code, such as "$1,234.50"
+// in en_US or "1.234,50 €" in de_DE.
+func (f Formatter) Amount(amount float64, currency string) string {
+	format := formatOf(f.Locale)
+	decimals := 2
+	if zeroDecimalCurrencies[currency] {
+		decimals = 0
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := strconv.FormatFloat(amount, 'f', decimals, 64)
+	integer, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		integer, fraction = digits[:i], format.decimal+digits[i+1:]
+	}
+
+	var grouped strings.Builder
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(format.group)
+		}
+		grouped.WriteRune(c)
+	}
+	number := grouped.String() + fraction
+
+	symbol, ok := currencySymbols[currency]
+	switch {
+	case !ok:
+		return sign + number + " " + currency
+	case format.symbolAfter:
+		return sign + number + " " + symbol
+	default:
+		return sign + symbol + number
+	}
+}
+
+// formatterCache remembers the Formatter of users.
+type formatterCache struct {
+	mu         sync.Mutex
+	formatters map[int64]Formatter
+}
+
+// FormatterFor returns the Formatter of the user psid, from the locale and
+// timezone of their profile, which requires the permission to read them.
+// Formatters are cached for the lifetime of the Messenger.
+func (m *Messenger) FormatterFor(psid int64) (Formatter, error) {
+	m.formatters.mu.Lock()
+	f, ok := m.formatters.formatters[psid]
+	m.formatters.mu.Unlock()
+	if ok {
+		return f, nil
+	}
+
+	p, err := m.ProfileByID(psid, []string{"locale", "timezone"})
+	if err != nil {
+		return Formatter{}, err
+	}
+	f = NewFormatter(p.Locale, p.Timezone)
+
+	m.formatters.mu.Lock()
+	defer m.formatters.mu.Unlock()
+	if m.formatters.formatters == nil {
+		m.formatters.formatters = make(map[int64]Formatter)
+	}
+	m.formatters.formatters[psid] = f
+	return f, nil
+}
+
+// Formatter returns the Formatter of the recipient of r, see
+// Messenger.FormatterFor.
+func (r *Response) Formatter() (Formatter, error) {
+	if r.messenger == nil {
+		return Formatter{}, ErrNoMessenger
+	}
+	return r.messenger.FormatterFor(r.to.ID)
+}
@@ -0,0 +1,61 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatter(t *testing.T) {
+	at := newFakeClock().Now()
+
+	us := NewFormatter("en_US", -8)
+	assert.Equal(t, "11/24/2018", us.Date(at))
+	assert.Equal(t, "1:31 PM", us.Time(at))
+	assert.Equal(t, "11/24/2018 1:31 PM", us.DateTime(at))
+	assert.Equal(t, "$1,234,567.50", us.Amount(1234567.5, "USD"))
+	assert.Equal(t, "-$3.00", us.Amount(-3, "USD"))
+	assert.Equal(t, "¥1,200", us.Amount(1200, "JPY"))
+	assert.Equal(t, "12.00 CHF", us.Amount(12, "CHF"))
+
+	de := NewFormatter("de_DE", 1)
+	assert.Equal(t, "24.11.2018 22:31", de.DateTime(at))
+	assert.Equal(t, "1.234,50 €", de.Amount(1234.5, "EUR"))
+
+	// Locales are looked up by language, and the timezone may be fractional.
+	in := NewFormatter("de_AT", 5.5)
+	assert.Equal(t, "25.11.2018 03:01", in.DateTime(at))
+	assert.Equal(t, "UTC+05:30", in.Location.String())
+
+	assert.Equal(t, "24/11/2018", NewFormatter("en_AU", 0).Date(at))
+
+	unknown := NewFormatter("xx_XX", 0)
+	assert.Equal(t, "11/24/2018 9:31 PM", unknown.DateTime(at))
+	assert.Equal(t, "UTC", unknown.Location.String())
+
+	// Formatters built by hand resolve their locale, and default to UTC.
+	fr := Formatter{Locale: "fr_FR"}
+	assert.Equal(t, "24/11/2018 21:31", fr.DateTime(at))
+	assert.Equal(t, "12,50 €", fr.Amount(12.5, "EUR"))
+	assert.Equal(t, "11/24/2018 9:31 PM", Formatter{}.DateTime(at))
+}
+
+func TestMessenger_FormatterFor(t *testing.T) {
+	graph := newFakeGraph(`{"locale":"fr_FR","timezone":1}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	f, err := m.Response(fixturePSID).Formatter()
+	require.NoError(t, err)
+	assert.Equal(t, "fr_FR", f.Locale)
+	assert.Equal(t, "24/11/2018 22:31", f.DateTime(newFakeClock().Now()))
+	require.Equal(t, 1, graph.count())
+	assert.Equal(t, "locale,timezone", graph.requests[0].URL.Query().Get("fields"))
+
+	_, err = m.FormatterFor(fixturePSID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, graph.count())
+
+	_, err = NewResponse(ResponseOptions{}).Formatter()
+	assert.Equal(t, ErrNoMessenger, err)
+}
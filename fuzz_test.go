@@ -0,0 +1,52 @@
+//go:build go1.18
+// +build go1.18
+
+package messenger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// FuzzHandle makes sure a malformed webhook can never crash a bot. It is
+// seeded with the payloads found in testdata/webhooks.
+func FuzzHandle(f *testing.F) {
+	seeds, err := filepath.Glob(filepath.Join("testdata", "webhooks", "*.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, seed := range seeds {
+		data, err := ioutil.ReadFile(seed)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		m := New(Options{})
+		m.HandleMessage(func(msg Message, r *Response) {
+			msg.GetNLP(&map[string]interface{}{})
+		})
+		m.HandleDelivery(func(d Delivery, r *Response) {
+			d.Watermark()
+		})
+		m.HandleRead(func(rd Read, r *Response) {
+			rd.Watermark()
+		})
+		m.HandlePostBack(func(p PostBack, r *Response) {
+			p.Referral.IsFromAd()
+		})
+		m.HandleOptIn(func(OptIn, *Response) {})
+		m.HandleReferral(func(ref ReferralMessage, r *Response) {
+			ref.IsFromShortlink()
+		})
+		m.HandleAccountLinking(func(AccountLinking, *Response) {})
+
+		req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+		m.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	})
+}
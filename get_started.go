@@ -0,0 +1,31 @@
+package messenger
+
+// GetStartedPayload is the payload of the postback sent when a user taps the
+// Get Started button set up by SetGetStarted.
+const GetStartedPayload = "GET_STARTED"
+
+// SetGetStarted displays the Get Started button on the welcome screen of the
+// page, which sends a postback with GetStartedPayload when tapped.
+func (m *Messenger) SetGetStarted() error {
+	return m.setMessengerProfile(map[string]interface{}{
+		"get_started": map[string]string{
+			"payload": GetStartedPayload,
+		},
+	})
+}
+
+// IsGetStarted reports whether the postback was sent by the Get Started
+// button.
+func (p PostBack) IsGetStarted() bool {
+	return p.Payload == GetStartedPayload
+}
+
+// HandleGetStarted adds a new PostBackHandler to the Messenger which will
+// only be triggered when a user taps the Get Started button.
+func (m *Messenger) HandleGetStarted(f PostBackHandler) {
+	m.HandlePostBack(func(p PostBack, r *Response) {
+		if p.IsGetStarted() {
+			f(p, r)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Error is a failed call to the Graph API, with the details Facebook
+// support asks for.
+type Error struct {
+	// Endpoint is the URL called, without its query.
+	Endpoint string
+	// Recipient is the ID of the user the call was about, anonymized with
+	// Options.PSIDHashKey of the Messenger. Empty if the call was not about a
+	// user identified by their ID.
+	Recipient string
+	// PayloadSize is the size of the request body in bytes.
+	PayloadSize int
+	// StatusCode is the HTTP status of the response, 0 if there was none.
+	StatusCode int
+	// FBTraceID identifies the call for Facebook support.
+	FBTraceID string
+	// Err is why the call failed.
+	Err error
+}
+
+func (e *Error) Error() string {
+	details := []string{fmt.Sprintf("payload %d bytes", e.PayloadSize)}
+	if e.StatusCode != 0 {
+		details = append(details, fmt.Sprintf("status %d", e.StatusCode))
+	}
+	if e.FBTraceID != "" {
+		details = append(details, "fbtrace_id "+e.FBTraceID)
+	}
+	if e.Recipient != "" {
+		details = append(details, "recipient "+e.Recipient)
+	}
+
+	return fmt.Sprintf("graph call to %s failed (%s): %v", e.Endpoint, strings.Join(details, ", "), e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// QueryResponse is the response sent back by Facebook when setting up things
+// like greetings or call-to-actions
+type QueryResponse struct {
+	Error  *QueryError `json:"error,omitempty"`
+	Result string      `json:"result,omitempty"`
+}
+
+// QueryError is representing an error sent back by Facebook
+type QueryError struct {
+	Message      string `json:"message"`
+	Type         string `json:"type"`
+	Code         int    `json:"code"`
+	ErrorSubcode int    `json:"error_subcode"`
+	FBTraceID    string `json:"fbtrace_id"`
+}
+
+// QueryError implements error
+func (e QueryError) Error() string {
+	return e.Message
+}
+
+// DecodeError decodes the answer of Facebook read from r, returning the
+// error it reports, if any.
+func DecodeError(r io.Reader) error {
+	qr := QueryResponse{}
+	if err := json.NewDecoder(r).Decode(&qr); err != nil {
+		return xerrors.Errorf("json unmarshal error: %w", err)
+	}
+	if qr.Error != nil {
+		return xerrors.Errorf("facebook error: %w", qr.Error)
+	}
+
+	return nil
+}
+
+// failed wraps err, the failure of the call req which was answered with
+// resp, into an Error. It returns nil if err is nil.
+func failed(req *http.Request, size int, resp *http.Response, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	endpoint := *req.URL
+	endpoint.RawQuery = ""
+	e := &Error{
+		Endpoint:    endpoint.String(),
+		PayloadSize: size,
+		Err:         err,
+	}
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+		e.FBTraceID = resp.Header.Get("X-FB-Trace-ID")
+	}
+	var qe *QueryError
+	if xerrors.As(err, &qe) && qe.FBTraceID != "" {
+		e.FBTraceID = qe.FBTraceID
+	}
+	return e
+}
@@ -0,0 +1,158 @@
+// Package graph is a client of the Facebook Graph API, taking care of the
+// access token, of signing calls and of decoding the errors of Facebook. It
+// is used by all the calls the messenger package makes, and lets bots call
+// the endpoints it does not cover yet the same way.
+package graph
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultURL is the base URL of the Graph API.
+const DefaultURL = "https://graph.facebook.com"
+
+// ErrServiceUnavailable is returned when Facebook answers with a server
+// error, meaning the call may succeed if retried later.
+var ErrServiceUnavailable = xerrors.New("facebook is unavailable")
+
+// Client makes calls to the Graph API. The zero value makes calls to
+// DefaultURL with http.DefaultClient and no access token.
+type Client struct {
+	// HTTPClient is used for the calls. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Token is the access token sent with every call.
+	Token string
+	// AppSecret, if set, signs every call with an appsecret_proof, as
+	// required by apps with "Require App Secret" enabled.
+	AppSecret string
+	// URL is the base URL of the calls. Defaults to DefaultURL.
+	URL string
+}
+
+// RawBody is the body of a call which is sent as is.
+type RawBody struct {
+	ContentType string
+	Data        []byte
+}
+
+// Do makes a call to path, such as "/v2.6/me/messages", or to an absolute
+// URL, with params added to its query.
+//
+// A nil body sends nothing, a RawBody is sent as is, url.Values are sent as
+// a form and anything else is sent as JSON. The answer is decoded into out
+// unless it is nil, or stored as is into a *[]byte. Failed calls return a *Error, wrapping the *QueryError
+// sent by Facebook if any, or ErrServiceUnavailable on server errors.
+func (c *Client) Do(ctx context.Context, method, path string, params url.Values, body, out interface{}) error {
+	endpoint := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		base := c.URL
+		if base == "" {
+			base = DefaultURL
+		}
+		endpoint = strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	for k, v := range params {
+		q[k] = v
+	}
+
+	var (
+		data        []byte
+		contentType string
+	)
+	switch b := body.(type) {
+	case nil:
+	case RawBody:
+		data, contentType = b.Data, b.ContentType
+	case url.Values:
+		form := url.Values{}
+		for k, v := range b {
+			form[k] = v
+		}
+		c.authenticate(form)
+		data, contentType = []byte(form.Encode()), "application/x-www-form-urlencoded"
+	case json.RawMessage:
+		data, contentType = b, "application/json"
+	default:
+		data, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+	if _, ok := body.(url.Values); !ok {
+		c.authenticate(q)
+	}
+	u.RawQuery = q.Encode()
+
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), r)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return failed(req, len(data), nil, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return failed(req, len(data), resp, xerrors.Errorf("status %d: %w", resp.StatusCode, ErrServiceUnavailable))
+	case resp.StatusCode != http.StatusOK || out == nil:
+		return failed(req, len(data), resp, DecodeError(resp.Body))
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// authenticate adds the access token and its proof to v.
+func (c *Client) authenticate(v url.Values) {
+	if c.Token == "" {
+		return
+	}
+
+	v.Set("access_token", c.Token)
+	if c.AppSecret != "" {
+		v.Set("appsecret_proof", Proof(c.Token, c.AppSecret))
+	}
+}
+
+// Proof returns the appsecret_proof of token, signed with the secret of the
+// app.
+func Proof(token, appSecret string) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+type call struct {
+	method, path, contentType, body string
+	query                           url.Values
+}
+
+// newServer starts a fake Graph API answering every call with status and
+// response, and recording the calls.
+func newServer(t *testing.T, status int, response string) (*httptest.Server, *[]call) {
+	var calls []call
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		calls = append(calls, call{r.Method, r.URL.Path, r.Header.Get("Content-Type"), string(body), r.URL.Query()})
+		w.Header().Set("X-FB-Trace-ID", "header-trace")
+		w.WriteHeader(status)
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(s.Close)
+	return s, &calls
+}
+
+func TestClient_Do(t *testing.T) {
+	s, calls := newServer(t, http.StatusOK, `{"recipient_id":"1","message_id":"m1"}`)
+	c := &Client{Token: "token", AppSecret: "secret", URL: s.URL}
+
+	var out struct {
+		MessageID string `json:"message_id"`
+	}
+	err := c.Do(context.Background(), "POST", "/v2.11/me/messages", url.Values{"fields": {"id"}}, map[string]string{"text": "hi"}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "m1", out.MessageID)
+
+	require.Len(t, *calls, 1)
+	got := (*calls)[0]
+	assert.Equal(t, "POST", got.method)
+	assert.Equal(t, "/v2.11/me/messages", got.path)
+	assert.Equal(t, "application/json", got.contentType)
+	assert.JSONEq(t, `{"text":"hi"}`, got.body)
+	assert.Equal(t, "id", got.query.Get("fields"))
+	assert.Equal(t, "token", got.query.Get("access_token"))
+	assert.Equal(t, Proof("token", "secret"), got.query.Get("appsecret_proof"))
+}
+
+func TestClient_DoBodies(t *testing.T) {
+	s, calls := newServer(t, http.StatusOK, `[]`)
+	c := &Client{Token: "token"}
+	ctx := context.Background()
+
+	var raw []byte
+	require.NoError(t, c.Do(ctx, "POST", s.URL+"/v2.6/", nil, url.Values{"batch": {"[]"}}, &raw))
+	require.NoError(t, c.Do(ctx, "POST", s.URL+"/upload", nil, RawBody{ContentType: "text/plain", Data: []byte("data")}, &raw))
+	assert.Equal(t, "[]", string(raw))
+
+	require.Len(t, *calls, 2)
+	assert.Equal(t, "application/x-www-form-urlencoded", (*calls)[0].contentType)
+	form, err := url.ParseQuery((*calls)[0].body)
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{"batch": {"[]"}, "access_token": {"token"}}, form)
+	assert.Empty(t, (*calls)[0].query.Get("access_token"))
+
+	assert.Equal(t, "text/plain", (*calls)[1].contentType)
+	assert.Equal(t, "data", (*calls)[1].body)
+	assert.Equal(t, "token", (*calls)[1].query.Get("access_token"))
+}
+
+func TestClient_DoErrors(t *testing.T) {
+	s, _ := newServer(t, http.StatusBadRequest, `{"error":{"message":"(#100) No matching user found","code":100,"fbtrace_id":"AbCdEf"}}`)
+	c := &Client{URL: s.URL}
+
+	err := c.Do(context.Background(), "GET", "/v2.6/1", nil, nil, nil)
+	var e *Error
+	require.True(t, xerrors.As(err, &e))
+	assert.Equal(t, s.URL+"/v2.6/1", e.Endpoint)
+	assert.Equal(t, http.StatusBadRequest, e.StatusCode)
+	assert.Equal(t, "AbCdEf", e.FBTraceID)
+	var qe *QueryError
+	require.True(t, xerrors.As(err, &qe))
+	assert.Equal(t, 100, qe.Code)
+
+	s, _ = newServer(t, http.StatusServiceUnavailable, `oops`)
+	c = &Client{URL: s.URL}
+	err = c.Do(context.Background(), "GET", "/v2.6/1", nil, nil, nil)
+	assert.True(t, xerrors.Is(err, ErrServiceUnavailable))
+	require.True(t, xerrors.As(err, &e))
+	assert.Equal(t, "header-trace", e.FBTraceID)
+}
@@ -0,0 +1,38 @@
+package messenger
+
+import (
+	"context"
+
+	"github.com/paked/messenger/graph"
+	"golang.org/x/xerrors"
+)
+
+// GraphError is a failed call to the Graph API, with the details Facebook
+// support asks for.
+type GraphError = graph.Error
+
+// graphFailed logs err, the failure of a Graph API call about the user
+// whose anonymized ID is recipient, and returns it. It returns nil if err is
+// nil.
+func graphFailed(ctx context.Context, recipient string, err error) error {
+	var ge *GraphError
+	if xerrors.As(err, &ge) {
+		ge.Recipient = recipient
+		logEvent(ctx, ge)
+	}
+	return err
+}
+
+// graphFailed is graphFailed for a call about the recipient of r.
+func (r *Response) graphFailed(err error) error {
+	var recipient string
+	if r.to.ID != 0 {
+		var h *PSIDHasher
+		if r.messenger != nil {
+			h = r.messenger.psidHasher
+		}
+		recipient = h.Hash(r.to.ID)
+	}
+
+	return graphFailed(r.Context(), recipient, err)
+}
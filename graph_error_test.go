@@ -0,0 +1,39 @@
+package messenger
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestGraphError(t *testing.T) {
+	graph := newFakeGraph(`{"error":{"message":"(#100) No matching user found","type":"OAuthException","code":100,"error_subcode":2018001,"fbtrace_id":"AbCdEf"}}`)
+	graph.status = http.StatusBadRequest
+	m := New(Options{HTTPClient: graph.client(), PSIDHashKey: []byte("key")})
+
+	err := m.Response(fixturePSID).Text("hello", ResponseType)
+	require.Error(t, err)
+
+	var ge *GraphError
+	require.True(t, xerrors.As(err, &ge))
+	assert.Equal(t, "https://graph.facebook.com/v2.11/me/messages", ge.Endpoint)
+	assert.Equal(t, m.psidHasher.Hash(fixturePSID), ge.Recipient)
+	assert.Equal(t, len(graph.bodies[0]), ge.PayloadSize)
+	assert.Equal(t, http.StatusBadRequest, ge.StatusCode)
+	assert.Equal(t, "AbCdEf", ge.FBTraceID)
+	assert.NotContains(t, err.Error(), "1254459154682919")
+
+	var qe *QueryError
+	require.True(t, xerrors.As(err, &qe))
+	assert.Equal(t, 100, qe.Code)
+
+	// Failures of calls about no particular user are reported the same way.
+	err = m.GreetingSetting("Hello")
+	require.True(t, xerrors.As(err, &ge))
+	assert.Empty(t, ge.Recipient)
+	assert.True(t, strings.HasPrefix(err.Error(), "graph call to https://graph.facebook.com/v2.6/me/thread_settings failed (payload "), err.Error())
+}
@@ -0,0 +1,136 @@
+package messenger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HandlerTable describes how the Messenger dispatches webhook events, to
+// find out why a handler never fires.
+type HandlerTable struct {
+	// Routes are the HTTP routes the Messenger registered.
+	Routes []Route
+	// Stages are the steps events go through before reaching their
+	// handlers, in order. Each of them can stop an event.
+	Stages []string
+	// Handlers is the number of handlers registered per event type, as
+	// returned by HandlerCounts.
+	Handlers map[string]int
+	// Commands are the keyword commands of the routers added with
+	// ListCommands, such as "/help".
+	Commands []string
+}
+
+// CommandLister is implemented by the routers of keyword commands, such as
+// commands.Router, so that their commands are listed in the HandlerTable.
+type CommandLister interface {
+	// Commands returns the commands of the router.
+	Commands() []string
+}
+
+// ListCommands adds the commands of a router to the HandlerTable. The
+// router still has to be registered with HandleMessage to receive messages.
+func (m *Messenger) ListCommands(l CommandLister) {
+	m.commands = append(m.commands, l)
+}
+
+// debugLog logs the HandlerTable once, when Options.Debug is set.
+type debugLog struct {
+	enabled bool
+	once    sync.Once
+}
+
+// Handlers returns the current HandlerTable of the Messenger. Stages
+// which can be turned on and off while the Messenger runs, such as the
+// maintenance mode, are only listed while they are on.
+func (m *Messenger) Handlers() HandlerTable {
+	var commands []string
+	for _, l := range m.commands {
+		commands = append(commands, l.Commands()...)
+	}
+
+	return HandlerTable{
+		Routes:   m.Routes(),
+		Stages:   m.stages(),
+		Handlers: m.HandlerCounts(),
+		Commands: commands,
+	}
+}
+
+// stages lists the enabled steps of dispatchContext and runHandlers.
+func (m *Messenger) stages() []string {
+	var stages []string
+	add := func(enabled bool, name string) {
+		if enabled {
+			stages = append(stages, name)
+		}
+	}
+
+	eventSteps, messageSteps := m.steps()
+	add(m.maxEventAge > 0, "stale events")
+	for _, step := range eventSteps {
+		if step.name != "" {
+			add(step.enabled(), step.name)
+		}
+	}
+	add(m.eventHooks.BeginEvent != nil, "event hooks")
+	for _, step := range messageSteps {
+		add(step.enabled(), step.name)
+	}
+	add(m.handlerTimeout > 0, "handler timeout")
+	return stages
+}
+
+// String formats the table for logs.
+func (t HandlerTable) String() string {
+	var b strings.Builder
+
+	b.WriteString("routes:\n")
+	for _, r := range t.Routes {
+		fmt.Fprintf(&b, "  %s %s\n", strings.Join(r.Methods, ","), r.Path)
+	}
+
+	b.WriteString("stages:\n")
+	if len(t.Stages) == 0 {
+		b.WriteString("  none\n")
+	}
+	for i, s := range t.Stages {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, s)
+	}
+
+	events := make([]string, 0, len(t.Handlers))
+	for e := range t.Handlers {
+		events = append(events, e)
+	}
+	sort.Strings(events)
+
+	b.WriteString("handlers:\n")
+	for _, e := range events {
+		note := ""
+		if t.Handlers[e] == 0 {
+			note = " (events are dropped)"
+		}
+		fmt.Fprintf(&b, "  %s: %d%s\n", e, t.Handlers[e], note)
+	}
+
+	if len(t.Commands) > 0 {
+		b.WriteString("commands:\n")
+		for _, c := range t.Commands {
+			fmt.Fprintf(&b, "  %s\n", c)
+		}
+	}
+	return b.String()
+}
+
+// logHandlers logs the HandlerTable the first time the Messenger serves a
+// request, once its handlers are registered.
+func (m *Messenger) logHandlers() {
+	if !m.debug.enabled {
+		return
+	}
+	m.debug.once.Do(func() {
+		fmt.Print("Messenger handlers\n", m.Handlers())
+	})
+}
@@ -0,0 +1,57 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_Handlers(t *testing.T) {
+	m := New(Options{
+		WebhookURL:          "/webhook",
+		PostbackDedupWindow: time.Second,
+		ModerationPolicy:    &wordPolicy{},
+	})
+	m.HandleMessage(func(Message, *Response) {})
+	m.HandleMessage(func(Message, *Response) {})
+
+	table := m.Handlers()
+	assert.Equal(t, []Route{{Path: "/webhook", Methods: []string{"GET", "POST"}}}, table.Routes)
+	assert.Equal(t, []string{"postback dedup", "moderation"}, table.Stages)
+	assert.Equal(t, 2, table.Handlers["message"])
+	assert.Equal(t, 0, table.Handlers["postback"])
+
+	m.SetMaintenanceMode("Back soon")
+	assert.Equal(t, []string{"postback dedup", "maintenance", "moderation"}, m.Handlers().Stages)
+
+	s := table.String()
+	assert.Contains(t, s, "  GET,POST /webhook\n")
+	assert.Contains(t, s, "  1. postback dedup\n  2. moderation\n")
+	assert.Contains(t, s, "  message: 2\n")
+	assert.Contains(t, s, "  postback: 0 (events are dropped)\n")
+}
+
+type commandList []string
+
+func (c commandList) Commands() []string { return c }
+
+func TestMessenger_HandlersStages(t *testing.T) {
+	m := New(Options{
+		MaxEventAge:      time.Minute,
+		BlockedSenders:   []int64{42},
+		ExcludeEchoes:    true,
+		ModerationPolicy: &wordPolicy{},
+		HandlerTimeout:   time.Second,
+	})
+	assert.Equal(t, []string{
+		"stale events",
+		"sender filter",
+		"echo exclusion",
+		"moderation",
+		"handler timeout",
+	}, m.Handlers().Stages)
+
+	m.ListCommands(commandList{"/start", "/stop"})
+	assert.Equal(t, []string{"/start", "/stop"}, m.Handlers().Commands)
+}
@@ -0,0 +1,177 @@
+package messenger
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultImageValidationTTL is how long the result of checking an image URL is
+// cached when no TTL is set on the ImageValidator.
+const DefaultImageValidationTTL = time.Hour
+
+// ImageURLError is returned when an image of a template element can not be
+// displayed by Messenger.
+type ImageURLError struct {
+	// Element is the index of the offending template element.
+	Element int
+	// URL is the image URL of the element.
+	URL string
+	// Err is the reason the image was rejected.
+	Err error
+}
+
+func (e *ImageURLError) Error() string {
+	return "element " + strconv.Itoa(e.Element) + " has an invalid image " + e.URL + ": " + e.Err.Error()
+}
+
+// Unwrap returns the reason the image was rejected.
+func (e *ImageURLError) Unwrap() error {
+	return e.Err
+}
+
+// ImageValidator checks that the images of template elements are served over
+// HTTPS, reachable and actually images before a template is sent, since
+// Facebook silently drops broken images from carousels. Results are cached,
+// except for network errors and server errors which may not last.
+type ImageValidator struct {
+	// Client is used to make the HEAD requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// TTL is how long results are cached. Defaults to
+	// DefaultImageValidationTTL.
+	TTL time.Duration
+	// Clock tells when cached results expire. Defaults to the Clock of the
+	// Messenger given the ImageValidator in its Options, or SystemClock.
+	Clock Clock
+
+	mu    sync.Mutex
+	cache map[string]imageValidation
+}
+
+type imageValidation struct {
+	err     error
+	expires time.Time
+}
+
+// NewImageValidator creates an ImageValidator with the default settings.
+func NewImageValidator() *ImageValidator {
+	return &ImageValidator{}
+}
+
+// Validate checks a single image URL.
+func (v *ImageValidator) Validate(imageURL string) error {
+	clock := v.Clock
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	now := clock.Now()
+
+	v.mu.Lock()
+	cached, ok := v.cache[imageURL]
+	v.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.err
+	}
+
+	cacheable, err := v.check(imageURL)
+	if !cacheable {
+		return err
+	}
+
+	ttl := v.TTL
+	if ttl == 0 {
+		ttl = DefaultImageValidationTTL
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cache == nil {
+		v.cache = make(map[string]imageValidation)
+	}
+	for u, c := range v.cache {
+		if !now.Before(c.expires) {
+			delete(v.cache, u)
+		}
+	}
+	v.cache[imageURL] = imageValidation{err: err, expires: now.Add(ttl)}
+
+	return err
+}
+
+// check checks an image URL, reporting whether the result can be cached:
+// network errors and server errors may not last.
+func (v *ImageValidator) check(imageURL string) (bool, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return true, err
+	}
+	if u.Scheme != "https" {
+		return true, xerrors.New("image must be served over https")
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Head(imageURL)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		cacheable := resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests
+		return cacheable, xerrors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "image/") {
+		return true, xerrors.Errorf("unexpected content type %q", ct)
+	}
+
+	return true, nil
+}
+
+// ValidateElements checks the images of every element which has one.
+func (v *ImageValidator) ValidateElements(elements []StructuredMessageElement) error {
+	for i, e := range elements {
+		if e.ImageURL == "" {
+			continue
+		}
+
+		if err := v.Validate(e.ImageURL); err != nil {
+			return &ImageURLError{Element: i, URL: e.ImageURL, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// validateTemplateImages checks the element images of a template message
+// before it is sent.
+func (m *Messenger) validateTemplateImages(msg interface{}) error {
+	if m.imageValidator == nil {
+		return nil
+	}
+
+	var payload StructuredMessagePayload
+	switch msg := msg.(type) {
+	case *SendStructuredMessage:
+		payload = msg.Message.Attachment.Payload
+	case SendStructuredMessage:
+		payload = msg.Message.Attachment.Payload
+	default:
+		return nil
+	}
+
+	if payload.Elements == nil {
+		return nil
+	}
+
+	return m.imageValidator.ValidateElements(*payload.Elements)
+}
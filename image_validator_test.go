@@ -0,0 +1,74 @@
+package messenger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageValidator(t *testing.T) {
+	requests := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/cat.jpg":
+			w.Header().Set("Content-Type", "image/jpeg")
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	v := &ImageValidator{Client: srv.Client()}
+
+	assert.NoError(t, v.Validate(srv.URL+"/cat.jpg"))
+	assert.NoError(t, v.Validate(srv.URL+"/cat.jpg"))
+	assert.Equal(t, 1, requests, "results should be cached")
+
+	assert.Error(t, v.Validate(srv.URL+"/page.html"))
+	assert.Error(t, v.Validate(srv.URL+"/missing.jpg"))
+	assert.Error(t, v.Validate("http://example.com/cat.jpg"))
+
+	err := v.ValidateElements([]StructuredMessageElement{
+		{Title: "no image"},
+		{Title: "cat", ImageURL: srv.URL + "/cat.jpg"},
+		{Title: "broken", ImageURL: srv.URL + "/missing.jpg"},
+	})
+	require.IsType(t, &ImageURLError{}, err)
+	assert.Equal(t, 2, err.(*ImageURLError).Element)
+}
+
+func TestImageValidatorCache(t *testing.T) {
+	requests := 0
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	clock := newFakeClock()
+	v := &ImageValidator{Client: srv.Client(), TTL: time.Minute}
+	New(Options{Clock: clock, ImageValidator: v})
+
+	// Server errors are not cached.
+	assert.Error(t, v.Validate(srv.URL+"/cat.jpg"))
+	status = http.StatusOK
+	assert.NoError(t, v.Validate(srv.URL+"/cat.jpg"))
+	assert.NoError(t, v.Validate(srv.URL+"/cat.jpg"))
+	assert.Equal(t, 2, requests)
+
+	// Expired results are checked again, and purged.
+	clock.Advance(2 * time.Minute)
+	assert.NoError(t, v.Validate(srv.URL+"/dog.jpg"))
+	assert.Len(t, v.cache, 1)
+	assert.NoError(t, v.Validate(srv.URL+"/cat.jpg"))
+	assert.Equal(t, 4, requests)
+}
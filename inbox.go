@@ -0,0 +1,142 @@
+package messenger
+
+import (
+	"net/url"
+	"strconv"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// CustomLabelsURL is the API endpoint for the custom labels of the Page
+// Inbox.
+// https://developers.facebook.com/docs/messenger-platform/identity/custom-labels
+const CustomLabelsURL = "https://graph.facebook.com/v2.11/me/custom_labels"
+
+// Labels of the Page Inbox managed by MarkDone, MarkSpam and MarkUnread.
+const (
+	InboxLabelDone   = "done"
+	InboxLabelSpam   = "spam"
+	InboxLabelUnread = "unread"
+)
+
+// Label is a custom label of the Page Inbox.
+type Label struct {
+	ID   int64  `json:"id,string"`
+	Name string `json:"name"`
+}
+
+// labelCache remembers the IDs of labels by name.
+type labelCache struct {
+	mu     sync.Mutex
+	ids    map[string]int64
+	listed bool
+}
+
+// Labels lists the custom labels of the page.
+func (m *Messenger) Labels() ([]Label, error) {
+	var resp struct {
+		Data []Label `json:"data"`
+	}
+	if err := m.graphCall("GET", CustomLabelsURL+"?fields=name", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
+// CreateLabel creates a custom label and returns its ID.
+func (m *Messenger) CreateLabel(name string) (int64, error) {
+	var resp struct {
+		ID int64 `json:"id,string"`
+	}
+	if err := m.graphCall("POST", CustomLabelsURL, map[string]string{"page_label_name": name}, &resp); err != nil {
+		return 0, err
+	}
+	return resp.ID, nil
+}
+
+// LabelUser applies a custom label to the conversation with a user.
+func (m *Messenger) LabelUser(labelID, psid int64) error {
+	return m.graphCall("POST", labelURL(labelID), map[string]string{"user": strconv.FormatInt(psid, 10)}, nil)
+}
+
+// UnlabelUser removes a custom label from the conversation with a user.
+func (m *Messenger) UnlabelUser(labelID, psid int64) error {
+	return m.graphCall("DELETE", labelURL(labelID)+"?user="+url.QueryEscape(strconv.FormatInt(psid, 10)), nil, nil)
+}
+
+// MarkDone marks the conversation with a user as done in the Page Inbox,
+// clearing the unread label.
+func (m *Messenger) MarkDone(psid int64) error {
+	if err := m.setInboxLabel(psid, InboxLabelDone, true); err != nil {
+		return err
+	}
+	return m.setInboxLabel(psid, InboxLabelUnread, false)
+}
+
+// MarkSpam marks the conversation with a user as spam in the Page Inbox.
+func (m *Messenger) MarkSpam(psid int64) error {
+	return m.setInboxLabel(psid, InboxLabelSpam, true)
+}
+
+// MarkUnread marks the conversation with a user as unread in the Page
+// Inbox, reopening it if it was done.
+func (m *Messenger) MarkUnread(psid int64) error {
+	if err := m.setInboxLabel(psid, InboxLabelUnread, true); err != nil {
+		return err
+	}
+	return m.setInboxLabel(psid, InboxLabelDone, false)
+}
+
+// setInboxLabel adds or removes one of the labels managed by the Messenger,
+// creating it the first time it is needed.
+func (m *Messenger) setInboxLabel(psid int64, name string, set bool) error {
+	id, err := m.labelID(name)
+	if err != nil {
+		return xerrors.Errorf("could not find label %s: %w", name, err)
+	}
+
+	if set {
+		return m.LabelUser(id, psid)
+	}
+	return m.UnlabelUser(id, psid)
+}
+
+func (m *Messenger) labelID(name string) (int64, error) {
+	m.labels.mu.Lock()
+	defer m.labels.mu.Unlock()
+
+	if id, ok := m.labels.ids[name]; ok {
+		return id, nil
+	}
+
+	if m.labels.ids == nil {
+		m.labels.ids = make(map[string]int64)
+	}
+
+	if !m.labels.listed {
+		labels, err := m.Labels()
+		if err != nil {
+			return 0, err
+		}
+		for _, l := range labels {
+			m.labels.ids[l.Name] = l.ID
+		}
+		m.labels.listed = true
+
+		if id, ok := m.labels.ids[name]; ok {
+			return id, nil
+		}
+	}
+
+	id, err := m.CreateLabel(name)
+	if err != nil {
+		return 0, err
+	}
+	m.labels.ids[name] = id
+	return id, nil
+}
+
+func labelURL(id int64) string {
+	return "https://graph.facebook.com/v2.11/" + strconv.FormatInt(id, 10) + "/label"
+}
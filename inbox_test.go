@@ -0,0 +1,54 @@
+package messenger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labelsGraph fakes the custom labels API, recording the calls made to it.
+type labelsGraph struct {
+	calls []string
+}
+
+func (g *labelsGraph) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+	}
+	g.calls = append(g.calls, req.Method+" "+req.URL.Path+" "+req.URL.Query().Get("user")+string(body))
+
+	response := `{"success":true}`
+	switch {
+	case req.Method == "GET" && req.URL.Path == "/v2.11/me/custom_labels":
+		response = `{"data":[{"name":"done","id":"1"},{"name":"vip","id":"2"}]}`
+	case req.Method == "POST" && req.URL.Path == "/v2.11/me/custom_labels":
+		response = `{"id":"3"}`
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(response)),
+	}, nil
+}
+
+func TestMessenger_InboxLabels(t *testing.T) {
+	graph := &labelsGraph{}
+	m := New(Options{HTTPClient: &http.Client{Transport: graph}})
+
+	require.NoError(t, m.MarkDone(111))
+	require.NoError(t, m.MarkSpam(111))
+
+	assert.Equal(t, []string{
+		"GET /v2.11/me/custom_labels ",
+		`POST /v2.11/1/label {"user":"111"}`,
+		`POST /v2.11/me/custom_labels {"page_label_name":"unread"}`,
+		"DELETE /v2.11/3/label 111",
+		`POST /v2.11/me/custom_labels {"page_label_name":"spam"}`,
+		`POST /v2.11/3/label {"user":"111"}`,
+	}, graph.calls)
+}
@@ -0,0 +1,74 @@
+package messenger
+
+import (
+	"sync"
+	"time"
+)
+
+// instantReplyMemory is how long the instant replies sent to a user are
+// remembered.
+const instantReplyMemory = time.Hour
+
+// pageReplies remembers when the Page's automations last answered each user.
+type pageReplies struct {
+	appIDs []int64
+
+	mu   sync.Mutex
+	last map[int64]time.Time
+}
+
+// IsInstantReply reports whether msg is the echo of a message sent by the
+// Page's automations, such as its instant reply, rather than by an app. The
+// apps of such messages are set by Options.InstantReplyAppIDs.
+func (m *Messenger) IsInstantReply(msg Message) bool {
+	if !msg.IsEcho {
+		return false
+	}
+
+	appIDs := m.instantReplies.appIDs
+	if appIDs == nil {
+		appIDs = []int64{InboxPageID}
+	}
+	for _, id := range appIDs {
+		if msg.AppID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// RepliedByPage reports whether the Page's automations answered the user
+// since the given time, so that a bot can avoid replying a second time. The
+// echoes of the answers must have been received by the Messenger; they may
+// arrive shortly after the message they answer.
+func (m *Messenger) RepliedByPage(psid int64, since time.Time) bool {
+	m.instantReplies.mu.Lock()
+	defer m.instantReplies.mu.Unlock()
+
+	last, ok := m.instantReplies.last[psid]
+	return ok && !last.Before(since)
+}
+
+// noteInstantReply remembers the instant reply echoed by info, if it is one.
+func (m *Messenger) noteInstantReply(info MessageInfo) {
+	if !m.IsInstantReply(*info.Message) {
+		return
+	}
+
+	sent := time.Unix(0, info.Timestamp*int64(time.Millisecond))
+	r := &m.instantReplies
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last == nil {
+		r.last = make(map[int64]time.Time)
+	}
+	for psid, t := range r.last {
+		if sent.Sub(t) > instantReplyMemory {
+			delete(r.last, psid)
+		}
+	}
+	if sent.After(r.last[info.Recipient.ID]) {
+		r.last[info.Recipient.ID] = sent
+	}
+}
@@ -0,0 +1,41 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_InstantReplies(t *testing.T) {
+	m := New(Options{})
+
+	var echoes []Message
+	m.HandleMessage(func(msg Message, r *Response) {
+		echoes = append(echoes, msg)
+	})
+
+	serveFixture(t, m, "message_echo.json")
+	require.Len(t, echoes, 1)
+	assert.EqualValues(t, 1517776481860111, echoes[0].AppID)
+	assert.False(t, m.IsInstantReply(echoes[0]))
+
+	sent := time.Date(2018, 11, 24, 21, 31, 51, 0, time.UTC)
+	echo := func(appID int64) MessageInfo {
+		return MessageInfo{
+			Sender:    Sender{ID: 1067280970047460},
+			Recipient: Recipient{ID: 111},
+			Timestamp: sent.UnixNano() / int64(time.Millisecond),
+			Message:   &Message{IsEcho: true, AppID: appID, Text: "Thanks, we will be in touch."},
+		}
+	}
+	m.dispatch(Receive{Entry: []Entry{{Messaging: []MessageInfo{echo(1517776481860111)}}}})
+	assert.False(t, m.RepliedByPage(111, sent.Add(-time.Second)))
+
+	m.dispatch(Receive{Entry: []Entry{{Messaging: []MessageInfo{echo(InboxPageID)}}}})
+	assert.True(t, m.IsInstantReply(*echo(InboxPageID).Message))
+	assert.True(t, m.RepliedByPage(111, sent.Add(-time.Second)))
+	assert.False(t, m.RepliedByPage(111, sent.Add(time.Second)))
+	assert.False(t, m.RepliedByPage(222, sent.Add(-time.Second)))
+}
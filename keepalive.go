@@ -0,0 +1,24 @@
+package messenger
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// isKeepAlive reports whether body, the body of a webhook request, is a
+// probe sent by Facebook to check that the webhook is up rather than
+// events: empty, or an empty JSON object. Other bodies without events are
+// decoding errors.
+func isKeepAlive(body []byte) bool {
+	body = bytes.TrimSpace(body)
+	return len(body) == 0 || bytes.Equal(bytes.Join(bytes.Fields(body), nil), []byte("{}"))
+}
+
+// answerKeepAlive answers a keep-alive webhook request, without logging an
+// error.
+func (m *Messenger) answerKeepAlive(w http.ResponseWriter, r *http.Request, body []byte) {
+	if m.onKeepAlive != nil {
+		m.onKeepAlive(r.RemoteAddr, body)
+	}
+	respond(w, http.StatusOK)
+}
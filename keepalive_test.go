@@ -0,0 +1,35 @@
+package messenger
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_KeepAlive(t *testing.T) {
+	var probes []string
+	m := New(Options{
+		Verify:      true,
+		AppSecret:   "secret",
+		OnKeepAlive: func(remoteAddr string, body []byte) { probes = append(probes, string(body)) },
+	})
+
+	for _, body := range []string{"", "\n", "{}", "{ }"} {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		assert.JSONEq(t, `{"code": 200, "status": "OK"}`, w.Body.String())
+	}
+	assert.Equal(t, []string{"", "\n", "{}", "{ }"}, probes)
+	assert.Empty(t, m.RecentErrors())
+
+	// Malformed or truncated bodies are still errors.
+	for _, body := range []string{`{"object":`, "ping", `"entry"`} {
+		w := httptest.NewRecorder()
+		m.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		assert.JSONEq(t, `{"code": 400, "status": "Bad Request"}`, w.Body.String())
+	}
+	assert.Len(t, m.RecentErrors(), 3)
+	assert.Len(t, probes, 4)
+}
@@ -0,0 +1,148 @@
+package messenger
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// LanguageDetector detects the language of incoming text messages. It is
+// invoked for every message with text before any MessageHandler runs, making
+// the result available in Message.Language and in the context of the
+// Response, see LanguageFromContext. Languages are ISO 639-1 codes such as
+// "en", or "" when unknown.
+type LanguageDetector interface {
+	DetectLanguage(text string) (string, error)
+}
+
+// LanguageDetectorFunc is an adapter to allow the use of ordinary functions
+// as a LanguageDetector.
+type LanguageDetectorFunc func(text string) (string, error)
+
+// DetectLanguage calls f(text).
+func (f LanguageDetectorFunc) DetectLanguage(text string) (string, error) {
+	return f(text)
+}
+
+type languageKey struct{}
+
+// LanguageFromContext returns the language of the message being handled, as
+// detected by the LanguageDetector set in Options, or "".
+func LanguageFromContext(ctx context.Context) string {
+	language, _ := ctx.Value(languageKey{}).(string)
+	return language
+}
+
+// detectLanguage runs the configured LanguageDetector over the text of msg,
+// and attaches the language to the context of resp.
+func (m *Messenger) detectLanguage(msg *Message, resp *Response) {
+	if m.languageDetector == nil || msg.Text == "" {
+		return
+	}
+
+	language, err := m.languageDetector.DetectLanguage(msg.Text)
+	if err != nil {
+		logEvent(resp.Context(), "could not detect language:", err)
+		return
+	}
+	if language == "" {
+		return
+	}
+
+	msg.Language = language
+	resp.ctx = context.WithValue(resp.Context(), languageKey{}, language)
+}
+
+// Localize picks the message of the language of the message being handled,
+// falling back on fallback. Messages are keyed by language such as "fr", or
+// by locale such as "fr_FR".
+func (r *Response) Localize(messages map[string]string, fallback string) string {
+	return localizedMessage(messages, LanguageFromContext(r.Context()), fallback)
+}
+
+// scriptLanguages are the languages recognised by their script alone, in
+// the order in which ties are broken.
+var scriptLanguages = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Thai, "th"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Greek, "el"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Devanagari, "hi"},
+}
+
+// stopwords are common words of the languages written in the Latin script.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "you", "to", "of", "it", "my", "what", "hello", "hi", "please", "thanks", "are", "have", "can", "this", "with"},
+	"fr": {"le", "la", "les", "et", "est", "je", "vous", "tu", "de", "des", "un", "une", "bonjour", "merci", "pas", "pour", "avec", "oui"},
+	"es": {"el", "la", "los", "las", "y", "es", "yo", "usted", "que", "de", "un", "una", "hola", "gracias", "por", "para", "con", "sí"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "sie", "du", "nicht", "ein", "eine", "hallo", "danke", "bitte", "mit", "für", "ja"},
+	"it": {"il", "lo", "gli", "e", "è", "io", "che", "di", "un", "una", "ciao", "grazie", "per", "con", "non", "sono"},
+	"pt": {"o", "os", "as", "e", "é", "eu", "você", "que", "de", "um", "uma", "olá", "obrigado", "obrigada", "por", "com", "não"},
+	"nl": {"de", "het", "en", "is", "ik", "je", "jij", "niet", "een", "hallo", "dank", "bedankt", "met", "voor", "ja"},
+}
+
+// stopwordLanguages are the languages of stopwords, in the order in which
+// ties are broken.
+var stopwordLanguages = []string{"en", "fr", "es", "de", "it", "pt", "nl"}
+
+// HeuristicLanguageDetector is a small LanguageDetector without
+// dependencies. It recognises languages by their script, and the main
+// languages written in the Latin script by their most common words. Use an
+// implementation backed by CLD or Lingua for better accuracy.
+type HeuristicLanguageDetector struct{}
+
+// DetectLanguage returns the most likely language of text, or "".
+func (HeuristicLanguageDetector) DetectLanguage(text string) (string, error) {
+	counts := map[string]int{}
+	for _, r := range text {
+		for _, s := range scriptLanguages {
+			if unicode.Is(s.table, r) {
+				counts[s.language]++
+				break
+			}
+		}
+	}
+	// Japanese mixes kanji with kana, so any kana wins over Chinese.
+	if counts["ja"] > 0 {
+		return "ja", nil
+	}
+	best, bestCount := "", 0
+	for _, s := range scriptLanguages {
+		if counts[s.language] > bestCount {
+			best, bestCount = s.language, counts[s.language]
+		}
+	}
+	if best != "" {
+		return best, nil
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	scores := map[string]int{}
+	for _, w := range words {
+		for language, list := range stopwords {
+			for _, s := range list {
+				if w == s {
+					scores[language]++
+					break
+				}
+			}
+		}
+	}
+
+	for _, language := range stopwordLanguages {
+		if scores[language] > bestCount {
+			best, bestCount = language, scores[language]
+		}
+	}
+	return best, nil
+}
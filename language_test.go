@@ -0,0 +1,44 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_LanguageDetector(t *testing.T) {
+	m := New(Options{LanguageDetector: HeuristicLanguageDetector{}})
+
+	var languages []string
+	var replies []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		languages = append(languages, msg.Language, LanguageFromContext(r.Context()))
+		replies = append(replies, r.Localize(map[string]string{"fr": "bonjour"}, "hello"))
+	})
+	serveFixture(t, m, "message_text.json")
+
+	assert.Equal(t, []string{"en", "en"}, languages)
+	assert.Equal(t, []string{"hello"}, replies)
+}
+
+func TestHeuristicLanguageDetector(t *testing.T) {
+	tests := map[string]string{
+		"hello, world!":                       "en",
+		"What is the price of this, please?":  "en",
+		"Bonjour, je voudrais des croissants": "fr",
+		"Hola, ¿cuánto cuesta? Gracias":       "es",
+		"Hallo, ich habe eine Frage":          "de",
+		"Здравствуйте":                        "ru",
+		"你好，世界":                               "zh",
+		"こんにちは世界":                             "ja",
+		"안녕하세요":                               "ko",
+		"1234":                                "",
+	}
+
+	for text, want := range tests {
+		got, err := HeuristicLanguageDetector{}.DetectLanguage(text)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, text)
+	}
+}
@@ -0,0 +1,124 @@
+package messenger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MaxTextLength is the maximum length of a text message accepted by Facebook.
+const MaxTextLength = 2000
+
+// fieldLimits are the maximum lengths of the fields of an outgoing message,
+// keyed by their path in the JSON payload with array indices elided.
+var fieldLimits = map[string]int{
+	"message.text":                                            MaxTextLength,
+	"message.quick_replies[].title":                           20,
+	"message.quick_replies[].payload":                         1000,
+	"message.attachment.payload.text":                         640,
+	"message.attachment.payload.buttons[].title":              20,
+	"message.attachment.payload.buttons[].payload":            1000,
+	"message.attachment.payload.elements[].title":             80,
+	"message.attachment.payload.elements[].subtitle":          80,
+	"message.attachment.payload.elements[].buttons[].title":   20,
+	"message.attachment.payload.elements[].buttons[].payload": 1000,
+}
+
+// FieldTooLongError is returned when a field of an outgoing message is longer
+// than Facebook accepts.
+type FieldTooLongError struct {
+	// Field is the path to the field in the payload, such as
+	// "message.quick_replies[2].payload".
+	Field string
+	// Length is the length of the field, as counted by Facebook.
+	Length int
+	// Limit is the maximum length of the field.
+	Limit int
+}
+
+func (e *FieldTooLongError) Error() string {
+	return fmt.Sprintf("%s is too long: %d characters, limit is %d", e.Field, e.Length, e.Limit)
+}
+
+// PayloadTooLargeError is returned when an outgoing message is larger than
+// Options.MaxPayloadSize once encoded.
+type PayloadTooLargeError struct {
+	// Size is the size of the encoded message in bytes.
+	Size int
+	// Limit is Options.MaxPayloadSize.
+	Limit int
+}
+
+func (e *PayloadTooLargeError) Error() string {
+	return fmt.Sprintf("payload is too large: %d bytes, limit is %d", e.Size, e.Limit)
+}
+
+// validatePayload checks the size of a marshalled message against maxSize,
+// unless it is zero, and the length of its fields, so that they are measured
+// exactly as they will be sent. The payload is scanned without being decoded
+// into a tree.
+func validatePayload(data []byte, maxSize int) error {
+	if maxSize > 0 && len(data) > maxSize {
+		return &PayloadTooLargeError{Size: len(data), Limit: maxSize}
+	}
+
+	return validateValue(json.NewDecoder(bytes.NewReader(data)), "", "")
+}
+
+// validateValue checks the next value read from dec, found at path.
+func validateValue(dec *json.Decoder, path, pattern string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		for i := 0; dec.More(); i++ {
+			elem, elemPattern := fmt.Sprintf("%s[%d]", path, i), pattern+"[]"
+			if tok == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				k, _ := key.(string)
+				elem, elemPattern = joinPath(path, k), joinPath(pattern, k)
+			}
+
+			if err := validateValue(dec, elem, elemPattern); err != nil {
+				return err
+			}
+		}
+		// the closing delimiter
+		_, err = dec.Token()
+		return err
+	case string:
+		limit, ok := fieldLimits[pattern]
+		if !ok {
+			return nil
+		}
+
+		if n := TextLength(tok); n > limit {
+			return &FieldTooLongError{Field: path, Length: n, Limit: limit}
+		}
+	}
+
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return strings.Join([]string{path, key}, ".")
+}
+
+// maxPayloadSize returns the Options.MaxPayloadSize of the Messenger of r, if
+// any.
+func (r *Response) maxPayloadSize() int {
+	if r.messenger == nil {
+		return 0
+	}
+	return r.messenger.maxPayloadSize
+}
@@ -0,0 +1,102 @@
+package messenger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePayload(t *testing.T) {
+	for name, test := range map[string]struct {
+		msg   interface{}
+		field string
+	}{
+		"short text": {
+			msg: SendMessage{Message: MessageData{Text: "hello"}},
+		},
+		"text at limit": {
+			msg: SendMessage{Message: MessageData{Text: strings.Repeat("a", MaxTextLength)}},
+		},
+		"emoji counted twice": {
+			msg:   SendMessage{Message: MessageData{Text: strings.Repeat("😀", MaxTextLength/2+1)}},
+			field: "message.text",
+		},
+		"escaped characters counted once": {
+			msg: SendMessage{Message: MessageData{Text: strings.Repeat("<&>\"", MaxTextLength/4)}},
+		},
+		"quick reply payload": {
+			msg: SendMessage{Message: MessageData{
+				Text: "pick one",
+				QuickReplies: []QuickReply{
+					{ContentType: "text", Title: "ok", Payload: "ok"},
+					{ContentType: "text", Title: "long", Payload: strings.Repeat("x", 1001)},
+				},
+			}},
+			field: "message.quick_replies[1].payload",
+		},
+		"element button title": {
+			msg: SendStructuredMessage{Message: StructuredMessageData{
+				Attachment: StructuredMessageAttachment{
+					Type: "template",
+					Payload: StructuredMessagePayload{
+						Elements: &[]StructuredMessageElement{
+							{Title: "ok", Buttons: []StructuredMessageButton{{Title: strings.Repeat("b", 21)}}},
+						},
+					},
+				},
+			}},
+			field: "message.attachment.payload.elements[0].buttons[0].title",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(test.msg)
+			require.NoError(t, err)
+
+			err = validatePayload(data, 0)
+			if test.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.IsType(t, &FieldTooLongError{}, err)
+			assert.Equal(t, test.field, err.(*FieldTooLongError).Field)
+		})
+	}
+}
+
+func TestValidatePayload_Size(t *testing.T) {
+	// Each "<" is escaped to six bytes, so the text is within its limit
+	// but the payload is not.
+	replies := make([]QuickReply, 11)
+	for i := range replies {
+		replies[i] = QuickReply{ContentType: "text", Title: "title", Payload: strings.Repeat("<", 1000)}
+	}
+	data, err := json.Marshal(SendMessage{Message: MessageData{Text: "pick one", QuickReplies: replies}})
+	require.NoError(t, err)
+	require.True(t, len(data) > 64<<10)
+
+	err = validatePayload(data, 64<<10)
+	require.IsType(t, &PayloadTooLargeError{}, err)
+	assert.Equal(t, len(data), err.(*PayloadTooLargeError).Size)
+
+	// Sizes are not checked without a limit.
+	assert.NoError(t, validatePayload(data, 0))
+
+	replies = replies[:1]
+	data, err = json.Marshal(SendMessage{Message: MessageData{Text: "pick one", QuickReplies: replies}})
+	require.NoError(t, err)
+	assert.NoError(t, validatePayload(data, 64<<10))
+}
+
+func TestMessenger_MaxPayloadSize(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client(), MaxPayloadSize: 100})
+
+	err := m.Response(111).Text(strings.Repeat("a", 100), ResponseType)
+	require.IsType(t, &PayloadTooLargeError{}, err)
+	assert.Equal(t, 100, err.(*PayloadTooLargeError).Limit)
+	assert.Equal(t, 0, graph.count())
+}
@@ -0,0 +1,56 @@
+package messenger
+
+import "time"
+
+// LocationAttachment is the type of the attachments of a shared location.
+// Locations can only be received.
+const LocationAttachment AttachmentType = "location"
+
+// Location is a location shared by a user.
+type Location struct {
+	// Sender is who the location was sent from.
+	Sender Sender
+	// Recipient is who the location was sent to.
+	Recipient Recipient
+	// Time is when the location was sent.
+	Time time.Time
+	// Title is the name of the location.
+	Title string
+	// URL is a link to the location on a map.
+	URL string
+	// Coordinates is where the location is.
+	Coordinates Coordinates
+}
+
+// LocationHandler is a handler used for responding to a shared location.
+type LocationHandler func(Location, *Response)
+
+// Locations returns the locations shared in the message.
+func (m Message) Locations() []Location {
+	var locations []Location
+	for _, a := range m.Attachments {
+		if a.Type != string(LocationAttachment) || a.Payload.Coordinates == nil {
+			continue
+		}
+
+		locations = append(locations, Location{
+			Sender:      m.Sender,
+			Recipient:   m.Recipient,
+			Time:        m.Time,
+			Title:       a.Title,
+			URL:         a.URL,
+			Coordinates: *a.Payload.Coordinates,
+		})
+	}
+	return locations
+}
+
+// HandleLocation adds a new LocationHandler to the Messenger which will be
+// triggered for every location shared by a user.
+func (m *Messenger) HandleLocation(f LocationHandler) {
+	m.HandleMessage(func(msg Message, r *Response) {
+		for _, l := range msg.Locations() {
+			f(l, r)
+		}
+	})
+}
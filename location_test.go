@@ -0,0 +1,32 @@
+package messenger
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_HandleLocation(t *testing.T) {
+	m := New(Options{})
+
+	var locations []Location
+	m.HandleLocation(func(l Location, r *Response) {
+		locations = append(locations, l)
+	})
+
+	for _, name := range []string{"message_text.json", "message_attachments.json", "message_location.json"} {
+		f, err := os.Open("testdata/webhooks/" + name)
+		require.NoError(t, err)
+		m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", f))
+		f.Close()
+	}
+
+	require.Len(t, locations, 1)
+	assert.Equal(t, "Jane's Location", locations[0].Title)
+	assert.Equal(t, Coordinates{Lat: -33.8688, Long: 151.2093}, locations[0].Coordinates)
+	assert.EqualValues(t, 1254459154682919, locations[0].Sender.ID)
+	assert.NotEmpty(t, locations[0].URL)
+}
@@ -0,0 +1,94 @@
+package messenger
+
+import (
+	"strings"
+	"sync"
+)
+
+// maintenanceMode holds the notice sent while the bot is under maintenance.
+type maintenanceMode struct {
+	mu        sync.RWMutex
+	message   string
+	localized map[string]string
+}
+
+// SetMaintenanceMode puts the bot under maintenance: messages and postbacks
+// no longer reach their handlers and are answered with message instead. An
+// empty message ends the maintenance.
+func (m *Messenger) SetMaintenanceMode(message string) {
+	m.maintenance.mu.Lock()
+	defer m.maintenance.mu.Unlock()
+
+	m.maintenance.message = message
+}
+
+// SetMaintenanceMessages sets translations of the maintenance notice, keyed
+// by locale such as "fr_FR", or by language such as "fr". The locale of
+// users is then fetched from their profile, and the message given to
+// SetMaintenanceMode is used for the other locales.
+func (m *Messenger) SetMaintenanceMessages(messages map[string]string) {
+	m.maintenance.mu.Lock()
+	defer m.maintenance.mu.Unlock()
+
+	m.maintenance.localized = messages
+}
+
+// InMaintenance reports whether the bot is under maintenance.
+func (m *Messenger) InMaintenance() bool {
+	m.maintenance.mu.RLock()
+	defer m.maintenance.mu.RUnlock()
+
+	return m.maintenance.message != ""
+}
+
+// answerMaintenance answers an event with the maintenance notice, reporting
+// whether the bot is under maintenance and its handlers must be skipped.
+func (m *Messenger) answerMaintenance(a Action, info MessageInfo) bool {
+	m.maintenance.mu.RLock()
+	message, localized := m.maintenance.message, m.maintenance.localized
+	m.maintenance.mu.RUnlock()
+
+	if message == "" {
+		return false
+	}
+
+	switch a {
+	case TextAction:
+		// Echoes are skipped without being answered.
+		if info.Message.IsEcho {
+			return true
+		}
+	case PostBackAction:
+	default:
+		return false
+	}
+
+	if len(localized) > 0 {
+		p, err := m.ProfileByID(info.Sender.ID, []string{"locale"})
+		if err == nil {
+			message = localizedMessage(localized, p.Locale, message)
+		}
+	}
+
+	if err := m.Response(info.Sender.ID).Text(message, ResponseType); err != nil {
+		m.recordError(err)
+	}
+	return true
+}
+
+// localizedMessage picks the message for locale, falling back on its
+// language, then on fallback.
+func localizedMessage(messages map[string]string, locale, fallback string) string {
+	if message, ok := messages[locale]; ok {
+		return message
+	}
+
+	language := locale
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		language = locale[:i]
+	}
+	if message, ok := messages[language]; ok {
+		return message
+	}
+	return fallback
+}
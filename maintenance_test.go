@@ -0,0 +1,45 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_MaintenanceMode(t *testing.T) {
+	graph := newFakeGraph(`{"locale":"fr_FR"}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	var handled int
+	m.HandleMessage(func(Message, *Response) { handled++ })
+	m.HandleDelivery(func(Delivery, *Response) { handled++ })
+
+	m.SetMaintenanceMode("Back soon!")
+	assert.True(t, m.InMaintenance())
+
+	serveFixture(t, m, "message_text.json")
+	serveFixture(t, m, "message_echo.json")
+	serveFixture(t, m, "delivery.json")
+	assert.Equal(t, 1, handled)
+	require.Equal(t, 1, graph.count())
+	assert.Contains(t, graph.bodies[0], `"text":"Back soon!"`)
+
+	m.SetMaintenanceMessages(map[string]string{"fr": "De retour bientôt !"})
+	serveFixture(t, m, "postback.json")
+	require.Equal(t, 3, graph.count())
+	assert.Contains(t, graph.requests[1].URL.RawQuery, "fields=locale")
+	assert.Contains(t, graph.bodies[2], `"text":"De retour bientôt !"`)
+
+	m.SetMaintenanceMode("")
+	assert.False(t, m.InMaintenance())
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 2, handled)
+}
+
+func TestLocalizedMessage(t *testing.T) {
+	messages := map[string]string{"fr_CA": "Bientôt, là", "fr": "Bientôt"}
+	assert.Equal(t, "Bientôt, là", localizedMessage(messages, "fr_CA", "Soon"))
+	assert.Equal(t, "Bientôt", localizedMessage(messages, "fr_FR", "Soon"))
+	assert.Equal(t, "Soon", localizedMessage(messages, "en_US", "Soon"))
+}
@@ -0,0 +1,41 @@
+package messenger
+
+import "golang.org/x/xerrors"
+
+// MediaElement is the image or video of a media template, given either by
+// the ID of an uploaded attachment or by the URL of a Facebook media.
+type MediaElement struct {
+	// MediaType is ImageAttachment or VideoAttachment.
+	MediaType AttachmentType `json:"media_type"`
+	// AttachmentID is the ID of an attachment uploaded with the Attachment
+	// Upload API.
+	AttachmentID string `json:"attachment_id,omitempty"`
+	// URL is the URL of a video or photo posted on Facebook, such as
+	// https://www.facebook.com/<page>/videos/<id>/.
+	URL string `json:"url,omitempty"`
+	// Buttons are shown over the media.
+	Buttons []StructuredMessageButton `json:"buttons,omitempty"`
+}
+
+// MediaTemplatePayload is the payload of a media template.
+type MediaTemplatePayload struct {
+	TemplateType string         `json:"template_type"`
+	Elements     []MediaElement `json:"elements"`
+	Sharable     bool           `json:"sharable,omitempty"`
+}
+
+// MediaTemplate sends an image or a video with buttons. Exactly one of the
+// AttachmentID and the URL of the element must be set.
+func (r *Response) MediaTemplate(element MediaElement, messagingType MessagingType, tags ...string) error {
+	if element.MediaType != ImageAttachment && element.MediaType != VideoAttachment {
+		return xerrors.Errorf("media template of type %q, must be image or video", element.MediaType)
+	}
+	if (element.AttachmentID == "") == (element.URL == "") {
+		return xerrors.New("media template must have either an attachment ID or a URL")
+	}
+
+	return r.templateMessage(MediaTemplatePayload{
+		TemplateType: "media",
+		Elements:     []MediaElement{element},
+	}, messagingType, tags...)
+}
@@ -0,0 +1,48 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_MediaTemplate(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+	r := m.Response(fixturePSID)
+
+	err := r.MediaTemplate(MediaElement{
+		MediaType:    ImageAttachment,
+		AttachmentID: "1854626884821032",
+		Buttons:      []StructuredMessageButton{{Type: "web_url", URL: "https://example.com", Title: "View Website"}},
+	}, ResponseType)
+	require.NoError(t, err)
+	require.NoError(t, r.MediaTemplate(MediaElement{MediaType: VideoAttachment, URL: "https://www.facebook.com/page/videos/1/"}, ResponseType))
+
+	assert.Error(t, r.MediaTemplate(MediaElement{MediaType: ImageAttachment}, ResponseType))
+	assert.Error(t, r.MediaTemplate(MediaElement{MediaType: ImageAttachment, AttachmentID: "1", URL: "https://example.com"}, ResponseType))
+	assert.Error(t, r.MediaTemplate(MediaElement{MediaType: FileAttachment, AttachmentID: "1"}, ResponseType))
+
+	require.Equal(t, 2, graph.count())
+	assert.JSONEq(t, `{
+		"messaging_type": "RESPONSE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {"attachment": {"type": "template", "payload": {
+			"template_type": "media",
+			"elements": [{
+				"media_type": "image",
+				"attachment_id": "1854626884821032",
+				"buttons": [{"type": "web_url", "url": "https://example.com", "title": "View Website"}]
+			}]
+		}}}
+	}`, graph.bodies[0])
+	assert.JSONEq(t, `{
+		"messaging_type": "RESPONSE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {"attachment": {"type": "template", "payload": {
+			"template_type": "media",
+			"elements": [{"media_type": "video", "url": "https://www.facebook.com/page/videos/1/"}]
+		}}}
+	}`, graph.bodies[1])
+}
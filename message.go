@@ -15,6 +15,10 @@ type Message struct {
 	Time time.Time `json:"-"`
 	// Message is mine
 	IsEcho bool `json:"is_echo,omitempty"`
+	// Metadata is the metadata sent along with an echoed message.
+	Metadata string `json:"metadata,omitempty"`
+	// AppID is the ID of the app which sent an echoed message.
+	AppID int64 `json:"app_id,omitempty"`
 	// Mid is the ID of the message.
 	Mid string `json:"mid"`
 	// Seq is order the message was sent in relation to other messages.
@@ -31,6 +35,22 @@ type Message struct {
 	// Entities for NLP
 	// https://developers.facebook.com/docs/messenger-platform/built-in-nlp/
 	NLP json.RawMessage `json:"nlp"`
+	// TranscribedText is the text of any audio attachments, as produced by
+	// the Transcriber set in Options. Empty if no Transcriber is configured.
+	TranscribedText string `json:"-"`
+	// ImageAnalyses are the results of analysing any image attachments, as
+	// produced by the ImageAnalyzer set in Options.
+	ImageAnalyses []ImageAnalysis `json:"-"`
+	// Intent is the intent of the text, as detected by the NLU set in
+	// Options. Nil if no NLU is configured or detection failed.
+	Intent *Intent `json:"-"`
+	// Language is the language of the text, as detected by the
+	// LanguageDetector set in Options. Empty if no LanguageDetector is
+	// configured or the language is unknown.
+	Language string `json:"-"`
+	// Flagged is set when the ModerationPolicy set in Options flags the
+	// message.
+	Flagged bool `json:"-"`
 }
 
 // Delivery represents a the event fired when Facebook delivers a message to the
@@ -62,6 +82,10 @@ type PostBack struct {
 	Recipient Recipient `json:"-"`
 	// Time is when the message was sent.
 	Time time.Time `json:"-"`
+	// Mid is the ID of the postback.
+	Mid string `json:"mid,omitempty"`
+	// Title is the title of the button which was tapped
+	Title string `json:"title,omitempty"`
 	// PostBack ID
 	Payload string `json:"payload"`
 	// Optional referral info
@@ -0,0 +1,35 @@
+package messenger
+
+import "context"
+
+// EchoHandler is a handler used for the echoes of messages sent by the page,
+// whether by the Messenger, another app or a person in the Page inbox. Its
+// Response sends to the user the echoed message was sent to.
+type EchoHandler func(Message, *Response)
+
+// HandleMessageEcho adds a new EchoHandler to the Messenger which will be
+// triggered for the echo of every message sent by the page. Echoes also
+// reach message handlers, unless Options.ExcludeEchoes is set.
+func (m *Messenger) HandleMessageEcho(f EchoHandler) {
+	m.echoHandlers = append(m.echoHandlers, f)
+}
+
+// runEchoHandlers triggers the echo handlers of an echoed message. It returns
+// the error of the first handler which was abandoned, see invoke.
+func (m *Messenger) runEchoHandlers(ctx context.Context, message Message, info MessageInfo) error {
+	if len(m.echoHandlers) == 0 {
+		return nil
+	}
+
+	resp := m.newResponse(Recipient{ID: info.Recipient.ID})
+	resp.surface = info.surface
+	resp.ctx = ctx
+	var abandoned error
+	for _, f := range m.echoHandlers {
+		f := f
+		if err := m.invoke(TextAction, resp, func(r *Response) { f(message, r) }); abandoned == nil {
+			abandoned = err
+		}
+	}
+	return abandoned
+}
@@ -0,0 +1,37 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_HandleMessageEcho(t *testing.T) {
+	for _, exclude := range []bool{false, true} {
+		graph := newFakeGraph(`{}`)
+		m := New(Options{HTTPClient: graph.client(), ExcludeEchoes: exclude})
+
+		var echoes, messages []string
+		m.HandleMessageEcho(func(msg Message, r *Response) {
+			echoes = append(echoes, msg.Text)
+			require.NoError(t, r.SenderAction(MarkSeenAction))
+		})
+		m.HandleMessage(func(msg Message, r *Response) {
+			messages = append(messages, msg.Text)
+		})
+		serveFixture(t, m, "message_echo.json")
+		serveFixture(t, m, "message_text.json")
+
+		assert.Equal(t, []string{"Thanks for your message!"}, echoes)
+		if exclude {
+			assert.Equal(t, []string{"hello, world!"}, messages)
+		} else {
+			assert.Equal(t, []string{"Thanks for your message!", "hello, world!"}, messages)
+		}
+
+		// The Response of echo handlers sends to the user.
+		require.Equal(t, 1, graph.count())
+		assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"sender_action":"mark_seen"}`, graph.bodies[0])
+	}
+}
@@ -0,0 +1,44 @@
+package messenger
+
+import "golang.org/x/xerrors"
+
+var (
+	// ErrMissingMessagingType is returned when sending a message without a
+	// messaging type.
+	ErrMissingMessagingType = xerrors.New("a messaging type is required to send a message")
+	// ErrMissingTag is returned when sending a MessageTagType message without
+	// a tag.
+	ErrMissingTag = xerrors.New("a tag is required with messaging type MESSAGE_TAG")
+)
+
+// validateMessagingType checks that a message has a messaging type, and a tag
+// if it needs one. Messages to one-time notification tokens are sent without
+// a messaging type.
+func validateMessagingType(to Recipient, messagingType MessagingType, tag string) error {
+	switch {
+	case messagingType == "" && to.OneTimeNotifToken == "":
+		return ErrMissingMessagingType
+	case messagingType == MessageTagType && tag == "":
+		return ErrMissingTag
+	}
+
+	return nil
+}
+
+// Validate checks that the message can be sent.
+func (m SendMessage) Validate() error {
+	return validateMessagingType(m.Recipient, m.MessagingType, m.Tag)
+}
+
+// Validate checks that the message can be sent.
+func (m SendStructuredMessage) Validate() error {
+	return validateMessagingType(m.Recipient, m.MessagingType, m.Tag)
+}
+
+// validateMessage validates messages which know how to.
+func validateMessage(m interface{}) error {
+	if v, ok := m.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
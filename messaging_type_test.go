@@ -0,0 +1,56 @@
+package messenger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendMessage_WireFormat(t *testing.T) {
+	for name, test := range map[string]struct {
+		msg  interface{}
+		wire string
+	}{
+		"text": {
+			SendMessage{MessagingType: ResponseType, Recipient: Recipient{ID: 1}, Message: MessageData{Text: "hi"}},
+			`{"messaging_type":"RESPONSE","recipient":{"id":"1"},"message":{"text":"hi"}}`,
+		},
+		"tagged": {
+			SendMessage{MessagingType: MessageTagType, Recipient: Recipient{ID: 1}, Message: MessageData{Text: "hi"}, Tag: "ACCOUNT_UPDATE"},
+			`{"messaging_type":"MESSAGE_TAG","recipient":{"id":"1"},"message":{"text":"hi"},"tag":"ACCOUNT_UPDATE"}`,
+		},
+		"no messaging type": {
+			SendMessage{Recipient: Recipient{ID: 1}, Message: MessageData{Text: "hi"}},
+			`{"recipient":{"id":"1"},"message":{"text":"hi"}}`,
+		},
+		"structured without messaging type": {
+			SendStructuredMessage{Recipient: Recipient{ID: 1}, Message: StructuredMessageData{Attachment: StructuredMessageAttachment{Type: ImageAttachment, Payload: StructuredMessagePayload{Url: "https://example.com/a.png"}}}},
+			`{"recipient":{"id":"1"},"message":{"attachment":{"type":"image","payload":{"url":"https://example.com/a.png"}}}}`,
+		},
+		"sender action": {
+			SendSenderAction{Recipient: Recipient{ID: 1}, SenderAction: TypingOnAction},
+			`{"recipient":{"id":"1"},"sender_action":"typing_on"}`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(test.msg)
+			require.NoError(t, err)
+			assert.Equal(t, test.wire, string(data))
+		})
+	}
+}
+
+func TestValidateMessagingType(t *testing.T) {
+	to := Recipient{ID: 1}
+	assert.NoError(t, validateMessagingType(to, ResponseType, ""))
+	assert.NoError(t, validateMessagingType(to, UpdateType, "ACCOUNT_UPDATE"))
+	assert.NoError(t, validateMessagingType(to, MessageTagType, "ACCOUNT_UPDATE"))
+	assert.Equal(t, ErrMissingTag, validateMessagingType(to, MessageTagType, ""))
+	assert.Equal(t, ErrMissingMessagingType, validateMessagingType(to, "", ""))
+	assert.NoError(t, validateMessagingType(Recipient{OneTimeNotifToken: "otn"}, "", ""))
+
+	r := &Response{}
+	assert.Equal(t, ErrMissingTag, r.Text("hi", MessageTagType))
+}
@@ -2,13 +2,14 @@ package messenger
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha1"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/xerrors"
@@ -44,6 +45,161 @@ type Options struct {
 	WebhookURL string
 	// Mux is shared mux between several Messenger objects
 	Mux *http.ServeMux
+	// Transcriber, if set, is used to transcribe incoming audio attachments
+	// before message handlers are triggered.
+	Transcriber Transcriber
+	// ImageAnalyzer, if set, is used to label incoming image attachments
+	// before message handlers are triggered.
+	ImageAnalyzer ImageAnalyzer
+	// NLU, if set, is used to detect the intent of incoming text messages
+	// before message handlers are triggered.
+	NLU NLU
+	// LanguageDetector, if set, is used to detect the language of incoming
+	// text messages before message handlers are triggered. See
+	// HeuristicLanguageDetector.
+	LanguageDetector LanguageDetector
+	// ModerationPolicy, if set, screens incoming messages before message
+	// handlers are triggered, and outgoing messages before they are sent.
+	ModerationPolicy ModerationPolicy
+	// AllowedSenders, if set, are the only users whose events are handled,
+	// such as the developers of a test page. Echoes are sent by the page.
+	AllowedSenders []int64
+	// BlockedSenders are users whose events are ignored.
+	BlockedSenders []int64
+	// SenderFilter, if set, is called with the ID of the sender of every
+	// event, which is ignored unless it returns true.
+	SenderFilter func(psid int64) bool
+	// BusinessHours, if set, answers users automatically while the business
+	// is closed.
+	BusinessHours *BusinessHours
+	// OnEchoConfirmed, if set, is called when the echo of a message sent by
+	// the Messenger arrives, confirming that it appeared in the thread. Such
+	// echoes are not passed to message handlers.
+	OnEchoConfirmed func(EchoConfirmation)
+	// EchoAppIDs, if set, are the apps whose echoes are passed to message
+	// handlers. The echoes of messages sent by other apps on the Page, such
+	// as the Page inbox, are ignored.
+	EchoAppIDs []int64
+	// EchoWindow is how long echoes of sent messages are waited for.
+	// Defaults to DefaultEchoWindow.
+	EchoWindow time.Duration
+	// ExcludeEchoes keeps the echoes of messages sent by the page from
+	// message handlers, so that they can not reply to themselves. Echoes
+	// still reach the handlers added with HandleMessageEcho.
+	ExcludeEchoes bool
+	// Analytics, if set, enables aggregate counters of the activity of
+	// users, available from Messenger.Analytics.
+	Analytics *AnalyticsOptions
+	// Codec, if set, replaces encoding/json to decode webhooks and encode
+	// sent messages.
+	Codec Codec
+	// PooledDecoding decodes webhooks into pooled values which are reused
+	// once every handler has returned, reducing allocations under heavy
+	// load. Handlers must not keep the values passed to them, or anything
+	// they point to, after returning; see Messenger.DecodeReceive.
+	PooledDecoding bool
+	// PostbackDedupWindow, if set, drops postbacks with the same sender and
+	// payload as one sent less than this long before, as happens when users
+	// double-tap buttons.
+	PostbackDedupWindow time.Duration
+	// InstantReplyAppIDs are the apps whose echoes are reported by
+	// Messenger.IsInstantReply and Messenger.RepliedByPage as answers from
+	// the Page's automations. Defaults to InboxPageID, the app of the Page
+	// inbox, which sends instant replies.
+	InstantReplyAppIDs []int64
+	// CaptureRate, if set, is the fraction of webhook requests, between 0
+	// and 1, which are captured for debugging. The most recent captures are
+	// available from Messenger.CapturedPayloads and the admin API.
+	CaptureRate float64
+	// CaptureSize is the number of captured requests kept. Defaults to
+	// DefaultCaptureSize.
+	CaptureSize int
+	// Alerts, if set, raises alerts when sends, signature checks or the
+	// decoding of webhooks fail repeatedly.
+	Alerts *AlertOptions
+	// MaxEventAge, if set, rejects the webhook entries whose time is further
+	// than this from now, so that replayed payloads do not trigger handlers
+	// even if their signature is valid. Rejections are counted in
+	// EventStats.
+	MaxEventAge time.Duration
+	// HandlerTimeout, if set, limits how long each handler invocation may
+	// take. A handler which times out is abandoned, with the context of its
+	// Response done, and the next handler runs.
+	HandlerTimeout time.Duration
+	// OnHandlerTimeout, if set, is called when a handler times out.
+	OnHandlerTimeout func(HandlerTimeout)
+	// EventHooks wrap the processing of each webhook event.
+	EventHooks EventHooks
+	// Outbox, if set, queues messages which could not be sent because
+	// Facebook was unavailable. Messenger.RunOutbox retries them.
+	Outbox *Outbox
+	// Escalation configures how conversations are handed over to human
+	// agents by Response.EscalateToAgent.
+	Escalation EscalationOptions
+	// Transcript, if set, receives a record of every dispatched event and
+	// every message sent.
+	Transcript *TranscriptWriter
+	// ImageValidator, if set, checks the images of template elements before
+	// templates are sent.
+	ImageValidator *ImageValidator
+	// PSIDHashKey, if set, is used to anonymize the IDs of users in the logs
+	// and metrics produced by the Messenger.
+	PSIDHashKey []byte
+	// DisableGzip turns off the transparent decompression of gzip encoded
+	// webhook requests.
+	DisableGzip bool
+	// MaxBodySize is the largest webhook request body, after decompression,
+	// which is accepted. Defaults to DefaultMaxBodySize.
+	MaxBodySize int64
+	// MaxPayloadSize, if set, is the largest outgoing message, in bytes once
+	// encoded, which is sent. Larger messages fail with a
+	// PayloadTooLargeError. The Send API does not document a limit, so
+	// messages are not checked by default.
+	MaxPayloadSize int
+	// VerifyRequestPolicy, if set, must allow webhook verification requests
+	// before they are answered.
+	VerifyRequestPolicy VerifyRequestPolicy
+	// OnVerify, if set, is called with the outcome of every webhook
+	// verification request and the address it came from, such as to alert
+	// on probes with a wrong verify token.
+	OnVerify func(success bool, remoteAddr string)
+	// OnKeepAlive, if set, is called with the address and body of the
+	// webhook requests which carry no events, the empty bodies or empty JSON
+	// objects Facebook sometimes sends as probes. They are answered
+	// successfully without being logged as errors.
+	OnKeepAlive func(remoteAddr string, body []byte)
+	// OnWebhookTimings, if set, is called after every webhook request with
+	// how long each phase of it took. The timings of dispatched requests are
+	// also summarized in EventStats.
+	OnWebhookTimings func(WebhookTimings)
+	// Offload, if set, sends text messages which are too long as a file
+	// rather than failing.
+	Offload *OffloadPolicy
+	// CorrelationIDs, if set, gives every webhook event a correlation ID,
+	// available to handlers through CorrelationIDFromContext. It prefixes
+	// the log lines about the event, and is added to the metadata of the
+	// messages its handlers send.
+	CorrelationIDs bool
+	// Debug, if set, logs the HandlerTable of the Messenger when it serves
+	// its first request.
+	Debug bool
+	// HTTPClient is used for all calls to the Graph API. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// Proxy, if set and HTTPClient is not, is the HTTP, HTTPS or SOCKS5 proxy
+	// through which calls to the Graph API are made.
+	Proxy *url.URL
+	// GraphHosts are the hosts of the Graph API to use, in order of
+	// preference, such as regional endpoints. Requests fail over to the next
+	// host on network errors. Defaults to DefaultGraphHost only.
+	GraphHosts []string
+	// Endpoints is the profile of the infrastructure the Graph API calls are
+	// made to, such as MockEndpoints in tests. Defaults to
+	// ProductionEndpoints.
+	Endpoints Endpoints
+	// Clock is used whenever the Messenger needs the current time. Defaults
+	// to SystemClock.
+	Clock Clock
 }
 
 // MessageHandler is a handler used for responding to a message containing text.
@@ -72,16 +228,78 @@ type AccountLinkingHandler func(AccountLinking, *Response)
 type Messenger struct {
 	mux                    *http.ServeMux
 	messageHandlers        []MessageHandler
+	fallbackHandlers       []MessageHandler
 	deliveryHandlers       []DeliveryHandler
 	readHandlers           []ReadHandler
 	postBackHandlers       []PostBackHandler
 	optInHandlers          []OptInHandler
 	referralHandlers       []ReferralHandler
 	accountLinkingHandlers []AccountLinkingHandler
+	orderHandlers          []OrderHandler
+	echoHandlers           []EchoHandler
+	standbyHandlers        []StandbyHandler
+	handoverHandlers       []PassThreadControlHandler
+	threadRequestHandlers  []RequestThreadControlHandler
+	paymentHandlers        []PaymentHandler
+	preCheckoutHandlers    []PreCheckoutHandler
+	excludeEchoes          bool
 	token                  string
-	verifyHandler          func(http.ResponseWriter, *http.Request)
+	verifyHandler          func(http.ResponseWriter, *http.Request) bool
+	onVerify               func(success bool, remoteAddr string)
+	onKeepAlive            func(remoteAddr string, body []byte)
+	onWebhookTimings       func(WebhookTimings)
+	phases                 phaseStats
+	offload                *OffloadPolicy
+	correlationIDs         bool
 	verify                 bool
 	appSecret              string
+	transcriber            Transcriber
+	imageAnalyzer          ImageAnalyzer
+	nlu                    NLU
+	languageDetector       LanguageDetector
+	moderation             ModerationPolicy
+	transcript             *TranscriptWriter
+	recentErrors           errorRing
+	imageValidator         *ImageValidator
+	psidHasher             *PSIDHasher
+	disableGzip            bool
+	maxBodySize            int64
+	maxPayloadSize         int
+	verifyPolicy           VerifyRequestPolicy
+	client                 *http.Client
+	graphHosts             []string
+	endpoints              Endpoints
+	clock                  Clock
+	escalation             EscalationOptions
+	escalationsMu          sync.Mutex
+	labels                 labelCache
+	formatters             formatterCache
+	stats                  eventStats
+	outbox                 *Outbox
+	eventHooks             EventHooks
+	senders                senderFilter
+	maintenance            maintenanceMode
+	businessHours          *BusinessHours
+	echoes                 echoTracker
+	analytics              *analytics
+	codec                  Codec
+	pooled                 bool
+	handlerTimeout         time.Duration
+	onHandlerTimeout       func(HandlerTimeout)
+	postbacks              postbackDedup
+	webhookURL             string
+	instantReplies         pageReplies
+	echoAppIDs             []int64
+	captures               payloadCapture
+	alerts                 *alerter
+	maxEventAge            time.Duration
+	debug                  debugLog
+	stepsOnce              sync.Once
+	eventSteps             []eventStep
+	messageSteps           []messageStep
+	rateLimits             rateLimitTracker
+	unknownEvents          unknownEvents
+	commands               []CommandLister
 }
 
 // New creates a new Messenger. You pass in Options in order to affect settings.
@@ -91,10 +309,85 @@ func New(mo Options) *Messenger {
 	}
 
 	m := &Messenger{
-		mux:       mo.Mux,
-		token:     mo.Token,
-		verify:    mo.Verify,
-		appSecret: mo.AppSecret,
+		mux:            mo.Mux,
+		token:          mo.Token,
+		verify:         mo.Verify,
+		appSecret:      mo.AppSecret,
+		transcriber:    mo.Transcriber,
+		imageAnalyzer:  mo.ImageAnalyzer,
+		nlu:            mo.NLU,
+		transcript:     mo.Transcript,
+		imageValidator: mo.ImageValidator,
+		disableGzip:    mo.DisableGzip,
+		maxBodySize:    mo.MaxBodySize,
+		maxPayloadSize: mo.MaxPayloadSize,
+		verifyPolicy:   mo.VerifyRequestPolicy,
+		client:         mo.HTTPClient,
+		graphHosts:     mo.GraphHosts,
+		clock:          mo.Clock,
+		escalation:     mo.Escalation,
+		outbox:         mo.Outbox,
+		eventHooks:     mo.EventHooks,
+		businessHours:  mo.BusinessHours,
+		codec:          mo.Codec,
+		pooled:         mo.PooledDecoding,
+	}
+
+	if m.clock == nil {
+		m.clock = SystemClock{}
+	}
+	if m.escalation.Store == nil {
+		m.escalation.Store = NewMemoryStore()
+	}
+	if v := m.imageValidator; v != nil && v.Clock == nil {
+		v.Clock = m.clock
+	}
+
+	m.senders.set(mo.AllowedSenders, mo.BlockedSenders, mo.SenderFilter)
+	m.echoes.onConfirmed = mo.OnEchoConfirmed
+	m.echoes.window = mo.EchoWindow
+	m.handlerTimeout = mo.HandlerTimeout
+	m.languageDetector = mo.LanguageDetector
+	m.moderation = mo.ModerationPolicy
+	m.onHandlerTimeout = mo.OnHandlerTimeout
+	m.onVerify = mo.OnVerify
+	m.onKeepAlive = mo.OnKeepAlive
+	m.onWebhookTimings = mo.OnWebhookTimings
+	m.offload = mo.Offload
+	m.correlationIDs = mo.CorrelationIDs
+	m.postbacks.window = mo.PostbackDedupWindow
+	m.instantReplies.appIDs = mo.InstantReplyAppIDs
+	m.echoAppIDs = mo.EchoAppIDs
+	m.captures.rate = mo.CaptureRate
+	m.captures.size = mo.CaptureSize
+	m.maxEventAge = mo.MaxEventAge
+	m.debug.enabled = mo.Debug
+	m.excludeEchoes = mo.ExcludeEchoes
+	m.endpoints = mo.Endpoints
+	if m.endpoints.Name == "" && m.endpoints.GraphURL == "" && m.endpoints.Recording == nil {
+		m.endpoints = ProductionEndpoints()
+	}
+
+	if mo.Analytics != nil {
+		m.analytics = newAnalytics(mo.Analytics)
+	}
+	if mo.Alerts != nil {
+		m.alerts = newAlerter(mo.Alerts)
+	}
+
+	if m.client == nil && mo.Proxy != nil {
+		m.client = newProxyClient(mo.Proxy)
+	}
+	if len(m.graphHosts) > 0 {
+		m.client = m.withFailover(m.httpClient())
+	}
+	if m.endpoints.GraphURL != "" || m.endpoints.Recording != nil {
+		m.client = m.withEndpoints(m.httpClient())
+	}
+	m.client = m.withRateLimits(m.httpClient())
+
+	if len(mo.PSIDHashKey) > 0 {
+		m.psidHasher = NewPSIDHasher(mo.PSIDHashKey)
 	}
 
 	if mo.WebhookURL == "" {
@@ -102,6 +395,7 @@ func New(mo Options) *Messenger {
 	}
 
 	m.verifyHandler = newVerifyHandler(mo.VerifyToken)
+	m.webhookURL = mo.WebhookURL
 	m.mux.HandleFunc(mo.WebhookURL, m.handle)
 
 	return m
@@ -162,42 +456,11 @@ func (m *Messenger) Handler() http.Handler {
 // - Profile Picture
 func (m *Messenger) ProfileByID(id int64, profileFields []string) (Profile, error) {
 	p := Profile{}
-	url := fmt.Sprintf("%v%v", ProfileURL, id)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return p, err
-	}
-
-	fields := strings.Join(profileFields, ",")
-
-	req.URL.RawQuery = "fields=" + fields + "&access_token=" + m.token
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return p, err
-	}
-	defer resp.Body.Close()
-
-	content, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return p, err
-	}
-
-	err = json.Unmarshal(content, &p)
-	if err != nil {
-		return p, err
-	}
-
-	if p == *new(Profile) {
-		qr := QueryResponse{}
-		err = json.Unmarshal(content, &qr)
-		if qr.Error != nil {
-			err = xerrors.Errorf("facebook error: %w", qr.Error)
-		}
-	}
+	endpoint := ProfileURL + strconv.FormatInt(id, 10)
+	fields := url.Values{"fields": {strings.Join(profileFields, ",")}}
 
+	ctx := context.Background()
+	err := graphFailed(ctx, "", m.Graph().Do(ctx, "GET", endpoint, fields, nil, &p))
 	return p, err
 }
 
@@ -210,28 +473,7 @@ func (m *Messenger) GreetingSetting(text string) error {
 		},
 	}
 
-	data, err := json.Marshal(d)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", SendSettingsURL, bytes.NewBuffer(data))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.URL.RawQuery = "access_token=" + m.token
-
-	client := &http.Client{}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	return checkFacebookError(resp.Body)
+	return m.graphCall("POST", SendSettingsURL, d, nil)
 }
 
 // CallToActionsSetting sends settings for Get Started or Persistent Menu
@@ -242,69 +484,103 @@ func (m *Messenger) CallToActionsSetting(state string, actions []CallToActionsIt
 		CallToActions: actions,
 	}
 
-	data, err := json.Marshal(d)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", SendSettingsURL, bytes.NewBuffer(data))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.URL.RawQuery = "access_token=" + m.token
-
-	client := &http.Client{}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	return checkFacebookError(resp.Body)
+	return m.graphCall("POST", SendSettingsURL, d, nil)
 }
 
 // handle is the internal HTTP handler for the webhooks.
 func (m *Messenger) handle(w http.ResponseWriter, r *http.Request) {
+	m.logHandlers()
+
 	if r.Method == "GET" {
-		m.verifyHandler(w, r)
+		m.handleVerify(w, r)
 		return
 	}
 
-	var rec Receive
+	timer := m.startPhases()
+	defer timer.finish()
 
 	// consume a *copy* of the request body
-	body, _ := ioutil.ReadAll(r.Body)
-	r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	buf := m.bodyBuffer()
+	defer m.releaseBodyBuffer(buf)
+	err := m.readBodyTo(buf, r)
+	timer.Read = timer.lap()
+	if err != nil {
+		m.recordError(xerrors.Errorf("could not read request: %w", err))
+		m.noteFailure(AlertDecodeErrors, err)
+		fmt.Println("could not read request:", err)
+		if err == ErrBodyTooLarge {
+			respond(w, http.StatusRequestEntityTooLarge)
+			return
+		}
+		respond(w, http.StatusBadRequest)
+		return
+	}
+	body := buf.Bytes()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	m.capture(r, body)
+
+	if isKeepAlive(body) {
+		m.answerKeepAlive(w, r, body)
+		return
+	}
 
-	err := json.Unmarshal(body, &rec)
+	rec, err := m.DecodeReceive(body)
+	timer.Decode = timer.lap()
 	if err != nil {
 		err = xerrors.Errorf("could not decode response: %w", err)
+		m.recordError(err)
+		m.noteFailure(AlertDecodeErrors, err)
 		fmt.Println(err)
 		fmt.Println("could not decode response:", err)
 		respond(w, http.StatusBadRequest)
 		return
 	}
+	defer rec.Release()
 
-	if rec.Object != "page" {
-		fmt.Println("Object is not page, undefined behaviour. Got", rec.Object)
+	if rec.Object != ObjectPage && rec.Object != ObjectInstagram {
+		fmt.Println("Object is not page or instagram, undefined behaviour. Got", rec.Object)
 		respond(w, http.StatusUnprocessableEntity)
 		return
 	}
 
 	if m.verify {
-		if err := m.checkIntegrity(r); err != nil {
+		err := m.checkIntegrity(r)
+		timer.Verify = timer.lap()
+		if err != nil {
+			m.recordError(xerrors.Errorf("could not verify request: %w", err))
+			m.noteFailure(AlertSignatureFailures, err)
 			fmt.Println("could not verify request:", err)
 			respond(w, http.StatusUnauthorized)
 			return
 		}
 	}
 
-	m.dispatch(rec)
+	// Handlers may keep sending once the request is answered.
+	m.dispatchContext(detach(r.Context()), *rec)
+	timer.Dispatch = timer.lap()
+	timer.dispatched = true
 
 	respond(w, http.StatusAccepted) // We do not return any meaningful response immediately so it should be 202
+	timer.Ack = timer.lap()
+}
+
+// ProcessPayload triggers the handlers of the events in body, a webhook
+// request body, as if it had been received by the webhook. The signature of
+// the payload is not checked, so it must come from a trusted source, such as
+// a development relay.
+func (m *Messenger) ProcessPayload(body []byte) error {
+	rec, err := m.DecodeReceive(body)
+	if err != nil {
+		return xerrors.Errorf("could not decode payload: %w", err)
+	}
+	defer rec.Release()
+
+	if rec.Object != ObjectPage && rec.Object != ObjectInstagram {
+		return xerrors.Errorf("unsupported object %q", rec.Object)
+	}
+
+	m.dispatch(*rec)
+	return nil
 }
 
 func respond(w http.ResponseWriter, code int) {
@@ -314,115 +590,384 @@ func respond(w http.ResponseWriter, code int) {
 
 // checkIntegrity checks the integrity of the requests received
 func (m *Messenger) checkIntegrity(r *http.Request) error {
-	if m.appSecret == "" {
-		return xerrors.New("missing app secret")
-	}
-
-	sigHeader := "X-Hub-Signature"
-	sig := strings.SplitN(r.Header.Get(sigHeader), "=", 2)
-	if len(sig) == 1 {
-		if sig[0] == "" {
-			return xerrors.Errorf("missing %s header", sigHeader)
-		}
-		return xerrors.Errorf("malformed %s header: %v", sigHeader, strings.Join(sig, "="))
-	}
-
-	checkSHA1 := func(body []byte, hash string) error {
-		mac := hmac.New(sha1.New, []byte(m.appSecret))
-		if mac.Write(body); fmt.Sprintf("%x", mac.Sum(nil)) != hash {
-			return xerrors.Errorf("invalid signature: %s", hash)
-		}
-		return nil
-	}
-
 	body, _ := ioutil.ReadAll(r.Body)
 	r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 
-	sigEnc := strings.ToLower(sig[0])
-	sigHash := strings.ToLower(sig[1])
-	switch sigEnc {
-	case "sha1":
-		return checkSHA1(body, sigHash)
-	default:
-		return xerrors.Errorf("unknown %s header encoding, expected sha1: %s", sigHeader, sig[0])
-	}
+	return verifyRequestSignature(m.appSecret, body, r.Header)
 }
 
 // dispatch triggers all of the relevant handlers when a webhook event is received.
 func (m *Messenger) dispatch(r Receive) {
+	m.dispatchContext(context.Background(), r)
+}
+
+// dispatchContext is dispatch with the context the Responses passed to
+// handlers carry, that of the webhook request.
+func (m *Messenger) dispatchContext(ctx context.Context, r Receive) {
+	steps, _ := m.steps()
 	for _, entry := range r.Entry {
+		if m.staleEntry(entry) {
+			continue
+		}
+
 		for _, info := range entry.Messaging {
+			ctx := m.withCorrelationID(ctx)
+			info.surface = surfaceOf(r.Object)
 			a := m.classify(info)
 			if a == UnknownAction {
-				fmt.Println("Unknown action:", info)
+				m.noteUnknown(info.unknown, info.raw)
+				logEvent(ctx, "Unknown action from", m.psidHasher.Hash(info.Sender.ID), info.unknown)
 				continue
 			}
 
-			resp := &Response{
-				to:    Recipient{info.Sender.ID},
-				token: m.token,
+			if m.skipEvent(ctx, steps, a, info) {
+				continue
 			}
 
-			switch a {
-			case TextAction:
-				for _, f := range m.messageHandlers {
-					message := *info.Message
-					message.Sender = info.Sender
-					message.Recipient = info.Recipient
-					message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
-					f(message, resp)
-				}
-			case DeliveryAction:
-				for _, f := range m.deliveryHandlers {
-					f(*info.Delivery, resp)
-				}
-			case ReadAction:
-				for _, f := range m.readHandlers {
-					f(*info.Read, resp)
-				}
-			case PostBackAction:
-				for _, f := range m.postBackHandlers {
-					message := *info.PostBack
-					message.Sender = info.Sender
-					message.Recipient = info.Recipient
-					message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
-					f(message, resp)
-				}
-			case OptInAction:
-				for _, f := range m.optInHandlers {
-					message := *info.OptIn
-					message.Sender = info.Sender
-					message.Recipient = info.Recipient
-					message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
-					f(message, resp)
-				}
-			case ReferralAction:
-				for _, f := range m.referralHandlers {
-					message := *info.ReferralMessage
-					message.Sender = info.Sender
-					message.Recipient = info.Recipient
-					message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
-					f(message, resp)
+			start := m.now()
+			m.runEvent(ctx, a, info)
+			m.stats.observe(start, m.now().Sub(start))
+		}
+
+		m.dispatchStandby(ctx, r.Object, entry)
+		m.dispatchChanges(ctx, entry)
+	}
+}
+
+// eventStep is a step of dispatchContext which can stop an event before its
+// handlers run. Steps with a name are listed in the HandlerTable while
+// enabled.
+type eventStep struct {
+	name    string
+	enabled func() bool
+	skip    func(ctx context.Context, a Action, info MessageInfo) bool
+}
+
+// steps returns the steps of dispatchContext and runHandlers. They are built
+// once, as they read the settings of m when they run.
+func (m *Messenger) steps() ([]eventStep, []messageStep) {
+	m.stepsOnce.Do(func() {
+		m.eventSteps = m.newEventSteps()
+		m.messageSteps = m.newMessageSteps()
+	})
+	return m.eventSteps, m.messageSteps
+}
+
+// newEventSteps returns the steps of dispatchContext, in order.
+func (m *Messenger) newEventSteps() []eventStep {
+	return []eventStep{
+		{
+			name:    "sender filter",
+			enabled: m.senders.enabled,
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				return !m.senders.allows(info.Sender.ID)
+			},
+		},
+		{
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				m.writeTranscript(TranscriptInbound, info.Sender.ID, info, nil)
+				m.recordAnalytics(a, info)
+				return false
+			},
+		},
+		{
+			// Agents answer escalated users, not handlers.
+			name:    "escalated conversations",
+			enabled: m.hasEscalations,
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				return a != PassThreadControlAction && m.isEscalated(info.Sender.ID)
+			},
+		},
+		{
+			name:    "postback dedup",
+			enabled: func() bool { return m.postbacks.window > 0 },
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				return a == PostBackAction && m.duplicatePostback(ctx, info)
+			},
+		},
+		{
+			name:    "maintenance",
+			enabled: m.InMaintenance,
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				return m.answerMaintenance(a, info)
+			},
+		},
+		{
+			name:    "business hours",
+			enabled: func() bool { return m.businessHours != nil },
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				return m.answerOutOfHours(a, info)
+			},
+		},
+		{
+			name:    "echo confirmations",
+			enabled: func() bool { return m.echoes.onConfirmed != nil },
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				return a == TextAction && m.confirmEcho(info)
+			},
+		},
+		{
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				if a == TextAction {
+					m.noteInstantReply(info)
 				}
-			case AccountLinkingAction:
-				for _, f := range m.accountLinkingHandlers {
-					message := *info.AccountLinking
-					message.Sender = info.Sender
-					message.Recipient = info.Recipient
-					message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
-					f(message, resp)
+				return false
+			},
+		},
+		{
+			name:    "foreign echoes",
+			enabled: func() bool { return len(m.echoAppIDs) > 0 },
+			skip: func(ctx context.Context, a Action, info MessageInfo) bool {
+				return a == TextAction && m.foreignEcho(info)
+			},
+		},
+	}
+}
+
+// skipEvent runs the steps of dispatchContext, reporting whether one of
+// them stopped the event.
+func (m *Messenger) skipEvent(ctx context.Context, steps []eventStep, a Action, info MessageInfo) bool {
+	for _, step := range steps {
+		if step.skip(ctx, a, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageStep is a step of runHandlers which can change a message or stop
+// it before its handlers run. Steps are listed in the HandlerTable while
+// enabled.
+type messageStep struct {
+	name    string
+	enabled func() bool
+	stop    func(ctx context.Context, info MessageInfo, message *Message, resp *Response) (bool, error)
+}
+
+// newMessageSteps returns the steps of runHandlers for messages, in order.
+func (m *Messenger) newMessageSteps() []messageStep {
+	return []messageStep{
+		{
+			name:    "echo exclusion",
+			enabled: func() bool { return m.excludeEchoes },
+			stop: func(ctx context.Context, info MessageInfo, message *Message, resp *Response) (bool, error) {
+				if !message.IsEcho {
+					return false, nil
 				}
+				err := m.runEchoHandlers(ctx, *message, info)
+				return m.excludeEchoes, err
+			},
+		},
+		{
+			name:    "transcription",
+			enabled: func() bool { return m.transcriber != nil },
+			stop: func(ctx context.Context, info MessageInfo, message *Message, resp *Response) (bool, error) {
+				m.transcribe(ctx, message)
+				return false, nil
+			},
+		},
+		{
+			name:    "image analysis",
+			enabled: func() bool { return m.imageAnalyzer != nil },
+			stop: func(ctx context.Context, info MessageInfo, message *Message, resp *Response) (bool, error) {
+				m.analyzeImages(ctx, message)
+				return false, nil
+			},
+		},
+		{
+			name:    "intent detection",
+			enabled: func() bool { return m.nlu != nil },
+			stop: func(ctx context.Context, info MessageInfo, message *Message, resp *Response) (bool, error) {
+				m.detectIntent(ctx, message)
+				return false, nil
+			},
+		},
+		{
+			name:    "language detection",
+			enabled: func() bool { return m.languageDetector != nil },
+			stop: func(ctx context.Context, info MessageInfo, message *Message, resp *Response) (bool, error) {
+				m.detectLanguage(message, resp)
+				return false, nil
+			},
+		},
+		{
+			name:    "moderation",
+			enabled: func() bool { return m.moderation != nil },
+			stop: func(ctx context.Context, info MessageInfo, message *Message, resp *Response) (bool, error) {
+				return m.moderateInbound(ctx, message), nil
+			},
+		},
+	}
+}
+
+// runHandlers triggers the handlers of an event. It returns the error of the
+// first handler which was abandoned, see invoke.
+func (m *Messenger) runHandlers(ctx context.Context, a Action, info MessageInfo) error {
+	resp := m.newResponse(Recipient{ID: info.Sender.ID})
+	resp.surface = info.surface
+	resp.ctx = ctx
+
+	var abandoned error
+	run := func(handler func(*Response)) {
+		if err := m.invoke(a, resp, handler); abandoned == nil {
+			abandoned = err
+		}
+	}
+
+	switch a {
+	case TextAction:
+		message := *info.Message
+		message.Sender = info.Sender
+		message.Recipient = info.Recipient
+		message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+		_, steps := m.steps()
+		for _, step := range steps {
+			stop, err := step.stop(ctx, info, &message, resp)
+			if abandoned == nil {
+				abandoned = err
 			}
+			if stop {
+				return abandoned
+			}
+		}
+
+		resp.trackReplies()
+		for _, f := range m.messageHandlers {
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+		if err := m.fallback(message, resp); abandoned == nil {
+			abandoned = err
+		}
+	case DeliveryAction:
+		for _, f := range m.deliveryHandlers {
+			f := f
+			run(func(r *Response) { f(*info.Delivery, r) })
+		}
+	case ReadAction:
+		for _, f := range m.readHandlers {
+			f := f
+			run(func(r *Response) { f(*info.Read, r) })
+		}
+	case PostBackAction:
+		for _, f := range m.postBackHandlers {
+			message := *info.PostBack
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+	case OptInAction:
+		for _, f := range m.optInHandlers {
+			message := *info.OptIn
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+	case ReferralAction:
+		for _, f := range m.referralHandlers {
+			message := *info.ReferralMessage
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+	case AccountLinkingAction:
+		for _, f := range m.accountLinkingHandlers {
+			message := *info.AccountLinking
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+	case PassThreadControlAction:
+		m.ReleaseFromAgent(info.Sender.ID)
+		for _, f := range m.handoverHandlers {
+			message := *info.PassThreadControl
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+	case RequestThreadControlAction:
+		for _, f := range m.threadRequestHandlers {
+			message := *info.RequestThreadControl
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+	case PaymentAction:
+		for _, f := range m.paymentHandlers {
+			message := *info.Payment
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
+		}
+	case PreCheckoutAction:
+		for _, f := range m.preCheckoutHandlers {
+			message := *info.PreCheckout
+			message.Sender = info.Sender
+			message.Recipient = info.Recipient
+			message.Time = time.Unix(info.Timestamp/int64(time.Microsecond), 0)
+			f := f
+			run(func(r *Response) { f(message, r) })
 		}
 	}
+	return abandoned
 }
 
 // Response returns new Response object
 func (m *Messenger) Response(to int64) *Response {
+	return m.newResponse(Recipient{ID: to})
+}
+
+// newResponse creates a Response which reports its sends back to m.
+func (m *Messenger) newResponse(to Recipient) *Response {
 	return &Response{
-		to:    Recipient{to},
-		token: m.token,
+		to:        to,
+		token:     m.token,
+		messenger: m,
+		client:    m.client,
+	}
+}
+
+// beforeSend is called by a Response created by m before it sends msg. The
+// message is not sent if an error is returned.
+func (m *Messenger) beforeSend(ctx context.Context, to Recipient, msg interface{}) error {
+	if err := m.moderateOutbound(to, msg); err != nil {
+		return err
+	}
+	if err := m.validateTemplateImages(msg); err != nil {
+		return err
+	}
+	m.tagForEcho(to, msg)
+	tagCorrelation(ctx, msg)
+	return nil
+}
+
+// afterSend is called by a Response created by m once it has attempted to
+// send msg. Queued messages are reported by FlushOutbox once they were sent
+// or dropped.
+func (m *Messenger) afterSend(to Recipient, msg interface{}, err error) {
+	if xerrors.Is(err, ErrQueued) {
+		return
+	}
+	if err != nil {
+		m.recordError(xerrors.Errorf("could not send message: %w", err))
+		m.noteFailure(AlertSendFailures, err)
+		m.untagForEcho(msg)
 	}
+	m.writeTranscript(TranscriptOutbound, to.ID, msg, err)
 }
 
 // Send will send a textual message to a user. This user must have previously initiated a conversation with the bot.
@@ -432,29 +977,20 @@ func (m *Messenger) Send(to Recipient, message string, messagingType MessagingTy
 
 // SendGeneralMessage will send the GenericTemplate message
 func (m *Messenger) SendGeneralMessage(to Recipient, elements *[]StructuredMessageElement, messagingType MessagingType, tags ...string) error {
-	r := &Response{
-		token: m.token,
-		to:    to,
-	}
+	r := m.newResponse(to)
 	return r.GenericTemplate(elements, messagingType, tags...)
 }
 
 // SendWithReplies sends a textual message to a user, but gives them the option of numerous quick response options.
 func (m *Messenger) SendWithReplies(to Recipient, message string, replies []QuickReply, messagingType MessagingType, tags ...string) error {
-	response := &Response{
-		token: m.token,
-		to:    to,
-	}
+	response := m.newResponse(to)
 
 	return response.TextWithReplies(message, replies, messagingType, tags...)
 }
 
 // Attachment sends an image, sound, video or a regular file to a given recipient.
 func (m *Messenger) Attachment(to Recipient, dataType AttachmentType, url string, messagingType MessagingType, tags ...string) error {
-	response := &Response{
-		token: m.token,
-		to:    to,
-	}
+	response := m.newResponse(to)
 
 	return response.Attachment(dataType, url, messagingType, tags...)
 }
@@ -464,32 +1000,20 @@ func (m *Messenger) EnableChatExtension(homeURL HomeURL) error {
 	wrap := map[string]interface{}{
 		"home_url": homeURL,
 	}
-	data, err := json.Marshal(wrap)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", MessengerProfileURL, bytes.NewBuffer(data))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.URL.RawQuery = "access_token=" + m.token
-
-	client := &http.Client{}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	return m.setMessengerProfile(wrap)
+}
 
-	return checkFacebookError(resp.Body)
+// setMessengerProfile sets the given properties of the messenger profile.
+func (m *Messenger) setMessengerProfile(properties interface{}) error {
+	return m.graphCall("POST", MessengerProfileURL, properties, nil)
 }
 
 // classify determines what type of message a webhook event is.
 func (m *Messenger) classify(info MessageInfo) Action {
+	return actionOf(info)
+}
+
+func actionOf(info MessageInfo) Action {
 	if info.Message != nil {
 		return TextAction
 	} else if info.Delivery != nil {
@@ -504,17 +1028,27 @@ func (m *Messenger) classify(info MessageInfo) Action {
 		return ReferralAction
 	} else if info.AccountLinking != nil {
 		return AccountLinkingAction
+	} else if info.PassThreadControl != nil {
+		return PassThreadControlAction
+	} else if info.RequestThreadControl != nil {
+		return RequestThreadControlAction
+	} else if info.Payment != nil {
+		return PaymentAction
+	} else if info.PreCheckout != nil {
+		return PreCheckoutAction
 	}
 	return UnknownAction
 }
 
-// newVerifyHandler returns a function which can be used to handle webhook verification
-func newVerifyHandler(token string) func(w http.ResponseWriter, r *http.Request) {
-	return func(w http.ResponseWriter, r *http.Request) {
+// newVerifyHandler returns a function which can be used to handle webhook
+// verification, reporting whether the verify token was correct.
+func newVerifyHandler(token string) func(w http.ResponseWriter, r *http.Request) bool {
+	return func(w http.ResponseWriter, r *http.Request) bool {
 		if r.FormValue("hub.verify_token") == token {
 			fmt.Fprintln(w, r.FormValue("hub.challenge"))
-			return
+			return true
 		}
 		fmt.Fprintln(w, "Incorrect verify token.")
+		return false
 	}
 }
@@ -1,10 +1,12 @@
 package messenger
 
 import (
+	"io/ioutil"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMessenger_Classify(t *testing.T) {
@@ -106,7 +108,7 @@ func TestMessenger_Dispatch(t *testing.T) {
 		messages := []MessageInfo{
 			{
 				Sender:    Sender{111},
-				Recipient: Recipient{222},
+				Recipient: Recipient{ID: 222},
 				// 2018-11-24 21:31:51 UTC + 999ms
 				Timestamp: 1543095111999,
 				Message:   &Message{},
@@ -138,7 +140,7 @@ func TestMessenger_Dispatch(t *testing.T) {
 		messages := []MessageInfo{
 			{
 				Sender:    Sender{111},
-				Recipient: Recipient{222},
+				Recipient: Recipient{ID: 222},
 				// 2018-11-24 21:31:51 UTC + 999ms
 				Timestamp: 1543095111999,
 				Delivery:  &Delivery{},
@@ -170,7 +172,7 @@ func TestMessenger_Dispatch(t *testing.T) {
 		messages := []MessageInfo{
 			{
 				Sender:    Sender{111},
-				Recipient: Recipient{222},
+				Recipient: Recipient{ID: 222},
 				// 2018-11-24 21:31:51 UTC + 999ms
 				Timestamp: 1543095111999,
 				Read:      &Read{},
@@ -205,7 +207,7 @@ func TestMessenger_Dispatch(t *testing.T) {
 		messages := []MessageInfo{
 			{
 				Sender:    Sender{111},
-				Recipient: Recipient{222},
+				Recipient: Recipient{ID: 222},
 				// 2018-11-24 21:31:51 UTC + 999ms
 				Timestamp: 1543095111999,
 				PostBack:  &PostBack{},
@@ -240,7 +242,7 @@ func TestMessenger_Dispatch(t *testing.T) {
 		messages := []MessageInfo{
 			{
 				Sender:    Sender{111},
-				Recipient: Recipient{222},
+				Recipient: Recipient{ID: 222},
 				// 2018-11-24 21:31:51 UTC + 999ms
 				Timestamp: 1543095111999,
 				OptIn:     &OptIn{},
@@ -275,7 +277,7 @@ func TestMessenger_Dispatch(t *testing.T) {
 		messages := []MessageInfo{
 			{
 				Sender:    Sender{111},
-				Recipient: Recipient{222},
+				Recipient: Recipient{ID: 222},
 				// 2018-11-24 21:31:51 UTC + 999ms
 				Timestamp:       1543095111999,
 				ReferralMessage: &ReferralMessage{},
@@ -295,3 +297,58 @@ func TestMessenger_Dispatch(t *testing.T) {
 		assertHandlersCalls(t, h, handlersCalls{referral: 3})
 	})
 }
+
+func TestMessenger_ProcessPayload(t *testing.T) {
+	m := New(Options{})
+
+	var texts []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		texts = append(texts, msg.Text)
+	})
+
+	body, err := ioutil.ReadFile("testdata/webhooks/message_text.json")
+	require.NoError(t, err)
+	require.NoError(t, m.ProcessPayload(body))
+	assert.Equal(t, []string{"hello, world!"}, texts)
+
+	assert.Error(t, m.ProcessPayload([]byte(`{"object":"user","entry":[]}`)))
+	assert.Error(t, m.ProcessPayload([]byte(`not json`)))
+}
+
+func TestReferral_Source(t *testing.T) {
+	var missing *Referral
+	assert.False(t, missing.IsFromAd())
+
+	ad := ReferralMessage{Referral: &Referral{Source: ReferralSourceAds}}
+	assert.True(t, ad.IsFromAd())
+	assert.False(t, ad.IsFromShortlink())
+	assert.False(t, ad.IsFromCustomerChat())
+
+	pb := PostBack{Referral: Referral{Source: ReferralSourceShortlink}}
+	assert.True(t, pb.Referral.IsFromShortlink())
+
+	chat := Referral{Source: ReferralSourceCustomerChat}
+	assert.True(t, chat.IsFromCustomerChat())
+}
+
+func TestMessenger_HandleGetStarted(t *testing.T) {
+	m := &Messenger{}
+
+	var payloads []string
+	m.HandleGetStarted(func(p PostBack, r *Response) {
+		payloads = append(payloads, p.Payload)
+	})
+
+	m.dispatch(Receive{
+		Entry: []Entry{
+			{
+				Messaging: []MessageInfo{
+					{Sender: Sender{111}, PostBack: &PostBack{Payload: "BUY"}},
+					{Sender: Sender{111}, PostBack: &PostBack{Payload: GetStartedPayload}},
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, []string{GetStartedPayload}, payloads)
+}
@@ -0,0 +1,143 @@
+package messengertest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+
+	"github.com/paked/messenger"
+)
+
+// graphHost is the host of the Graph API, which GraphServer.Client sends
+// to the fake instead.
+const graphHost = "graph.facebook.com"
+
+// graphVersion matches the version starting the path of Graph API calls.
+var graphVersion = regexp.MustCompile(`^/v[0-9]+\.[0-9]+`)
+
+// GraphRequest is a call made to a GraphServer.
+type GraphRequest struct {
+	Method string
+	// Path is the path of the call without the version of the Graph API,
+	// such as "/me/messages".
+	Path  string
+	Query url.Values
+	Body  []byte
+}
+
+// GraphServer is a fake Graph API running in an httptest.Server, recording
+// the calls made to it. Together with a webhook handler mounted in another
+// httptest.Server, it lets tests run bots end to end without Facebook:
+//
+//	graph := messengertest.NewGraphServer()
+//	defer graph.Close()
+//	client := messenger.New(messenger.Options{HTTPClient: graph.Client()})
+//	registerHandlers(client)
+//	bot := httptest.NewServer(client.Handler())
+//	defer bot.Close()
+//	http.Post(bot.URL, "application/json", bytes.NewReader(payload.Body))
+//	sent := graph.Sent()
+type GraphServer struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []GraphRequest
+	responses map[string]string
+}
+
+// NewGraphServer starts a GraphServer, which answers every call with {}
+// until told otherwise with Respond.
+func NewGraphServer() *GraphServer {
+	g := &GraphServer{responses: make(map[string]string)}
+	g.Server = httptest.NewServer(http.HandlerFunc(g.serve))
+	return g
+}
+
+// Respond sets the body of the answers to the calls to path, without the
+// version of the Graph API, made with method.
+func (g *GraphServer) Respond(method, path, body string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.responses[method+" "+path] = body
+}
+
+// Client returns an HTTP client sending the calls to the Graph API to g,
+// for the HTTPClient of the Messenger under test.
+func (g *GraphServer) Client() *http.Client {
+	target, err := url.Parse(g.URL)
+	if err != nil {
+		panic(err)
+	}
+	return &http.Client{Transport: redirectTransport{target: target, base: g.Server.Client().Transport}}
+}
+
+// Endpoints returns the endpoints profile making the Graph API calls to g,
+// for the Options.Endpoints of the Messenger under test.
+func (g *GraphServer) Endpoints() messenger.Endpoints {
+	return messenger.MockEndpoints(g.URL)
+}
+
+// Requests returns the calls made to g, in order.
+func (g *GraphServer) Requests() []GraphRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]GraphRequest(nil), g.requests...)
+}
+
+// Sent returns the bodies of the messages sent through the Send API, in
+// order.
+func (g *GraphServer) Sent() []string {
+	var sent []string
+	for _, r := range g.Requests() {
+		if r.Method == "POST" && r.Path == "/me/messages" {
+			sent = append(sent, string(r.Body))
+		}
+	}
+	return sent
+}
+
+func (g *GraphServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	path := graphVersion.ReplaceAllString(r.URL.Path, "")
+
+	g.mu.Lock()
+	g.requests = append(g.requests, GraphRequest{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Body:   body,
+	})
+	response, ok := g.responses[r.Method+" "+path]
+	g.mu.Unlock()
+
+	if !ok {
+		response = "{}"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(response))
+}
+
+// redirectTransport sends the requests to the Graph API to target.
+type redirectTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != graphHost {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,83 @@
+package messengertest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paked/messenger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEndToEnd runs a bot mounted in an httptest.Server against a
+// GraphServer, from the verification of its webhook to its replies.
+func TestEndToEnd(t *testing.T) {
+	graph := NewGraphServer()
+	defer graph.Close()
+	graph.Respond("GET", "/1254459154682919", `{"first_name":"Peter"}`)
+
+	client := messenger.New(messenger.Options{
+		Verify:      true,
+		AppSecret:   "secret",
+		VerifyToken: "token",
+		HTTPClient:  graph.Client(),
+	})
+	client.HandleMessage(func(m messenger.Message, r *messenger.Response) {
+		p, err := client.ProfileByID(m.Sender.ID, []string{"first_name"})
+		require.NoError(t, err)
+		require.NoError(t, r.Text("Hello, "+p.FirstName+"!", messenger.ResponseType))
+	})
+
+	bot := httptest.NewServer(client.Handler())
+	defer bot.Close()
+	verify := httptest.NewServer(client.VerifyHandlerFunc())
+	defer verify.Close()
+	admin := httptest.NewServer(client.AdminHandler("admin"))
+	defer admin.Close()
+
+	resp, err := http.Get(verify.URL + "?hub.mode=subscribe&hub.verify_token=token&hub.challenge=42")
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "42\n", string(body))
+
+	p, ok := Find("messenger_message_text")
+	require.True(t, ok)
+	resp, err = p.Deliver(bot.URL, "secret")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	requests := graph.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "first_name", requests[0].Query.Get("fields"))
+	assert.Equal(t, []string{`{"messaging_type":"RESPONSE","recipient":{"id":"1254459154682919"},"message":{"text":"Hello, Peter!"}}`}, graph.Sent())
+
+	// Unsigned payloads are rejected before reaching the handlers.
+	resp, err = p.Deliver(bot.URL, "")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Len(t, graph.Sent(), 1)
+
+	req, err := http.NewRequest("POST", admin.URL+"/send", strings.NewReader("psid=1254459154682919&text=Hi"))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer admin")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"messaging_type":"UPDATE","recipient":{"id":"1254459154682919"},"message":{"text":"Hi"}}`, graph.Sent()[1])
+}
+
+func TestGraphServer_Endpoints(t *testing.T) {
+	graph := NewGraphServer()
+	defer graph.Close()
+
+	client := messenger.New(messenger.Options{Endpoints: graph.Endpoints()})
+	require.NoError(t, client.Response(1254459154682919).Text("hello", messenger.ResponseType))
+	assert.Equal(t, []string{`{"messaging_type":"RESPONSE","recipient":{"id":"1254459154682919"},"message":{"text":"hello"}}`}, graph.Sent())
+}
@@ -0,0 +1,136 @@
+// Package messengertest provides a corpus of anonymized webhook payloads, as
+// sent by Facebook for Messenger and Instagram, a conformance test
+// checking that a webhook handler copes with all of them, and a fake Graph
+// API for end to end tests. Run the conformance test from your own tests
+// after wiring your handlers, to catch regressions when upgrading:
+//
+//	func TestWebhook(t *testing.T) {
+//		client := messenger.New(messenger.Options{AppSecret: "secret", Verify: true})
+//		registerHandlers(client)
+//		messengertest.Conformance(t, client.Handler(), "secret")
+//	}
+package messengertest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/paked/messenger"
+)
+
+//go:embed corpus/*.json
+var corpus embed.FS
+
+// Payload is a recorded webhook payload.
+type Payload struct {
+	// Name describes the payload, such as "instagram_message_text".
+	Name string
+	// Body is the JSON body of the webhook request.
+	Body []byte
+}
+
+// Corpus returns the recorded payloads, sorted by name.
+func Corpus() []Payload {
+	entries, err := corpus.ReadDir("corpus")
+	if err != nil {
+		panic(err)
+	}
+
+	var payloads []Payload
+	for _, e := range entries {
+		body, err := corpus.ReadFile(path.Join("corpus", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		payloads = append(payloads, Payload{
+			Name: strings.TrimSuffix(e.Name(), ".json"),
+			Body: body,
+		})
+	}
+
+	sort.Slice(payloads, func(i, j int) bool { return payloads[i].Name < payloads[j].Name })
+	return payloads
+}
+
+// Request returns a webhook request delivering p, signed with appSecret
+// unless it is empty.
+func (p Payload) Request(appSecret string) *http.Request {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(p.Body))
+	p.sign(req, appSecret)
+	return req
+}
+
+// Deliver posts p to the webhook at url, such as that of an httptest.Server
+// serving the handler of a Messenger, signed with appSecret unless it is
+// empty.
+func (p Payload) Deliver(url, appSecret string) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(p.Body))
+	if err != nil {
+		return nil, err
+	}
+	p.sign(req, appSecret)
+	return http.DefaultClient.Do(req)
+}
+
+// Find returns the payload of the corpus named name.
+func Find(name string) (Payload, bool) {
+	for _, p := range Corpus() {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Payload{}, false
+}
+
+func (p Payload) sign(req *http.Request, appSecret string) {
+	req.Header.Set("Content-Type", "application/json")
+
+	if appSecret != "" {
+		mac := hmac.New(sha256.New, []byte(appSecret))
+		mac.Write(p.Body)
+		req.Header.Set(messenger.SignatureSHA256Header, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+}
+
+// Conformance delivers every payload of the corpus to h, which is usually
+// the handler of a Messenger, signed with appSecret unless it is empty. Each
+// payload is a subtest, failing if h panics or answers with an error.
+func Conformance(t *testing.T, h http.Handler, appSecret string) {
+	t.Helper()
+
+	for _, p := range Corpus() {
+		p := p
+		t.Run(p.Name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("handler panicked: %v", r)
+				}
+			}()
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, p.Request(appSecret))
+
+			code := w.Code
+			// The Messenger reports its status in the body.
+			var status struct {
+				Code int `json:"code"`
+			}
+			if json.Unmarshal(w.Body.Bytes(), &status) == nil && status.Code != 0 {
+				code = status.Code
+			}
+			if code >= 300 {
+				t.Errorf("handler answered %d: %s", code, w.Body.String())
+			}
+		})
+	}
+}
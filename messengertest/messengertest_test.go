@@ -0,0 +1,76 @@
+package messengertest
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/paked/messenger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConformance(t *testing.T) {
+	client := messenger.New(messenger.Options{AppSecret: "secret", Verify: true})
+	client.HandleMessage(func(messenger.Message, *messenger.Response) {})
+	Conformance(t, client.Handler(), "secret")
+}
+
+// TestCorpus checks that the payloads reach the handlers they are meant for.
+func TestCorpus(t *testing.T) {
+	client := messenger.New(messenger.Options{})
+
+	var events []string
+	client.HandleMessage(func(m messenger.Message, r *messenger.Response) {
+		switch {
+		case m.IsEcho:
+			events = append(events, "echo")
+		case m.QuickReply != nil:
+			events = append(events, "quick_reply")
+		case len(m.Attachments) > 0:
+			events = append(events, "attachments")
+		default:
+			events = append(events, "message")
+		}
+	})
+	client.HandleDelivery(func(messenger.Delivery, *messenger.Response) { events = append(events, "delivery") })
+	client.HandleRead(func(messenger.Read, *messenger.Response) { events = append(events, "read") })
+	client.HandlePostBack(func(messenger.PostBack, *messenger.Response) { events = append(events, "postback") })
+	client.HandleOptIn(func(messenger.OptIn, *messenger.Response) { events = append(events, "optin") })
+	client.HandleReferral(func(messenger.ReferralMessage, *messenger.Response) { events = append(events, "referral") })
+	client.HandleAccountLinking(func(messenger.AccountLinking, *messenger.Response) { events = append(events, "account_linking") })
+	client.HandleStandby(func(messenger.StandbyEvent, *messenger.Response) { events = append(events, "standby") })
+
+	expected := map[string][]string{
+		"instagram_message_attachments": {"attachments"},
+		"instagram_message_echo":        {"echo"},
+		"instagram_message_quick_reply": {"quick_reply"},
+		"instagram_message_text":        {"message"},
+		"instagram_postback":            {"postback"},
+		"instagram_reaction":            nil,
+		"instagram_read":                {"read"},
+		"messenger_account_linking":     {"account_linking"},
+		"messenger_batched":             {"message", "read", "message"},
+		"messenger_delivery":            {"delivery"},
+		"messenger_message_attachments": {"attachments"},
+		"messenger_message_echo":        {"echo"},
+		"messenger_message_location":    {"attachments"},
+		"messenger_message_quick_reply": {"quick_reply"},
+		"messenger_message_text":        {"message"},
+		"messenger_optin":               {"optin"},
+		"messenger_pass_thread_control": nil,
+		"messenger_postback":            {"postback"},
+		"messenger_reaction":            nil,
+		"messenger_read":                {"read"},
+		"messenger_referral":            {"referral"},
+		"messenger_standby":             {"standby"},
+	}
+
+	payloads := Corpus()
+	assert.Len(t, payloads, len(expected))
+	for _, p := range payloads {
+		events = nil
+		client.Handler().ServeHTTP(httptest.NewRecorder(), p.Request(""))
+		assert.Equal(t, expected[p.Name], events, p.Name)
+		assert.True(t, strings.HasPrefix(p.Name, "messenger_") || strings.HasPrefix(p.Name, "instagram_"), p.Name)
+	}
+}
@@ -0,0 +1,113 @@
+package messenger
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrContentBlocked is returned when sending a message which the
+// ModerationPolicy set in Options blocks.
+var ErrContentBlocked = xerrors.New("content blocked by moderation policy")
+
+// ModerationVerdict is the decision of a ModerationPolicy about a message.
+type ModerationVerdict int
+
+const (
+	// ModerationAllow lets the message through.
+	ModerationAllow ModerationVerdict = iota
+	// ModerationFlag lets the message through, marking incoming messages as
+	// Message.Flagged.
+	ModerationFlag
+	// ModerationBlock stops the message: incoming messages are not passed to
+	// handlers, and sends fail with ErrContentBlocked.
+	ModerationBlock
+)
+
+// ModerationPolicy screens the messages exchanged with users, such as for
+// profanity. Inbound is invoked for every message before any MessageHandler
+// runs, once its audio is transcribed. Outbound is invoked before every
+// message is sent, with its text, the titles of its quick replies, elements
+// and buttons, separated by newlines.
+type ModerationPolicy interface {
+	Inbound(msg Message) (ModerationVerdict, error)
+	Outbound(to Recipient, text string) (ModerationVerdict, error)
+}
+
+// moderateInbound runs the configured ModerationPolicy over msg, and reports
+// whether it is blocked. Messages which cannot be screened are let through.
+func (m *Messenger) moderateInbound(ctx context.Context, msg *Message) bool {
+	if m.moderation == nil {
+		return false
+	}
+
+	verdict, err := m.moderation.Inbound(*msg)
+	if err != nil {
+		logEvent(ctx, "could not moderate message:", err)
+		return false
+	}
+
+	msg.Flagged = verdict == ModerationFlag
+	return verdict == ModerationBlock
+}
+
+// moderateOutbound runs the configured ModerationPolicy over msg before it
+// is sent to to. Messages which cannot be screened are not sent.
+func (m *Messenger) moderateOutbound(to Recipient, msg interface{}) error {
+	if m.moderation == nil {
+		return nil
+	}
+
+	text := outboundText(msg)
+	if text == "" {
+		return nil
+	}
+
+	verdict, err := m.moderation.Outbound(to, text)
+	if err != nil {
+		return xerrors.Errorf("could not moderate message: %w", err)
+	}
+	if verdict == ModerationBlock {
+		return ErrContentBlocked
+	}
+	return nil
+}
+
+// outboundText returns the text shown to users by msg.
+func outboundText(msg interface{}) string {
+	var texts []string
+	add := func(s string) {
+		if s != "" {
+			texts = append(texts, s)
+		}
+	}
+	addButtons := func(buttons []StructuredMessageButton) {
+		for _, b := range buttons {
+			add(b.Title)
+		}
+	}
+
+	switch msg := msg.(type) {
+	case *SendMessage:
+		add(msg.Message.Text)
+		for _, qr := range msg.Message.QuickReplies {
+			add(qr.Title)
+		}
+	case *SendStructuredMessage:
+		p := msg.Message.Attachment.Payload
+		add(p.Text)
+		if p.Elements != nil {
+			for _, e := range *p.Elements {
+				add(e.Title)
+				add(e.Subtitle)
+				addButtons(e.Buttons)
+			}
+		}
+		if p.Buttons != nil {
+			addButtons(*p.Buttons)
+		}
+	}
+
+	return strings.Join(texts, "\n")
+}
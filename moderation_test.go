@@ -0,0 +1,78 @@
+package messenger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+// wordPolicy blocks the messages containing blocked and flags those
+// containing flagged.
+type wordPolicy struct {
+	blocked, flagged string
+	outbound         []string
+}
+
+func (p *wordPolicy) verdict(text string) ModerationVerdict {
+	switch {
+	case strings.Contains(text, p.blocked):
+		return ModerationBlock
+	case strings.Contains(text, p.flagged):
+		return ModerationFlag
+	}
+	return ModerationAllow
+}
+
+func (p *wordPolicy) Inbound(msg Message) (ModerationVerdict, error) {
+	return p.verdict(msg.Text), nil
+}
+
+func (p *wordPolicy) Outbound(to Recipient, text string) (ModerationVerdict, error) {
+	p.outbound = append(p.outbound, text)
+	return p.verdict(text), nil
+}
+
+func TestMessenger_ModerationInbound(t *testing.T) {
+	for _, tt := range []struct {
+		policy  *wordPolicy
+		handled bool
+		flagged bool
+	}{
+		{policy: &wordPolicy{blocked: "darn", flagged: "heck"}, handled: true},
+		{policy: &wordPolicy{blocked: "darn", flagged: "world"}, handled: true, flagged: true},
+		{policy: &wordPolicy{blocked: "hello", flagged: "world"}},
+	} {
+		m := New(Options{ModerationPolicy: tt.policy})
+
+		var handled []Message
+		m.HandleMessage(func(msg Message, r *Response) {
+			handled = append(handled, msg)
+		})
+		serveFixture(t, m, "message_text.json")
+
+		if !tt.handled {
+			assert.Empty(t, handled)
+			continue
+		}
+		require.Len(t, handled, 1)
+		assert.Equal(t, tt.flagged, handled[0].Flagged)
+	}
+}
+
+func TestMessenger_ModerationOutbound(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	policy := &wordPolicy{blocked: "darn", flagged: "heck"}
+	m := New(Options{ModerationPolicy: policy, HTTPClient: graph.client()})
+	r := m.Response(fixturePSID)
+
+	require.NoError(t, r.Text("what the heck", ResponseType))
+	err := r.TextWithReplies("pick one", []QuickReply{{ContentType: QuickReplyText, Title: "darn it", Payload: "X"}}, ResponseType)
+	assert.True(t, xerrors.Is(err, ErrContentBlocked))
+	require.NoError(t, r.SenderAction("typing_on"))
+
+	assert.Equal(t, 2, graph.count())
+	assert.Equal(t, []string{"what the heck", "pick one\ndarn it"}, policy.outbound)
+}
@@ -0,0 +1,55 @@
+package messenger
+
+import "context"
+
+// Intent is what a user meant by a message, as understood by an NLU.
+type Intent struct {
+	// Name is the name of the intent, such as "book_flight".
+	Name string
+	// Confidence is between 0 and 1.
+	Confidence float64
+	// Entities are the parameters extracted from the text, by name.
+	Entities map[string]interface{}
+}
+
+// NLU detects the intent of incoming text messages. It is invoked for every
+// message with text before any MessageHandler runs, making the result
+// available in Message.Intent. Adapters for common services live in the nlu
+// package.
+type NLU interface {
+	Parse(msg Message) (Intent, error)
+}
+
+// NLUFunc is an adapter to allow the use of ordinary functions as an NLU.
+type NLUFunc func(msg Message) (Intent, error)
+
+// Parse calls f(msg).
+func (f NLUFunc) Parse(msg Message) (Intent, error) {
+	return f(msg)
+}
+
+// detectIntent runs the configured NLU over the text of msg.
+func (m *Messenger) detectIntent(ctx context.Context, msg *Message) {
+	if m.nlu == nil || msg.Text == "" {
+		return
+	}
+
+	intent, err := m.nlu.Parse(*msg)
+	if err != nil {
+		logEvent(ctx, "could not detect intent:", err)
+		return
+	}
+
+	msg.Intent = &intent
+}
+
+// HandleIntent adds a new MessageHandler to the Messenger which will be
+// triggered for every message whose intent, as detected by the NLU set in
+// Options, is name.
+func (m *Messenger) HandleIntent(name string, f MessageHandler) {
+	m.HandleMessage(func(msg Message, r *Response) {
+		if msg.Intent != nil && msg.Intent.Name == name {
+			f(msg, r)
+		}
+	})
+}
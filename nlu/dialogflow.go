@@ -0,0 +1,137 @@
+package nlu
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/paked/messenger"
+)
+
+// DialogflowEndpoint is the global endpoint of the Dialogflow API.
+const DialogflowEndpoint = "https://dialogflow.googleapis.com"
+
+// DialogflowES detects intents with a Dialogflow ES agent. Each user gets
+// their own Dialogflow session, keyed by their PSID, so that contexts carry
+// over between messages.
+type DialogflowES struct {
+	// ProjectID is the Google Cloud project of the agent.
+	ProjectID string
+	// LanguageCode is the language of the messages. Defaults to "en".
+	LanguageCode string
+	// Token authenticates the requests.
+	Token TokenSource
+	// Client is used for the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Endpoint overrides DialogflowEndpoint.
+	Endpoint string
+}
+
+// Parse detects the intent of msg.
+func (d *DialogflowES) Parse(msg messenger.Message) (messenger.Intent, error) {
+	url := fmt.Sprintf("%s/v2/projects/%s/agent/sessions/%s:detectIntent",
+		endpoint(d.Endpoint, ""), d.ProjectID, strconv.FormatInt(msg.Sender.ID, 10))
+
+	body := map[string]interface{}{
+		"queryInput": map[string]interface{}{
+			"text": map[string]string{
+				"text":         msg.Text,
+				"languageCode": languageCode(d.LanguageCode),
+			},
+		},
+	}
+
+	var resp struct {
+		QueryResult struct {
+			Intent struct {
+				DisplayName string `json:"displayName"`
+			} `json:"intent"`
+			IntentDetectionConfidence float64                `json:"intentDetectionConfidence"`
+			Parameters                map[string]interface{} `json:"parameters"`
+		} `json:"queryResult"`
+	}
+	if err := postJSON(d.Client, url, d.Token, body, &resp); err != nil {
+		return messenger.Intent{}, err
+	}
+
+	return messenger.Intent{
+		Name:       resp.QueryResult.Intent.DisplayName,
+		Confidence: resp.QueryResult.IntentDetectionConfidence,
+		Entities:   resp.QueryResult.Parameters,
+	}, nil
+}
+
+// DialogflowCX detects intents with a Dialogflow CX agent. Each user gets
+// their own Dialogflow session, keyed by their PSID.
+type DialogflowCX struct {
+	// ProjectID is the Google Cloud project of the agent.
+	ProjectID string
+	// Location is the region of the agent, such as "global" or
+	// "europe-west1".
+	Location string
+	// AgentID is the ID of the agent.
+	AgentID string
+	// LanguageCode is the language of the messages. Defaults to "en".
+	LanguageCode string
+	// Token authenticates the requests.
+	Token TokenSource
+	// Client is used for the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// Endpoint overrides the regional endpoint of Location.
+	Endpoint string
+}
+
+// Parse detects the intent of msg.
+func (d *DialogflowCX) Parse(msg messenger.Message) (messenger.Intent, error) {
+	url := fmt.Sprintf("%s/v3/projects/%s/locations/%s/agents/%s/sessions/%s:detectIntent",
+		endpoint(d.Endpoint, d.Location), d.ProjectID, d.Location, d.AgentID,
+		strconv.FormatInt(msg.Sender.ID, 10))
+
+	body := map[string]interface{}{
+		"queryInput": map[string]interface{}{
+			"text": map[string]string{
+				"text": msg.Text,
+			},
+			"languageCode": languageCode(d.LanguageCode),
+		},
+	}
+
+	var resp struct {
+		QueryResult struct {
+			Match struct {
+				Intent struct {
+					DisplayName string `json:"displayName"`
+				} `json:"intent"`
+				Confidence float64 `json:"confidence"`
+			} `json:"match"`
+			Parameters map[string]interface{} `json:"parameters"`
+		} `json:"queryResult"`
+	}
+	if err := postJSON(d.Client, url, d.Token, body, &resp); err != nil {
+		return messenger.Intent{}, err
+	}
+
+	return messenger.Intent{
+		Name:       resp.QueryResult.Match.Intent.DisplayName,
+		Confidence: resp.QueryResult.Match.Confidence,
+		Entities:   resp.QueryResult.Parameters,
+	}, nil
+}
+
+// endpoint returns the Dialogflow endpoint serving location.
+func endpoint(override, location string) string {
+	if override != "" {
+		return override
+	}
+	if location == "" || location == "global" {
+		return DialogflowEndpoint
+	}
+	return "https://" + location + "-dialogflow.googleapis.com"
+}
+
+func languageCode(code string) string {
+	if code == "" {
+		return "en"
+	}
+	return code
+}
@@ -0,0 +1,61 @@
+// Package nlu provides messenger.NLU adapters for external natural language
+// understanding services, for bots which outgrew the built-in NLP.
+package nlu
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// TokenSource returns the OAuth access token used to authenticate with a
+// service, allowing it to be refreshed between requests.
+type TokenSource func() (string, error)
+
+// StaticToken is a TokenSource always returning token.
+func StaticToken(token string) TokenSource {
+	return func() (string, error) {
+		return token, nil
+	}
+}
+
+// postJSON posts body to url and decodes the response into v.
+func postJSON(client *http.Client, url string, token TokenSource, body, v interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if token != nil {
+		t, err := token()
+		if err != nil {
+			return xerrors.Errorf("could not get token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+t)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		content, _ := ioutil.ReadAll(resp.Body)
+		return xerrors.Errorf("unexpected status %d: %s", resp.StatusCode, content)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
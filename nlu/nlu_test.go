@@ -0,0 +1,97 @@
+package nlu
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/paked/messenger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeService answers every request with resp, recording the request.
+func fakeService(t *testing.T, resp string, req *map[string]interface{}, path *string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*path = r.URL.String()
+		if r.Header.Get("Authorization") != "" {
+			*path += " " + r.Header.Get("Authorization")
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(req))
+		w.Write([]byte(resp))
+	}))
+}
+
+var msg = messenger.Message{Sender: messenger.Sender{ID: 111}, Mid: "mid.1", Text: "book a flight to Paris"}
+
+func TestDialogflowES(t *testing.T) {
+	var (
+		req  map[string]interface{}
+		path string
+	)
+	srv := fakeService(t, `{"queryResult":{"intent":{"displayName":"book_flight"},"intentDetectionConfidence":0.8,"parameters":{"city":"Paris"}}}`, &req, &path)
+	defer srv.Close()
+
+	d := &DialogflowES{ProjectID: "proj", Token: StaticToken("tok"), Endpoint: srv.URL}
+	intent, err := d.Parse(msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, messenger.Intent{Name: "book_flight", Confidence: 0.8, Entities: map[string]interface{}{"city": "Paris"}}, intent)
+	assert.Equal(t, "/v2/projects/proj/agent/sessions/111:detectIntent Bearer tok", path)
+	assert.Equal(t, map[string]interface{}{
+		"queryInput": map[string]interface{}{
+			"text": map[string]interface{}{"text": msg.Text, "languageCode": "en"},
+		},
+	}, req)
+}
+
+func TestDialogflowCX(t *testing.T) {
+	var (
+		req  map[string]interface{}
+		path string
+	)
+	srv := fakeService(t, `{"queryResult":{"match":{"intent":{"displayName":"book_flight"},"confidence":0.7},"parameters":{"city":"Paris"}}}`, &req, &path)
+	defer srv.Close()
+
+	d := &DialogflowCX{ProjectID: "proj", Location: "europe-west1", AgentID: "agent", LanguageCode: "fr", Token: StaticToken("tok"), Endpoint: srv.URL}
+	intent, err := d.Parse(msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, messenger.Intent{Name: "book_flight", Confidence: 0.7, Entities: map[string]interface{}{"city": "Paris"}}, intent)
+	assert.Equal(t, "/v3/projects/proj/locations/europe-west1/agents/agent/sessions/111:detectIntent Bearer tok", path)
+	assert.Equal(t, "fr", req["queryInput"].(map[string]interface{})["languageCode"])
+	assert.Equal(t, "https://europe-west1-dialogflow.googleapis.com", endpoint("", "europe-west1"))
+	assert.Equal(t, DialogflowEndpoint, endpoint("", "global"))
+}
+
+func TestRasa(t *testing.T) {
+	var (
+		req  map[string]interface{}
+		path string
+	)
+	srv := fakeService(t, `{"intent":{"name":"book_flight","confidence":0.95},"entities":[{"entity":"city","value":"Paris"},{"entity":"city","value":"Rome"}]}`, &req, &path)
+	defer srv.Close()
+
+	ra := &Rasa{URL: srv.URL + "/", Token: "secret"}
+	intent, err := ra.Parse(msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, messenger.Intent{Name: "book_flight", Confidence: 0.95, Entities: map[string]interface{}{"city": "Paris"}}, intent)
+	assert.Equal(t, "/model/parse?token=secret", path)
+	assert.Equal(t, map[string]interface{}{"text": msg.Text, "message_id": "mid.1", "sender_id": "111"}, req)
+}
+
+func TestRasaError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no model", http.StatusConflict)
+	}))
+	defer srv.Close()
+
+	_, err := (&Rasa{URL: srv.URL}).Parse(msg)
+	assert.Error(t, err)
+}
+
+var _ messenger.NLU = (*Rasa)(nil)
+var _ messenger.NLU = (*DialogflowES)(nil)
+var _ messenger.NLU = (*DialogflowCX)(nil)
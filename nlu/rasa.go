@@ -0,0 +1,61 @@
+package nlu
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/paked/messenger"
+)
+
+// Rasa detects intents with the HTTP API of a Rasa server.
+type Rasa struct {
+	// URL is the base URL of the server, such as "http://localhost:5005".
+	URL string
+	// Token, if set, is the token the server was started with.
+	Token string
+	// Client is used for the requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Parse detects the intent of msg. When an entity is found several times,
+// the first value is kept.
+func (ra *Rasa) Parse(msg messenger.Message) (messenger.Intent, error) {
+	u := strings.TrimSuffix(ra.URL, "/") + "/model/parse"
+	if ra.Token != "" {
+		u += "?token=" + url.QueryEscape(ra.Token)
+	}
+
+	body := map[string]string{
+		"text":       msg.Text,
+		"message_id": msg.Mid,
+		"sender_id":  strconv.FormatInt(msg.Sender.ID, 10),
+	}
+
+	var resp struct {
+		Intent struct {
+			Name       string  `json:"name"`
+			Confidence float64 `json:"confidence"`
+		} `json:"intent"`
+		Entities []struct {
+			Entity string      `json:"entity"`
+			Value  interface{} `json:"value"`
+		} `json:"entities"`
+	}
+	if err := postJSON(ra.Client, u, nil, body, &resp); err != nil {
+		return messenger.Intent{}, err
+	}
+
+	intent := messenger.Intent{
+		Name:       resp.Intent.Name,
+		Confidence: resp.Intent.Confidence,
+		Entities:   make(map[string]interface{}),
+	}
+	for _, e := range resp.Entities {
+		if _, ok := intent.Entities[e.Entity]; !ok {
+			intent.Entities[e.Entity] = e.Value
+		}
+	}
+	return intent, nil
+}
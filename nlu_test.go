@@ -0,0 +1,40 @@
+package messenger
+
+import (
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_HandleIntent(t *testing.T) {
+	m := New(Options{
+		NLU: NLUFunc(func(msg Message) (Intent, error) {
+			if strings.HasPrefix(msg.Text, "hello") {
+				return Intent{Name: "greet", Confidence: 0.9}, nil
+			}
+			return Intent{Name: "unknown"}, nil
+		}),
+	})
+
+	var greeted, booked []Message
+	m.HandleIntent("greet", func(msg Message, r *Response) {
+		greeted = append(greeted, msg)
+	})
+	m.HandleIntent("book_flight", func(msg Message, r *Response) {
+		booked = append(booked, msg)
+	})
+
+	f, err := os.Open("testdata/webhooks/message_text.json")
+	require.NoError(t, err)
+	defer f.Close()
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", f))
+
+	require.Len(t, greeted, 1)
+	assert.Equal(t, "greet", greeted[0].Intent.Name)
+	assert.Equal(t, 0.9, greeted[0].Intent.Confidence)
+	assert.Empty(t, booked)
+}
@@ -0,0 +1,96 @@
+package messenger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultOffloadText introduces the link to an offloaded message.
+const DefaultOffloadText = "This message is too long to be shown here."
+
+// DefaultOffloadButtonTitle is the title of the button opening an offloaded
+// message.
+const DefaultOffloadButtonTitle = "Read it"
+
+// ObjectStore stores files and returns the URL they can be downloaded from,
+// such as a bucket of a cloud provider.
+type ObjectStore interface {
+	Put(ctx context.Context, name, contentType string, data []byte) (string, error)
+}
+
+// ObjectStoreFunc is an adapter to allow the use of ordinary functions as an
+// ObjectStore.
+type ObjectStoreFunc func(ctx context.Context, name, contentType string, data []byte) (string, error)
+
+// Put calls f(ctx, name, contentType, data).
+func (f ObjectStoreFunc) Put(ctx context.Context, name, contentType string, data []byte) (string, error) {
+	return f(ctx, name, contentType, data)
+}
+
+// OffloadPolicy sends text messages longer than MaxTextLength as a file
+// instead of failing with a FieldTooLongError.
+type OffloadPolicy struct {
+	// ObjectStore, if set, stores the text, which is sent as a button
+	// template linking to it, keeping the quick replies, messaging type and
+	// tag of the message. Otherwise the text is uploaded to Facebook as a
+	// file attachment.
+	ObjectStore ObjectStore
+	// Text is the text of the button template. Defaults to
+	// DefaultOffloadText.
+	Text string
+	// ButtonTitle is the title of the button of the button template.
+	// Defaults to DefaultOffloadButtonTitle.
+	ButtonTitle string
+}
+
+// shouldOffload reports whether msg is too long to be sent and the Messenger
+// offloads such messages.
+func (m *Messenger) shouldOffload(msg interface{}) bool {
+	sm, ok := msg.(*SendMessage)
+	return ok && m.offload != nil && TextLength(sm.Message.Text) > MaxTextLength
+}
+
+// offload sends the text of msg as a file, according to the OffloadPolicy.
+func (r *Response) offload(msg *SendMessage) error {
+	m := r.messenger
+	if err := m.moderateOutbound(r.to, msg); err != nil {
+		m.afterSend(r.to, msg, err)
+		return err
+	}
+
+	data := []byte(msg.Message.Text)
+	name := fmt.Sprintf("message-%d.txt", m.now().UnixNano())
+
+	p := m.offload
+	if p.ObjectStore == nil {
+		return r.AttachmentData(FileAttachment, name, bytes.NewReader(data))
+	}
+
+	url, err := p.ObjectStore.Put(r.Context(), name, "text/plain; charset=utf-8", data)
+	if err != nil {
+		err = xerrors.Errorf("could not offload message: %w", err)
+		m.afterSend(r.to, msg, err)
+		return err
+	}
+
+	text, title := p.Text, p.ButtonTitle
+	if text == "" {
+		text = DefaultOffloadText
+	}
+	if title == "" {
+		title = DefaultOffloadButtonTitle
+	}
+
+	attachment := &StructuredMessageAttachment{
+		Type: "template",
+		Payload: StructuredMessagePayload{
+			TemplateType: "button",
+			Text:         text,
+			Buttons:      &[]StructuredMessageButton{{Type: "web_url", URL: url, Title: title}},
+		},
+	}
+	return r.AttachmentWithReplies(attachment, msg.Message.QuickReplies, msg.MessagingType, msg.Tag)
+}
@@ -0,0 +1,68 @@
+package messenger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_OffloadObjectStore(t *testing.T) {
+	var stored []string
+	graph := newFakeGraph(`{}`)
+	m := New(Options{
+		HTTPClient: graph.client(),
+		Clock:      newFakeClock(),
+		Offload: &OffloadPolicy{
+			ObjectStore: ObjectStoreFunc(func(ctx context.Context, name, contentType string, data []byte) (string, error) {
+				stored = append(stored, string(data))
+				return "https://example.com/" + name, nil
+			}),
+		},
+	})
+	r := m.Response(fixturePSID)
+
+	long := strings.Repeat("a", MaxTextLength+1)
+	replies := []QuickReply{{ContentType: QuickReplyText, Title: "OK", Payload: "OK"}}
+	require.NoError(t, r.TextWithReplies(long, replies, ResponseType))
+	require.NoError(t, r.Text("short", ResponseType))
+
+	assert.Equal(t, []string{long}, stored)
+	require.Equal(t, 2, graph.count())
+	assert.JSONEq(t, `{
+		"messaging_type": "RESPONSE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {
+			"attachment": {"type": "template", "payload": {
+				"template_type": "button",
+				"text": "This message is too long to be shown here.",
+				"buttons": [{"type": "web_url", "url": "https://example.com/message-1543095111000000000.txt", "title": "Read it"}]
+			}},
+			"quick_replies": [{"content_type": "text", "title": "OK", "payload": "OK"}]
+		}
+	}`, graph.bodies[0])
+	assert.JSONEq(t, `{"messaging_type":"RESPONSE","recipient":{"id":"1254459154682919"},"message":{"text":"short"}}`, graph.bodies[1])
+}
+
+func TestResponse_OffloadAttachment(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client(), Offload: &OffloadPolicy{}})
+
+	long := strings.Repeat("a", MaxTextLength+1)
+	require.NoError(t, m.Response(fixturePSID).Text(long, ResponseType))
+
+	require.Equal(t, 1, graph.count())
+	assert.True(t, strings.HasPrefix(graph.requests[0].Header.Get("Content-Type"), "multipart/form-data"))
+	assert.Contains(t, graph.bodies[0], long)
+}
+
+func TestResponse_TooLongWithoutOffload(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	err := m.Response(fixturePSID).Text(strings.Repeat("a", MaxTextLength+1), ResponseType)
+	assert.IsType(t, &FieldTooLongError{}, err)
+	assert.Equal(t, 0, graph.count())
+}
@@ -0,0 +1,42 @@
+package messenger
+
+// OneTimeNotificationType is the type of the template asking users whether
+// they want to be notified once, and of the OptIn of those who accept.
+const OneTimeNotificationType = "one_time_notif_req"
+
+// OneTimeNotificationRequest is the payload of a one-time notification
+// request template.
+type OneTimeNotificationRequest struct {
+	TemplateType string `json:"template_type"`
+	// Title is what the user is asked to be notified about, up to 65
+	// characters.
+	Title string `json:"title"`
+	// Payload is sent back in the OptIn of users who accept.
+	Payload string `json:"payload"`
+}
+
+// OneTimeNotificationRequest asks the user whether they want to be notified
+// once about title, such as when an item is back in stock. Users who accept
+// trigger an OptIn of type OneTimeNotificationType whose token can be used
+// once, outside of the 24 hour window, as a Recipient with
+// OneTimeNotifToken.
+func (r *Response) OneTimeNotificationRequest(title, payload string) error {
+	return r.templateMessage(OneTimeNotificationRequest{
+		TemplateType: OneTimeNotificationType,
+		Title:        title,
+		Payload:      payload,
+	}, ResponseType)
+}
+
+// IsOneTimeNotification reports whether the user accepted a one-time
+// notification request.
+func (o OptIn) IsOneTimeNotification() bool {
+	return o.Type == OneTimeNotificationType && o.OneTimeNotifToken != ""
+}
+
+// OneTimeNotification returns a Response which sends to the user who gave
+// token, in the OptIn of a one-time notification request. Only one message
+// can be sent with a token.
+func (m *Messenger) OneTimeNotification(token string) *Response {
+	return m.newResponse(Recipient{OneTimeNotifToken: token})
+}
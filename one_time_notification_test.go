@@ -0,0 +1,42 @@
+package messenger
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_OneTimeNotificationRequest(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	require.NoError(t, m.Response(fixturePSID).OneTimeNotificationRequest("Back in stock", "SHOES-42"))
+	require.Equal(t, 1, graph.count())
+	assert.JSONEq(t, `{
+		"messaging_type": "RESPONSE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {"attachment": {"type": "template", "payload": {
+			"template_type": "one_time_notif_req",
+			"title": "Back in stock",
+			"payload": "SHOES-42"
+		}}}
+	}`, graph.bodies[0])
+
+	var tokens []string
+	m.HandleOptIn(func(o OptIn, r *Response) {
+		if o.IsOneTimeNotification() {
+			tokens = append(tokens, o.OneTimeNotifToken)
+		}
+	})
+	webhook := `{"object":"page","entry":[{"id":"1067280970047460","time":1543095111999,"messaging":[{"sender":{"id":"1254459154682919"},"recipient":{"id":"1067280970047460"},"timestamp":1543095111999,"optin":{"type":"one_time_notif_req","payload":"SHOES-42","one_time_notif_token":"otn-token"}}]}]}`
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(webhook)))
+	serveFixture(t, m, "optin.json")
+	require.Equal(t, []string{"otn-token"}, tokens)
+
+	require.NoError(t, m.OneTimeNotification(tokens[0]).Text("Your shoes are back!", ""))
+	require.Equal(t, 2, graph.count())
+	assert.JSONEq(t, `{"recipient":{"one_time_notif_token":"otn-token"},"message":{"text":"Your shoes are back!"}}`, graph.bodies[1])
+}
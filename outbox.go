@@ -0,0 +1,283 @@
+package messenger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/paked/messenger/graph"
+	"golang.org/x/xerrors"
+)
+
+// DefaultOutboxRetryInterval is how often queued messages are retried when
+// the Outbox does not set its own interval.
+const DefaultOutboxRetryInterval = 30 * time.Second
+
+// ErrServiceUnavailable is returned when Facebook answers with a server
+// error.
+var ErrServiceUnavailable = graph.ErrServiceUnavailable
+
+// ErrQueued is returned by sends which could not be made right away and were
+// queued in the Outbox, to be retried later.
+var ErrQueued = xerrors.New("message queued for retry")
+
+// outboxPrefix prefixes the keys of queued messages in the Store.
+const outboxPrefix = "outbox:"
+
+// Outbox persists messages which could not be sent because Facebook was
+// unavailable, and retries them in order until they are sent. Once a
+// message to a user is queued, further messages to them are queued behind
+// it so that they arrive in order.
+type Outbox struct {
+	// Store persists the queued messages. Use a durable Store, such as a
+	// FileStore, for messages to survive restarts.
+	Store Store
+	// RetryInterval is how often queued messages are retried. Defaults to
+	// DefaultOutboxRetryInterval.
+	RetryInterval time.Duration
+	// MaxAttempts, if set, is how many times a message is tried before it is
+	// dropped.
+	MaxAttempts int
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewOutbox creates an Outbox persisting messages in store.
+func NewOutbox(store Store) *Outbox {
+	return &Outbox{Store: store}
+}
+
+// outboxEntry is a queued message.
+type outboxEntry struct {
+	Recipient Recipient       `json:"recipient"`
+	Message   json.RawMessage `json:"message"`
+	Attempts  int             `json:"attempts"`
+	Queued    time.Time       `json:"queued"`
+}
+
+func outboxKey(to Recipient) string {
+	return outboxPrefix + to.String() + ":"
+}
+
+// enqueue persists an encoded message to be retried.
+func (o *Outbox) enqueue(to Recipient, data []byte, attempts int, now time.Time) error {
+	o.mu.Lock()
+	o.seq++
+	seq := o.seq
+	o.mu.Unlock()
+
+	e, err := json.Marshal(outboxEntry{
+		Recipient: to,
+		Message:   data,
+		Attempts:  attempts,
+		Queued:    now,
+	})
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s%020d-%09d", outboxKey(to), now.UnixNano(), seq)
+	return o.Store.Set(key, e)
+}
+
+// pending reports whether messages to a recipient are waiting.
+func (o *Outbox) pending(to Recipient) (bool, error) {
+	keys, err := o.Store.Keys(outboxKey(to))
+	return len(keys) > 0, err
+}
+
+// isRetriable reports whether a failed send may succeed later: network
+// errors, server errors and Facebook's temporary API errors.
+func isRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if xerrors.Is(err, ErrServiceUnavailable) {
+		return true
+	}
+
+	var qe *QueryError
+	if xerrors.As(err, &qe) {
+		// Unknown and service errors are temporary.
+		return qe.Code == 1 || qe.Code == 2
+	}
+
+	var ne net.Error
+	return xerrors.As(err, &ne)
+}
+
+// send sends a message right away, or through the Outbox when Facebook is
+// unavailable or earlier messages to the same recipient are still queued.
+// Sender actions are never queued, as they would be stale when retried.
+// Messages are validated before they are queued, so that only messages which
+// can be sent are.
+func (r *Response) send(m interface{}) (SendResponse, error) {
+	if err := validateMessage(m); err != nil {
+		return SendResponse{}, err
+	}
+
+	data, err := r.jsonCodec().Marshal(m)
+	if err != nil {
+		return SendResponse{}, err
+	}
+
+	if err := validatePayload(data, r.maxPayloadSize()); err != nil {
+		return SendResponse{}, err
+	}
+
+	var o *Outbox
+	if r.messenger != nil && !isSenderAction(m) {
+		o = r.messenger.outbox
+	}
+	if o == nil {
+		return r.postMessage(data)
+	}
+
+	waiting, err := o.pending(r.to)
+	if err != nil {
+		return SendResponse{}, err
+	}
+
+	attempts := 0
+	if !waiting {
+		sent, err := r.postMessage(data)
+		if !isRetriable(err) {
+			return sent, err
+		}
+		attempts = 1
+	}
+
+	if err := o.enqueue(r.to, data, attempts, r.messenger.now()); err != nil {
+		return SendResponse{}, xerrors.Errorf("could not queue message: %w", err)
+	}
+	return SendResponse{}, ErrQueued
+}
+
+// queuedMessage is an encoded message sent from the Outbox, reported to
+// afterSend once it was sent or dropped.
+type queuedMessage struct {
+	data     json.RawMessage
+	metadata string
+}
+
+// newQueuedMessage wraps an encoded message, keeping its metadata so that
+// its echo can still be confirmed.
+func newQueuedMessage(data []byte) *queuedMessage {
+	var v struct {
+		Message struct {
+			Metadata string `json:"metadata"`
+		} `json:"message"`
+	}
+	json.Unmarshal(data, &v)
+	return &queuedMessage{data: data, metadata: v.Message.Metadata}
+}
+
+// MarshalJSON encodes the message as it was queued.
+func (q *queuedMessage) MarshalJSON() ([]byte, error) {
+	return q.data, nil
+}
+
+// FlushOutbox tries to send the queued messages, in order for each
+// recipient. Messages failing with a retriable error stay queued, along with
+// the messages to the same recipient queued after them.
+func (m *Messenger) FlushOutbox() error {
+	o := m.outbox
+	if o == nil {
+		return nil
+	}
+
+	keys, err := o.Store.Keys(outboxPrefix)
+	if err != nil {
+		return err
+	}
+
+	blocked := make(map[string]bool)
+	for _, key := range keys {
+		recipient := key[:strings.LastIndex(key, ":")+1]
+		if blocked[recipient] {
+			continue
+		}
+
+		data, err := o.Store.Get(key)
+		if err == ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		var e outboxEntry
+		if err := json.Unmarshal(data, &e); err != nil {
+			m.recordError(xerrors.Errorf("dropping corrupt queued message %s: %w", key, err))
+			if err := o.Store.Delete(key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = m.newResponse(e.Recipient).postMessage(e.Message)
+		e.Attempts++
+		if isRetriable(err) && (o.MaxAttempts <= 0 || e.Attempts < o.MaxAttempts) {
+			blocked[recipient] = true
+			if data, err := json.Marshal(e); err == nil {
+				o.Store.Set(key, data)
+			}
+			continue
+		}
+		if err != nil {
+			err = xerrors.Errorf("dropping queued message to %s after %d attempts: %w", e.Recipient, e.Attempts, err)
+		}
+		// The message went through beforeSend when it was queued.
+		m.afterSend(e.Recipient, newQueuedMessage(e.Message), err)
+		if err := o.Store.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outboxDepth returns the number of queued messages, recording the error if
+// the Store can not tell.
+func (m *Messenger) outboxDepth() int {
+	if m.outbox == nil {
+		return 0
+	}
+
+	keys, err := m.outbox.Store.Keys(outboxPrefix)
+	if err != nil {
+		m.recordError(xerrors.Errorf("could not count queued messages: %w", err))
+	}
+	return len(keys)
+}
+
+// RunOutbox retries the queued messages every RetryInterval until ctx is
+// done.
+func (m *Messenger) RunOutbox(ctx context.Context) error {
+	if m.outbox == nil {
+		return xerrors.New("no outbox configured")
+	}
+
+	interval := m.outbox.RetryInterval
+	if interval <= 0 {
+		interval = DefaultOutboxRetryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.FlushOutbox(); err != nil {
+			m.recordError(xerrors.Errorf("could not flush outbox: %w", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
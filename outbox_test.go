@@ -0,0 +1,169 @@
+package messenger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestOutbox(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	graph.status = http.StatusServiceUnavailable
+
+	store := NewMemoryStore()
+	m := New(Options{HTTPClient: graph.client(), Outbox: NewOutbox(store)})
+	r := m.Response(111)
+
+	assert.Equal(t, ErrQueued, r.Text("first", ResponseType))
+	assert.Equal(t, 1, graph.count())
+
+	// Queued behind the first message without trying.
+	assert.Equal(t, ErrQueued, r.Text("second", ResponseType))
+	assert.Equal(t, 1, graph.count())
+
+	// Other users are not held up.
+	graph.status = http.StatusOK
+	assert.NoError(t, m.Response(222).Text("other", ResponseType))
+
+	keys, err := store.Keys(outboxPrefix)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	require.NoError(t, m.FlushOutbox())
+	require.Equal(t, 4, graph.count())
+	assert.Contains(t, graph.bodies[2], `"first"`)
+	assert.Contains(t, graph.bodies[3], `"second"`)
+
+	keys, err = store.Keys(outboxPrefix)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestOutboxValidatesQueuedMessages(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	graph.status = http.StatusServiceUnavailable
+
+	store := NewMemoryStore()
+	m := New(Options{HTTPClient: graph.client(), Outbox: NewOutbox(store)})
+	r := m.Response(111)
+
+	assert.Equal(t, ErrQueued, r.Text("first", ResponseType))
+	assert.Equal(t, ErrMissingTag, r.Text("second", MessageTagType))
+
+	keys, err := store.Keys(outboxPrefix)
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestOutboxReportsFlushedMessages(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	graph.status = http.StatusServiceUnavailable
+
+	var transcript bytes.Buffer
+	var confirmed []string
+	m := New(Options{
+		HTTPClient: graph.client(),
+		Outbox:     NewOutbox(NewMemoryStore()),
+		Transcript: NewTranscriptWriter(&transcript),
+		OnEchoConfirmed: func(c EchoConfirmation) {
+			confirmed = append(confirmed, c.Mid)
+		},
+	})
+
+	// Queued messages are neither failures nor sent yet.
+	assert.Equal(t, ErrQueued, m.Response(111).Text("hello", ResponseType))
+	assert.Empty(t, m.RecentErrors())
+	assert.Empty(t, transcript.String())
+
+	graph.status = http.StatusOK
+	require.NoError(t, m.FlushOutbox())
+	require.Equal(t, 2, graph.count())
+	assert.Contains(t, transcript.String(), `"hello"`)
+	assert.NotContains(t, transcript.String(), `"error"`)
+
+	// The message is still tagged, so its echo is confirmed.
+	var sent struct {
+		Message MessageData `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[1]), &sent))
+	require.Contains(t, sent.Message.Metadata, echoMetadataPrefix)
+	m.confirmEcho(MessageInfo{Message: &Message{IsEcho: true, Mid: "m_1", Metadata: sent.Message.Metadata}})
+	assert.Equal(t, []string{"m_1"}, confirmed)
+}
+
+func TestOutboxMaxAttempts(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	graph.status = http.StatusBadGateway
+
+	store := NewMemoryStore()
+	m := New(Options{HTTPClient: graph.client(), Outbox: &Outbox{Store: store, MaxAttempts: 2}})
+
+	assert.Equal(t, ErrQueued, m.Response(111).Text("hello", ResponseType))
+	require.NoError(t, m.FlushOutbox())
+
+	keys, err := store.Keys(outboxPrefix)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	assert.Equal(t, 2, graph.count())
+}
+
+func TestOutboxPermanentError(t *testing.T) {
+	graph := newFakeGraph(`{"error":{"message":"Invalid parameter","code":100}}`)
+	graph.status = http.StatusBadRequest
+
+	store := NewMemoryStore()
+	m := New(Options{HTTPClient: graph.client(), Outbox: NewOutbox(store)})
+
+	err := m.Response(111).Text("hello", ResponseType)
+	assert.Error(t, err)
+	assert.NotEqual(t, ErrQueued, err)
+
+	keys, err := store.Keys(outboxPrefix)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
+
+func TestIsRetriable(t *testing.T) {
+	assert.False(t, isRetriable(nil))
+	assert.True(t, isRetriable(xerrors.Errorf("status 503: %w", ErrServiceUnavailable)))
+	assert.True(t, isRetriable(xerrors.Errorf("facebook error: %w", &QueryError{Code: 2})))
+	assert.False(t, isRetriable(xerrors.Errorf("facebook error: %w", &QueryError{Code: 100})))
+}
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "messenger")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := NewFileStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Set("outbox:id:1:b", []byte("2")))
+	require.NoError(t, s.Set("outbox:id:1:a", []byte("1")))
+	require.NoError(t, s.Set("../escape", []byte("3")))
+	require.NoError(t, s.Set("dialog:1", []byte("4")))
+
+	value, err := s.Get("outbox:id:1:a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	keys, err := s.Keys("outbox:")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outbox:id:1:a", "outbox:id:1:b"}, keys)
+
+	require.NoError(t, s.Delete("outbox:id:1:a"))
+	require.NoError(t, s.Delete("outbox:id:1:a"))
+	_, err = s.Get("outbox:id:1:a")
+	assert.Equal(t, ErrNotFound, err)
+
+	value, err = s.Get("../escape")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("3"), value)
+}
@@ -1,7 +1,150 @@
 package messenger
 
+import (
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
 type passThreadControl struct {
 	Recipient   Recipient `json:"recipient"`
 	TargetAppID int64     `json:"target_app_id"`
 	Metadata    string    `json:"metadata"`
 }
+
+// PassThreadControl is the event fired when thread control is passed to the
+// app through the handover protocol.
+type PassThreadControl struct {
+	// Sender is the user whose conversation was passed.
+	Sender Sender `json:"-"`
+	// Recipient is the page.
+	Recipient Recipient `json:"-"`
+	// Time is when control was passed.
+	Time time.Time `json:"-"`
+	// NewOwnerAppID is the ID of the app now controlling the thread.
+	NewOwnerAppID int64 `json:"new_owner_app_id,string"`
+	// PreviousOwnerAppID is the ID of the app which passed control, when
+	// Facebook sends it.
+	PreviousOwnerAppID int64 `json:"previous_owner_app_id,string,omitempty"`
+	// Metadata is the text passed along by the previous owner.
+	Metadata string `json:"metadata"`
+}
+
+// PassThreadControlHandler is a handler used to react to thread control
+// being passed to the app.
+type PassThreadControlHandler func(PassThreadControl, *Response)
+
+// HandlePassThreadControl adds a new PassThreadControlHandler to the
+// Messenger which will be triggered when another app passes control of a
+// conversation to this one. The page must be subscribed to the
+// messaging_handovers field.
+func (m *Messenger) HandlePassThreadControl(f PassThreadControlHandler) {
+	m.handoverHandlers = append(m.handoverHandlers, f)
+}
+
+// takeThreadControl is the body of take_thread_control and
+// request_thread_control calls.
+type takeThreadControl struct {
+	Recipient Recipient `json:"recipient"`
+	Metadata  string    `json:"metadata,omitempty"`
+}
+
+// TakeThreadControl takes control of the conversation with to back from a
+// secondary receiver, such as a live chat tool, using the handover
+// protocol. Only the primary receiver of the page can take control. A
+// conversation escalated with Response.EscalateToAgent is released, so that
+// its events reach the handlers again.
+// https://developers.facebook.com/docs/messenger-platform/handover-protocol/take-thread-control
+func (m *Messenger) TakeThreadControl(to Recipient, metadata string) error {
+	err := m.graphCall("POST", TakeThreadControlURL, takeThreadControl{Recipient: to, Metadata: metadata}, nil)
+	if err != nil {
+		return err
+	}
+	if to.ID != 0 {
+		m.ReleaseFromAgent(to.ID)
+	}
+	return nil
+}
+
+// RequestThreadControl is the event fired when a secondary receiver asks
+// the app, as primary receiver, for control of a conversation through the
+// handover protocol.
+type RequestThreadControl struct {
+	// Sender is the user whose conversation is requested.
+	Sender Sender `json:"-"`
+	// Recipient is the page.
+	Recipient Recipient `json:"-"`
+	// Time is when control was requested.
+	Time time.Time `json:"-"`
+	// RequestedOwnerAppID is the ID of the app asking for control.
+	RequestedOwnerAppID int64 `json:"requested_owner_app_id"`
+	// Metadata is the text passed along by the requesting app.
+	Metadata string `json:"metadata"`
+}
+
+// RequestThreadControlHandler is a handler used to react to requests for
+// thread control.
+type RequestThreadControlHandler func(RequestThreadControl, *Response)
+
+// HandleRequestThreadControl adds a new RequestThreadControlHandler to the
+// Messenger which will be triggered when a secondary receiver asks for
+// control of a conversation. Handlers approve the request by passing
+// control to it:
+//
+//	r.PassThreadControl(req.RequestedOwnerAppID, "")
+//
+// The page must be subscribed to the messaging_handovers field.
+func (m *Messenger) HandleRequestThreadControl(f RequestThreadControlHandler) {
+	m.threadRequestHandlers = append(m.threadRequestHandlers, f)
+}
+
+// RequestThreadControl asks the primary receiver of the page for control of
+// the conversation with to, using the handover protocol. Only secondary
+// receivers can request control.
+// https://developers.facebook.com/docs/messenger-platform/handover-protocol/request-thread-control
+func (m *Messenger) RequestThreadControl(to Recipient, metadata string) error {
+	return m.graphCall("POST", RequestThreadControlURL, takeThreadControl{Recipient: to, Metadata: metadata}, nil)
+}
+
+// SecondaryReceiver is an app which can be passed thread control.
+type SecondaryReceiver struct {
+	ID   int64  `json:"id,string"`
+	Name string `json:"name"`
+}
+
+// SecondaryReceivers lists the apps set as secondary receivers of the page,
+// which thread control can be passed to. Only the primary receiver of the
+// page can list them.
+// https://developers.facebook.com/docs/messenger-platform/handover-protocol/secondary-receivers
+func (m *Messenger) SecondaryReceivers() ([]SecondaryReceiver, error) {
+	var resp struct {
+		Data []SecondaryReceiver `json:"data"`
+	}
+	if err := m.graphCall("GET", SecondaryReceiversURL+"?fields=id,name", nil, &resp); err != nil {
+		return nil, xerrors.Errorf("could not list secondary receivers: %w", err)
+	}
+	return resp.Data, nil
+}
+
+// ThreadOwner returns the ID of the app currently controlling the
+// conversation with the user psid, to compare with the ID of the app before
+// sending.
+// https://developers.facebook.com/docs/messenger-platform/handover-protocol/get-thread-owner
+func (m *Messenger) ThreadOwner(psid int64) (int64, error) {
+	var resp struct {
+		Data []struct {
+			ThreadOwner struct {
+				AppID int64 `json:"app_id,string"`
+			} `json:"thread_owner"`
+		} `json:"data"`
+	}
+	err := m.graphCall("GET", ThreadOwnerURL+"?recipient="+strconv.FormatInt(psid, 10), nil, &resp)
+	if err != nil {
+		return 0, xerrors.Errorf("could not get thread owner: %w", err)
+	}
+	if len(resp.Data) == 0 {
+		return 0, xerrors.New("could not get thread owner: empty response")
+	}
+	return resp.Data[0].ThreadOwner.AppID, nil
+}
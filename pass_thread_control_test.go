@@ -0,0 +1,113 @@
+package messenger
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_HandlePassThreadControl(t *testing.T) {
+	m := New(Options{})
+
+	var passed []PassThreadControl
+	m.HandlePassThreadControl(func(p PassThreadControl, r *Response) {
+		passed = append(passed, p)
+	})
+	serveFixture(t, m, "pass_thread_control.json")
+
+	assert.Equal(t, []PassThreadControl{{
+		Sender:        Sender{ID: fixturePSID},
+		Recipient:     Recipient{ID: 1067280970047460},
+		Time:          time.Unix(1543095111999/int64(time.Microsecond), 0),
+		NewOwnerAppID: 123456789,
+		Metadata:      "Conversation resolved",
+	}}, passed)
+	assert.Equal(t, 1, m.HandlerCounts()["handover"])
+}
+
+func TestMessenger_TakeThreadControl(t *testing.T) {
+	graph := newFakeGraph(`{"success":true}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	assert.NoError(t, m.TakeThreadControl(Recipient{ID: fixturePSID}, "Agent timed out"))
+	if assert.Equal(t, 1, graph.count()) {
+		assert.Equal(t, "/v2.6/me/take_thread_control", graph.requests[0].URL.Path)
+		assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"metadata":"Agent timed out"}`, graph.bodies[0])
+	}
+
+	graph.status = http.StatusBadRequest
+	graph.response = `{"error":{"message":"(#10) Only the primary receiver can take thread control","code":10}}`
+	assert.Error(t, m.TakeThreadControl(Recipient{ID: fixturePSID}, ""))
+}
+
+func TestMessenger_HandleRequestThreadControl(t *testing.T) {
+	graph := newFakeGraph(`{"success":true}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	var requested []RequestThreadControl
+	m.HandleRequestThreadControl(func(req RequestThreadControl, r *Response) {
+		requested = append(requested, req)
+		assert.NoError(t, r.PassThreadControl(req.RequestedOwnerAppID, "Approved"))
+	})
+	serveFixture(t, m, "request_thread_control.json")
+
+	assert.Equal(t, []RequestThreadControl{{
+		Sender:              Sender{ID: fixturePSID},
+		Recipient:           Recipient{ID: 1067280970047460},
+		Time:                time.Unix(1543095111999/int64(time.Microsecond), 0),
+		RequestedOwnerAppID: 123456789,
+		Metadata:            "User asked for an agent",
+	}}, requested)
+	if assert.Equal(t, 1, graph.count()) {
+		assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"target_app_id":123456789,"metadata":"Approved"}`, graph.bodies[0])
+	}
+	assert.Equal(t, 1, m.HandlerCounts()["thread_request"])
+}
+
+func TestMessenger_RequestThreadControl(t *testing.T) {
+	graph := newFakeGraph(`{"success":true}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	assert.NoError(t, m.RequestThreadControl(Recipient{ID: fixturePSID}, "User asked for a bot"))
+	if assert.Equal(t, 1, graph.count()) {
+		assert.Equal(t, "/v2.6/me/request_thread_control", graph.requests[0].URL.Path)
+		assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"metadata":"User asked for a bot"}`, graph.bodies[0])
+	}
+}
+
+func TestMessenger_SecondaryReceivers(t *testing.T) {
+	graph := newFakeGraph(`{"data":[{"id":"12345","name":"Live chat"},{"id":"67890","name":"Page inbox"}]}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	receivers, err := m.SecondaryReceivers()
+	assert.NoError(t, err)
+	assert.Equal(t, []SecondaryReceiver{{ID: 12345, Name: "Live chat"}, {ID: 67890, Name: "Page inbox"}}, receivers)
+	if assert.Equal(t, 1, graph.count()) {
+		assert.Equal(t, "/v2.6/me/secondary_receivers", graph.requests[0].URL.Path)
+		assert.Equal(t, "id,name", graph.requests[0].URL.Query().Get("fields"))
+	}
+
+	graph.status = http.StatusBadRequest
+	graph.response = `{"error":{"message":"(#10) Only the primary receiver can list secondary receivers","code":10}}`
+	_, err = m.SecondaryReceivers()
+	assert.Error(t, err)
+}
+
+func TestMessenger_ThreadOwner(t *testing.T) {
+	graph := newFakeGraph(`{"data":[{"thread_owner":{"app_id":"12345"}}]}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	owner, err := m.ThreadOwner(fixturePSID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), owner)
+	if assert.Equal(t, 1, graph.count()) {
+		assert.Equal(t, "/v2.6/me/thread_owner", graph.requests[0].URL.Path)
+		assert.Equal(t, "1254459154682919", graph.requests[0].URL.Query().Get("recipient"))
+	}
+
+	graph.response = `{"data":[]}`
+	_, err = m.ThreadOwner(fixturePSID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package messenger
+
+import "time"
+
+// Payment is the event fired when a user pays with the Buy button.
+type Payment struct {
+	// Sender is the user who paid.
+	Sender Sender `json:"-"`
+	// Recipient is the page.
+	Recipient Recipient `json:"-"`
+	// Time is when the payment was made.
+	Time time.Time `json:"-"`
+	// Payload is the payload set on the Buy button.
+	Payload string `json:"payload"`
+	// RequestedUserInfo is the information about the user the Buy button
+	// asked for.
+	RequestedUserInfo RequestedUserInfo `json:"requested_user_info"`
+	// PaymentCredential identifies the payment with the payment provider.
+	PaymentCredential PaymentCredential `json:"payment_credential"`
+	// Amount is the amount paid.
+	Amount PaymentAmount `json:"amount"`
+	// ShippingOptionID is the ID of the shipping option picked by the user.
+	ShippingOptionID string `json:"shipping_option_id,omitempty"`
+}
+
+// PreCheckout is the event fired when a user taps the Pay button, before
+// the payment is made, so that the order can be checked.
+type PreCheckout struct {
+	// Sender is the user about to pay.
+	Sender Sender `json:"-"`
+	// Recipient is the page.
+	Recipient Recipient `json:"-"`
+	// Time is when the user tapped the Pay button.
+	Time time.Time `json:"-"`
+	// Payload is the payload set on the Buy button.
+	Payload string `json:"payload"`
+	// RequestedUserInfo is the information about the user the Buy button
+	// asked for.
+	RequestedUserInfo RequestedUserInfo `json:"requested_user_info"`
+	// Amount is the amount about to be paid.
+	Amount PaymentAmount `json:"amount"`
+}
+
+// RequestedUserInfo is the information about a user requested by a Buy
+// button. Only the fields which were requested are set.
+type RequestedUserInfo struct {
+	ShippingAddress *ShippingAddress `json:"shipping_address,omitempty"`
+	ContactName     string           `json:"contact_name,omitempty"`
+	ContactEmail    string           `json:"contact_email,omitempty"`
+	ContactPhone    string           `json:"contact_phone,omitempty"`
+}
+
+// ShippingAddress is the address a user wants their order shipped to.
+type ShippingAddress struct {
+	Street1    string `json:"street_1"`
+	Street2    string `json:"street_2,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state"`
+	Country    string `json:"country"`
+	PostalCode string `json:"postal_code"`
+}
+
+// PaymentCredential identifies a payment with the payment provider.
+type PaymentCredential struct {
+	// ProviderType is the payment provider, such as "stripe" or "paypal".
+	ProviderType string `json:"provider_type"`
+	// ChargeID is the ID of the charge with the provider.
+	ChargeID string `json:"charge_id"`
+	// FBPaymentID is the ID of the payment for Facebook.
+	FBPaymentID string `json:"fb_payment_id"`
+}
+
+// PaymentAmount is an amount of money.
+type PaymentAmount struct {
+	// Currency is an ISO 4217 code such as "USD".
+	Currency string  `json:"currency"`
+	Amount   float64 `json:"amount,string"`
+}
+
+// PaymentHandler is a handler used to react to payments.
+type PaymentHandler func(Payment, *Response)
+
+// PreCheckoutHandler is a handler used to react to users about to pay.
+type PreCheckoutHandler func(PreCheckout, *Response)
+
+// HandlePayment adds a new PaymentHandler to the Messenger which will be
+// triggered when a user pays with the Buy button. The page must be
+// subscribed to the messaging_payments field.
+func (m *Messenger) HandlePayment(f PaymentHandler) {
+	m.paymentHandlers = append(m.paymentHandlers, f)
+}
+
+// HandlePreCheckout adds a new PreCheckoutHandler to the Messenger which
+// will be triggered when a user taps the Pay button, before paying. The page
+// must be subscribed to the messaging_pre_checkouts field.
+func (m *Messenger) HandlePreCheckout(f PreCheckoutHandler) {
+	m.preCheckoutHandlers = append(m.preCheckoutHandlers, f)
+}
@@ -0,0 +1,67 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_HandlePayment(t *testing.T) {
+	m := New(Options{})
+
+	var payments []Payment
+	m.HandlePayment(func(p Payment, r *Response) {
+		payments = append(payments, p)
+	})
+	serveFixture(t, m, "payment.json")
+
+	assert.Equal(t, []Payment{{
+		Sender:    Sender{ID: fixturePSID},
+		Recipient: Recipient{ID: 1067280970047460},
+		Time:      time.Unix(1543095111999/int64(time.Microsecond), 0),
+		Payload:   "ORDER_42",
+		RequestedUserInfo: RequestedUserInfo{
+			ShippingAddress: &ShippingAddress{
+				Street1:    "1 Hacker Way",
+				City:       "Menlo Park",
+				State:      "CA",
+				Country:    "US",
+				PostalCode: "94025",
+			},
+			ContactName:  "Peter Chang",
+			ContactEmail: "peter@example.com",
+		},
+		PaymentCredential: PaymentCredential{
+			ProviderType: "stripe",
+			ChargeID:     "ch_18tmdBEoNIH3FPJHa60ep123",
+			FBPaymentID:  "123456789",
+		},
+		Amount:           PaymentAmount{Currency: "USD", Amount: 29.62},
+		ShippingOptionID: "123",
+	}}, payments)
+	assert.Equal(t, 1, m.HandlerCounts()["payment"])
+}
+
+func TestMessenger_HandlePreCheckout(t *testing.T) {
+	m := New(Options{})
+
+	var checkouts []PreCheckout
+	m.HandlePreCheckout(func(p PreCheckout, r *Response) {
+		checkouts = append(checkouts, p)
+	})
+	m.HandlePayment(func(p Payment, r *Response) {
+		t.Error("unexpected payment")
+	})
+	serveFixture(t, m, "pre_checkout.json")
+
+	assert.Equal(t, []PreCheckout{{
+		Sender:            Sender{ID: fixturePSID},
+		Recipient:         Recipient{ID: 1067280970047460},
+		Time:              time.Unix(1543095111999/int64(time.Microsecond), 0),
+		Payload:           "ORDER_42",
+		RequestedUserInfo: RequestedUserInfo{ContactEmail: "peter@example.com"},
+		Amount:            PaymentAmount{Currency: "USD", Amount: 2.7},
+	}}, checkouts)
+	assert.Equal(t, 1, m.HandlerCounts()["pre_checkout"])
+}
@@ -0,0 +1,86 @@
+package messenger
+
+import (
+	"net/url"
+
+	"golang.org/x/xerrors"
+)
+
+// personasPath is the Graph API path of the personas of the page, relative
+// to the URL of Messenger.Graph. Personas are found at the root, by ID.
+// https://developers.facebook.com/docs/messenger-platform/send-messages/personas
+const personasPath = "/v2.6/me/personas"
+
+// personaPath returns the Graph API path of the persona id.
+func personaPath(id string) string {
+	return "/v2.6/" + url.PathEscape(id)
+}
+
+// Persona is an identity the page sends messages as, such as a support
+// agent, see Response.WithPersona.
+type Persona struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	ProfilePictureURL string `json:"profile_picture_url"`
+}
+
+// CreatePersona creates a persona with a name and a profile picture, and
+// returns it.
+func (m *Messenger) CreatePersona(name, profilePictureURL string) (Persona, error) {
+	p := Persona{Name: name, ProfilePictureURL: profilePictureURL}
+	body := map[string]string{"name": name, "profile_picture_url": profilePictureURL}
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := m.graphCall("POST", personasPath, body, &resp); err != nil {
+		return Persona{}, xerrors.Errorf("could not create persona %s: %w", name, err)
+	}
+
+	p.ID = resp.ID
+	return p, nil
+}
+
+// GetPersona retrieves a persona by ID.
+func (m *Messenger) GetPersona(id string) (Persona, error) {
+	var p Persona
+	if err := m.graphCall("GET", personaPath(id)+"?fields=id,name,profile_picture_url", nil, &p); err != nil {
+		return Persona{}, xerrors.Errorf("could not get persona %s: %w", id, err)
+	}
+	return p, nil
+}
+
+// ListPersonas lists all the personas of the page.
+func (m *Messenger) ListPersonas() ([]Persona, error) {
+	var personas []Persona
+	next := personasPath
+	for next != "" {
+		var resp struct {
+			Data   []Persona `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+		if err := m.graphCall("GET", next, nil, &resp); err != nil {
+			return nil, xerrors.Errorf("could not list personas: %w", err)
+		}
+
+		personas = append(personas, resp.Data...)
+		next = resp.Paging.Next
+	}
+	return personas, nil
+}
+
+// DeletePersona deletes a persona. Messages sent as the persona keep showing
+// it.
+func (m *Messenger) DeletePersona(id string) error {
+	var resp struct {
+		Success bool `json:"success"`
+	}
+	if err := m.graphCall("DELETE", personaPath(id), nil, &resp); err != nil {
+		return xerrors.Errorf("could not delete persona %s: %w", id, err)
+	}
+	if !resp.Success {
+		return xerrors.Errorf("could not delete persona %s", id)
+	}
+	return nil
+}
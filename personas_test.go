@@ -0,0 +1,40 @@
+package messenger
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_Personas(t *testing.T) {
+	graph := newFakeGraph(`{"id":"2188717154583779"}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	p, err := m.CreatePersona("Sam", "https://example.com/sam.png")
+	require.NoError(t, err)
+	assert.Equal(t, Persona{ID: "2188717154583779", Name: "Sam", ProfilePictureURL: "https://example.com/sam.png"}, p)
+	assert.Equal(t, "/v2.6/me/personas", graph.requests[0].URL.Path)
+	assert.JSONEq(t, `{"name":"Sam","profile_picture_url":"https://example.com/sam.png"}`, graph.bodies[0])
+
+	graph.response = `{"id":"2188717154583779","name":"Sam","profile_picture_url":"https://example.com/sam.png"}`
+	got, err := m.GetPersona(p.ID)
+	require.NoError(t, err)
+	assert.Equal(t, p, got)
+	assert.Equal(t, "/v2.6/2188717154583779", graph.requests[1].URL.Path)
+
+	graph.response = `{"data":[{"id":"2188717154583779","name":"Sam","profile_picture_url":"https://example.com/sam.png"}]}`
+	personas, err := m.ListPersonas()
+	require.NoError(t, err)
+	assert.Equal(t, []Persona{p}, personas)
+
+	graph.response = `{"success":true}`
+	require.NoError(t, m.DeletePersona(p.ID))
+	assert.Equal(t, "DELETE", graph.requests[3].Method)
+	assert.Equal(t, "/v2.6/2188717154583779", graph.requests[3].URL.Path)
+
+	graph.status = http.StatusBadRequest
+	graph.response = `{"error":{"message":"(#100) Invalid persona","code":100}}`
+	assert.Error(t, m.DeletePersona(p.ID))
+}
@@ -0,0 +1,115 @@
+package messenger
+
+import (
+	"sync"
+	"time"
+)
+
+// WebhookTimings are how long each phase of a webhook request took, to find
+// out which one makes Facebook time out. Phases which were not reached, such
+// as those after a failed signature check, are zero.
+type WebhookTimings struct {
+	// Read is the reading of the request body.
+	Read time.Duration `json:"read"`
+	// Decode is the decoding of the events.
+	Decode time.Duration `json:"decode"`
+	// Verify is the checking of the signature, when Options.Verify is set.
+	Verify time.Duration `json:"verify"`
+	// Dispatch is the running of the handlers of every event.
+	Dispatch time.Duration `json:"dispatch"`
+	// Ack is the writing of the response.
+	Ack time.Duration `json:"ack"`
+	// Total is the whole request.
+	Total time.Duration `json:"total"`
+}
+
+// PhaseLatencies summarize the phases of the most recent webhook requests
+// which were dispatched.
+type PhaseLatencies struct {
+	Read     LatencySummary `json:"read"`
+	Decode   LatencySummary `json:"decode"`
+	Verify   LatencySummary `json:"verify"`
+	Dispatch LatencySummary `json:"dispatch"`
+	Ack      LatencySummary `json:"ack"`
+	Total    LatencySummary `json:"total"`
+}
+
+// phaseTimer measures the phases of a webhook request.
+type phaseTimer struct {
+	WebhookTimings
+
+	m           *Messenger
+	start, last time.Time
+	dispatched  bool
+}
+
+func (m *Messenger) startPhases() *phaseTimer {
+	now := m.now()
+	return &phaseTimer{m: m, start: now, last: now}
+}
+
+// lap returns the time since the previous lap.
+func (t *phaseTimer) lap() time.Duration {
+	now := t.m.now()
+	d := now.Sub(t.last)
+	t.last = now
+	return d
+}
+
+// finish records the timings of the request, including the time spent
+// since the last lap by the paths returning early.
+func (t *phaseTimer) finish() {
+	t.Total = t.m.now().Sub(t.start)
+	if t.dispatched {
+		t.m.phases.observe(t.WebhookTimings)
+	}
+	if t.m.onWebhookTimings != nil {
+		t.m.onWebhookTimings(t.WebhookTimings)
+	}
+}
+
+// phaseStats keeps the timings of the most recent dispatched requests.
+type phaseStats struct {
+	mu      sync.Mutex
+	timings [latencySamples]WebhookTimings
+	next    int
+	full    bool
+}
+
+func (s *phaseStats) observe(t WebhookTimings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.timings[s.next] = t
+	s.next = (s.next + 1) % latencySamples
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+func (s *phaseStats) snapshot() PhaseLatencies {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.next
+	if s.full {
+		n = latencySamples
+	}
+
+	phase := func(f func(WebhookTimings) time.Duration) LatencySummary {
+		durations := make([]time.Duration, n)
+		for i, t := range s.timings[:n] {
+			durations[i] = f(t)
+		}
+		return summarize(durations)
+	}
+
+	return PhaseLatencies{
+		Read:     phase(func(t WebhookTimings) time.Duration { return t.Read }),
+		Decode:   phase(func(t WebhookTimings) time.Duration { return t.Decode }),
+		Verify:   phase(func(t WebhookTimings) time.Duration { return t.Verify }),
+		Dispatch: phase(func(t WebhookTimings) time.Duration { return t.Dispatch }),
+		Ack:      phase(func(t WebhookTimings) time.Duration { return t.Ack }),
+		Total:    phase(func(t WebhookTimings) time.Duration { return t.Total }),
+	}
+}
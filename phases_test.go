@@ -0,0 +1,50 @@
+package messenger
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowWriter is a ResponseRecorder taking two seconds to write the response.
+type slowWriter struct {
+	*httptest.ResponseRecorder
+	clock *fakeClock
+}
+
+func (w *slowWriter) Write(b []byte) (int, error) {
+	w.clock.Advance(2 * time.Second)
+	return w.ResponseRecorder.Write(b)
+}
+
+func TestMessenger_WebhookTimings(t *testing.T) {
+	clock := newFakeClock()
+	var timings []WebhookTimings
+	m := New(Options{
+		Clock: clock,
+		OnWebhookTimings: func(t WebhookTimings) {
+			timings = append(timings, t)
+		},
+	})
+	m.HandleMessage(func(msg Message, r *Response) {
+		clock.Advance(3 * time.Second)
+	})
+
+	serveFixture(t, m, "message_text.json")
+	w := &slowWriter{ResponseRecorder: httptest.NewRecorder(), clock: clock}
+	m.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("{")))
+
+	require.Len(t, timings, 2)
+	assert.Equal(t, WebhookTimings{Dispatch: 3 * time.Second, Total: 3 * time.Second}, timings[0])
+	assert.Equal(t, WebhookTimings{Total: 2 * time.Second}, timings[1])
+
+	stats := m.EventStats()
+	assert.Equal(t, 1, stats.Phases.Dispatch.Count)
+	assert.Equal(t, 3*time.Second, stats.Phases.Dispatch.Max)
+	assert.Equal(t, 3*time.Second, stats.Phases.Total.P50)
+	assert.Equal(t, time.Duration(0), stats.Phases.Read.Max)
+}
@@ -0,0 +1,127 @@
+package messenger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// maxPooledBodySize is the largest body buffer kept for reuse, so that a
+// single huge webhook does not pin its memory in the pool.
+const maxPooledBodySize = 1 << 20
+
+var (
+	receivePool = sync.Pool{New: func() interface{} { return new(Receive) }}
+	bodyPool    = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+)
+
+// DecodeReceive decodes the body of a webhook request.
+//
+// When Options.PooledDecoding is set the Receive comes from a pool and the
+// caller owns it until it calls Release, after which neither it nor any of
+// its entries and events may be used. Webhook requests served by the
+// Messenger release their Receive once every handler has returned, so
+// handlers must copy anything they keep from a MessageInfo, see
+// Message.Clone.
+func (m *Messenger) DecodeReceive(body []byte) (*Receive, error) {
+	if !m.pooled {
+		var rec Receive
+		if err := m.jsonCodec().Unmarshal(body, &rec); err != nil {
+			return nil, err
+		}
+		m.findUnknownFields(body, &rec)
+		return &rec, nil
+	}
+
+	rec := receivePool.Get().(*Receive)
+	rec.owned = true
+	if err := m.jsonCodec().Unmarshal(body, rec); err != nil {
+		rec.Release()
+		return nil, err
+	}
+
+	m.findUnknownFields(body, rec)
+	return rec, nil
+}
+
+// Dispatch triggers the handlers of every event of rec, as if it had been
+// received by the webhook. It lets decoding and processing happen on
+// different goroutines, for instance to answer Facebook before the events
+// are handled; the caller remains the owner of rec.
+func (m *Messenger) Dispatch(rec *Receive) {
+	m.dispatch(*rec)
+}
+
+// DispatchContext is Dispatch with the context returned by the Context
+// method of the Responses passed to handlers.
+func (m *Messenger) DispatchContext(ctx context.Context, rec *Receive) {
+	m.dispatchContext(ctx, *rec)
+}
+
+// Release returns a Receive decoded with Options.PooledDecoding to its pool.
+// It must be called exactly once, after the Receive is no longer used.
+// Release does nothing on a Receive which was not pooled.
+func (rec *Receive) Release() {
+	if !rec.owned {
+		return
+	}
+
+	// The entries and events are cleared but their backing arrays are
+	// kept, so that decoding the next webhook reuses them.
+	for i := range rec.Entry {
+		events := rec.Entry[i].Messaging
+		for j := range events {
+			events[j] = MessageInfo{}
+		}
+		rec.Entry[i] = Entry{Messaging: events[:0]}
+	}
+	*rec = Receive{Entry: rec.Entry[:0]}
+	receivePool.Put(rec)
+}
+
+// bodyBuffer returns a buffer to read a webhook body into.
+func (m *Messenger) bodyBuffer() *bytes.Buffer {
+	if !m.pooled {
+		return new(bytes.Buffer)
+	}
+
+	buf := bodyPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// releaseBodyBuffer returns a buffer obtained from bodyBuffer to its pool.
+func (m *Messenger) releaseBodyBuffer(buf *bytes.Buffer) {
+	if !m.pooled || buf.Cap() > maxPooledBodySize {
+		return
+	}
+
+	bodyPool.Put(buf)
+}
+
+// Clone returns a copy of the message which shares nothing decoded from the
+// webhook, so that it may be kept after the handler it was passed to
+// returns.
+func (msg Message) Clone() Message {
+	if msg.Attachments != nil {
+		attachments := make([]Attachment, len(msg.Attachments))
+		for i, a := range msg.Attachments {
+			if a.Payload.Coordinates != nil {
+				c := *a.Payload.Coordinates
+				a.Payload.Coordinates = &c
+			}
+			attachments[i] = a
+		}
+		msg.Attachments = attachments
+	}
+	if msg.QuickReply != nil {
+		qr := *msg.QuickReply
+		msg.QuickReply = &qr
+	}
+	if msg.NLP != nil {
+		msg.NLP = append(json.RawMessage(nil), msg.NLP...)
+	}
+
+	return msg
+}
@@ -0,0 +1,114 @@
+package messenger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_DecodeReceivePooled(t *testing.T) {
+	pooled := New(Options{PooledDecoding: true})
+	plain := New(Options{})
+
+	batched, err := ioutil.ReadFile("testdata/webhooks/batched.json")
+	require.NoError(t, err)
+	rec, err := pooled.DecodeReceive(batched)
+	require.NoError(t, err)
+	require.Len(t, rec.Entry, 2)
+	rec.Release()
+	rec.Release()
+
+	// Nothing from the batch may leak into the events decoded next.
+	for _, name := range []string{"delivery.json", "message_text.json", "read.json"} {
+		body, err := ioutil.ReadFile("testdata/webhooks/" + name)
+		require.NoError(t, err)
+
+		want, err := plain.DecodeReceive(body)
+		require.NoError(t, err)
+		got, err := pooled.DecodeReceive(body)
+		require.NoError(t, err)
+		assert.Equal(t, want.Object, got.Object, name)
+		assert.Equal(t, want.Entry, got.Entry, name)
+		got.Release()
+	}
+
+	_, err = pooled.DecodeReceive([]byte(`{"object":`))
+	assert.Error(t, err)
+}
+
+func TestMessenger_PooledDecoding(t *testing.T) {
+	m := New(Options{PooledDecoding: true})
+
+	var kept []Message
+	m.HandleMessage(func(msg Message, r *Response) {
+		kept = append(kept, msg.Clone())
+	})
+
+	serveFixture(t, m, "batched.json")
+	serveFixture(t, m, "message_attachments.json")
+	serveFixture(t, m, "message_text.json")
+
+	require.Len(t, kept, 4)
+	assert.Equal(t, "first", kept[0].Text)
+	assert.Equal(t, "second", kept[1].Text)
+	assert.NotEmpty(t, kept[2].Attachments)
+	assert.Equal(t, "hello, world!", kept[3].Text)
+}
+
+func TestMessenger_PooledDecodingConcurrent(t *testing.T) {
+	m := New(Options{PooledDecoding: true})
+
+	var (
+		mu    sync.Mutex
+		texts = make(map[string]int)
+	)
+	m.HandleMessage(func(msg Message, r *Response) {
+		mu.Lock()
+		texts[msg.Text]++
+		mu.Unlock()
+	})
+
+	var bodies [][]byte
+	for _, name := range []string{"batched.json", "message_text.json", "read.json"} {
+		body, err := ioutil.ReadFile("testdata/webhooks/" + name)
+		require.NoError(t, err)
+		bodies = append(bodies, body)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				for _, body := range bodies {
+					w := httptest.NewRecorder()
+					m.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"first": 400, "second": 400, "hello, world!": 400}, texts)
+}
+
+func BenchmarkHandle_Pooled(b *testing.B) {
+	body, err := ioutil.ReadFile("testdata/webhooks/batched.json")
+	require.NoError(b, err)
+
+	m := New(Options{PooledDecoding: true})
+	m.HandleMessage(func(Message, *Response) {})
+	h := m.Handler()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+	}
+}
@@ -0,0 +1,58 @@
+package messenger
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// postbackDedup remembers recent postbacks to drop the duplicates caused by
+// users double-tapping buttons.
+type postbackDedup struct {
+	window time.Duration
+
+	mu     sync.Mutex
+	latest int64
+	seen   map[postbackKey]int64
+}
+
+type postbackKey struct {
+	sender  int64
+	payload string
+}
+
+// duplicatePostback reports whether info is a postback with the same sender
+// and payload as one received less than PostbackDedupWindow before it,
+// according to the timestamps set by Facebook.
+func (m *Messenger) duplicatePostback(ctx context.Context, info MessageInfo) bool {
+	d := &m.postbacks
+	if d.window <= 0 || info.PostBack == nil {
+		return false
+	}
+
+	window := d.window.Milliseconds()
+	key := postbackKey{sender: info.Sender.ID, payload: info.PostBack.Payload}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen == nil {
+		d.seen = make(map[postbackKey]int64)
+	}
+	if info.Timestamp > d.latest {
+		d.latest = info.Timestamp
+		for k, ts := range d.seen {
+			if d.latest-ts >= window {
+				delete(d.seen, k)
+			}
+		}
+	}
+
+	last, ok := d.seen[key]
+	if ok && info.Timestamp-last < window && last-info.Timestamp < window {
+		logEvent(ctx, "Duplicate postback from", m.psidHasher.Hash(info.Sender.ID))
+		return true
+	}
+	d.seen[key] = info.Timestamp
+	return false
+}
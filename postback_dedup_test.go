@@ -0,0 +1,47 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_PostbackDedup(t *testing.T) {
+	m := New(Options{PostbackDedupWindow: time.Second})
+
+	var handled []string
+	m.HandlePostBack(func(p PostBack, r *Response) {
+		handled = append(handled, p.Payload)
+	})
+
+	postback := func(sender, ts int64, payload string) MessageInfo {
+		return MessageInfo{
+			Sender:    Sender{ID: sender},
+			Timestamp: ts,
+			PostBack:  &PostBack{Payload: payload},
+		}
+	}
+	m.dispatch(Receive{Entry: []Entry{{Messaging: []MessageInfo{
+		postback(1, 1000, "ORDER"),
+		postback(1, 1150, "ORDER"),  // double tap
+		postback(2, 1150, "ORDER"),  // another user
+		postback(1, 1200, "CANCEL"), // another button
+		postback(1, 2500, "ORDER"),  // a later tap
+	}}}})
+
+	assert.Equal(t, []string{"ORDER", "ORDER", "CANCEL", "ORDER"}, handled)
+}
+
+func TestMessenger_PostbackDedupDisabled(t *testing.T) {
+	m := New(Options{})
+
+	var handled int
+	m.HandlePostBack(func(p PostBack, r *Response) {
+		handled++
+	})
+
+	serveFixture(t, m, "postback.json")
+	serveFixture(t, m, "postback.json")
+	assert.Equal(t, 2, handled)
+}
@@ -0,0 +1,127 @@
+package messenger
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ProductURL is the API endpoint for the products of the catalogs of the
+// page, followed by the ID of a product.
+// https://developers.facebook.com/docs/marketing-api/reference/product-item
+const ProductURL = "https://graph.facebook.com/v2.11/"
+
+// productFields are the fields of a product fetched from its catalog.
+const productFields = "id,retailer_id,name,description,price,currency,image_url,url"
+
+// productPayloadPrefix starts the payload of product quick replies.
+const productPayloadPrefix = "product:"
+
+// maxQuickReplyTitle is the maximum length of the title of a quick reply.
+const maxQuickReplyTitle = 20
+
+// Product is an item of a Commerce catalog.
+type Product struct {
+	// ID is the ID of the product in the catalog.
+	ID string `json:"id"`
+	// RetailerID is the ID of the product given by the retailer.
+	RetailerID string `json:"retailer_id,omitempty"`
+	Name       string `json:"name"`
+	// Description is the description of the product.
+	Description string `json:"description,omitempty"`
+	// Price is the price formatted for display, such as "$9.99".
+	Price string `json:"price,omitempty"`
+	// Currency is the ISO 4217 code of the currency of Price.
+	Currency string `json:"currency,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	// URL is the page of the product on the website of the retailer.
+	URL string `json:"url,omitempty"`
+}
+
+// ProductTemplatePayload is the payload of a product template, showing
+// products of the catalog of the page.
+type ProductTemplatePayload struct {
+	TemplateType string           `json:"template_type"`
+	Elements     []ProductElement `json:"elements"`
+}
+
+// ProductElement is a product shown in a product template.
+type ProductElement struct {
+	ID string `json:"id"`
+}
+
+// Product fetches the details of a product from its catalog.
+func (m *Messenger) Product(id string) (Product, error) {
+	var p Product
+	err := m.graphCall("GET", ProductURL+url.PathEscape(id)+"?fields="+productFields, nil, &p)
+	if err != nil {
+		return Product{}, xerrors.Errorf("could not fetch product %s: %w", id, err)
+	}
+	return p, nil
+}
+
+// Products fetches the details of several products, in order.
+func (m *Messenger) Products(ids []string) ([]Product, error) {
+	products := make([]Product, 0, len(ids))
+	for _, id := range ids {
+		p, err := m.Product(id)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+// NewProductQuickReply creates a quick reply picking p, titled with its name
+// and showing its image. The product picked by the user is returned by
+// QuickReply.ProductID.
+func NewProductQuickReply(p Product) QuickReply {
+	return QuickReply{
+		ContentType: QuickReplyText,
+		Title:       TruncateText(p.Name, maxQuickReplyTitle),
+		Payload:     productPayloadPrefix + p.ID,
+		ImageURL:    p.ImageURL,
+	}
+}
+
+// ProductQuickReplies creates a quick reply for each product, up to
+// MaxQuickReplies.
+func ProductQuickReplies(products []Product) []QuickReply {
+	if len(products) > MaxQuickReplies {
+		products = products[:MaxQuickReplies]
+	}
+
+	replies := make([]QuickReply, 0, len(products))
+	for _, p := range products {
+		replies = append(replies, NewProductQuickReply(p))
+	}
+	return replies
+}
+
+// ProductID returns the ID of the product of a quick reply created by
+// NewProductQuickReply, and whether it is one.
+func (q QuickReply) ProductID() (string, bool) {
+	if !strings.HasPrefix(q.Payload, productPayloadPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(q.Payload, productPayloadPrefix), true
+}
+
+// ProductTemplate sends products of the catalog of the page, which users can
+// browse and buy.
+func (r *Response) ProductTemplate(productIDs []string, messagingType MessagingType, tags ...string) error {
+	if len(productIDs) == 0 || len(productIDs) > MaxGenericTemplateElements {
+		return xerrors.Errorf("product template needs between 1 and %d products, got %d", MaxGenericTemplateElements, len(productIDs))
+	}
+
+	elements := make([]ProductElement, 0, len(productIDs))
+	for _, id := range productIDs {
+		elements = append(elements, ProductElement{ID: id})
+	}
+	return r.templateMessage(ProductTemplatePayload{
+		TemplateType: "product",
+		Elements:     elements,
+	}, messagingType, tags...)
+}
@@ -0,0 +1,63 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_Product(t *testing.T) {
+	graph := newFakeGraph(`{"id":"1234","retailer_id":"SHOES-42","name":"Running shoes, size 42","price":"$89.99","currency":"USD","image_url":"https://example.com/shoes.jpg"}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	p, err := m.Product("1234")
+	require.NoError(t, err)
+	assert.Equal(t, Product{
+		ID:         "1234",
+		RetailerID: "SHOES-42",
+		Name:       "Running shoes, size 42",
+		Price:      "$89.99",
+		Currency:   "USD",
+		ImageURL:   "https://example.com/shoes.jpg",
+	}, p)
+	require.Equal(t, 1, graph.count())
+	assert.Equal(t, "/v2.11/1234", graph.requests[0].URL.Path)
+	assert.Equal(t, productFields, graph.requests[0].URL.Query().Get("fields"))
+
+	qr := NewProductQuickReply(p)
+	assert.Equal(t, QuickReply{
+		ContentType: QuickReplyText,
+		Title:       "Running shoes, size ",
+		Payload:     "product:1234",
+		ImageURL:    "https://example.com/shoes.jpg",
+	}, qr)
+
+	id, ok := qr.ProductID()
+	assert.True(t, ok)
+	assert.Equal(t, "1234", id)
+	_, ok = NewQuickReply("Red").ProductID()
+	assert.False(t, ok)
+
+	require.NoError(t, m.Response(fixturePSID).TextWithReplies("Which one?", ProductQuickReplies([]Product{p}), ResponseType))
+	assert.Equal(t, 2, graph.count())
+}
+
+func TestResponse_ProductTemplate(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	require.NoError(t, m.Response(fixturePSID).ProductTemplate([]string{"1234", "5678"}, ResponseType))
+	require.Equal(t, 1, graph.count())
+	assert.JSONEq(t, `{
+		"messaging_type": "RESPONSE",
+		"recipient": {"id": "1254459154682919"},
+		"message": {"attachment": {"type": "template", "payload": {
+			"template_type": "product",
+			"elements": [{"id": "1234"}, {"id": "5678"}]
+		}}}
+	}`, graph.bodies[0])
+
+	assert.Error(t, m.Response(fixturePSID).ProductTemplate(nil, ResponseType))
+	assert.Equal(t, 1, graph.count())
+}
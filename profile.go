@@ -1,5 +1,10 @@
 package messenger
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Profile is the public information of a Facebook user
 type Profile struct {
 	Name          string  `json:"name"`
@@ -10,3 +15,62 @@ type Profile struct {
 	Timezone      float64 `json:"timezone"`
 	Gender        string  `json:"gender"`
 }
+
+// FacebookProfileFields are the fields of the Profile of Messenger users
+// which can be retrieved without special permissions.
+var FacebookProfileFields = []string{"name", "first_name", "last_name", "profile_pic"}
+
+// InstagramProfileFields are the fields of an InstagramProfile.
+var InstagramProfileFields = []string{"name", "username", "profile_pic", "follower_count", "is_verified_user", "is_user_follow_business", "is_business_follow_user"}
+
+// InstagramProfile is the public information of an Instagram user.
+type InstagramProfile struct {
+	Name                 string `json:"name"`
+	Username             string `json:"username"`
+	ProfilePicURL        string `json:"profile_pic"`
+	FollowerCount        int    `json:"follower_count"`
+	IsVerifiedUser       bool   `json:"is_verified_user"`
+	IsUserFollowBusiness bool   `json:"is_user_follow_business"`
+	IsBusinessFollowUser bool   `json:"is_business_follow_user"`
+}
+
+// UserProfile is the profile of a user of either surface. Facebook is set for
+// Messenger users and Instagram for Instagram users.
+type UserProfile struct {
+	Surface   Surface
+	Facebook  *Profile
+	Instagram *InstagramProfile
+}
+
+// UserProfile retrieves the profile of a user of the given surface,
+// requesting only the fields valid on it: FacebookProfileFields or
+// InstagramProfileFields.
+func (m *Messenger) UserProfile(surface Surface, id int64) (UserProfile, error) {
+	up := UserProfile{Surface: surface}
+
+	fields := FacebookProfileFields
+	var v interface{}
+	if surface == SurfaceInstagram {
+		fields = InstagramProfileFields
+		up.Instagram = &InstagramProfile{}
+		v = up.Instagram
+	} else {
+		up.Surface = SurfaceMessenger
+		up.Facebook = &Profile{}
+		v = up.Facebook
+	}
+
+	endpoint := ProfileURL + strconv.FormatInt(id, 10) + "?fields=" + strings.Join(fields, ",")
+	if err := m.graphCall("GET", endpoint, nil, v); err != nil {
+		return UserProfile{}, err
+	}
+	return up, nil
+}
+
+// Profile retrieves the profile of the recipient of r, on its surface.
+func (r *Response) Profile() (UserProfile, error) {
+	if r.messenger == nil {
+		return UserProfile{}, ErrNoMessenger
+	}
+	return r.messenger.UserProfile(r.Surface(), r.to.ID)
+}
@@ -0,0 +1,34 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_UserProfile(t *testing.T) {
+	graph := newFakeGraph(`{"name":"Jane Doe","username":"jane","profile_pic":"https://example.com/jane.jpg","follower_count":42,"is_user_follow_business":true}`)
+	m := New(Options{Token: "token", HTTPClient: graph.client()})
+
+	p, err := m.Response(111).WithSurface(SurfaceInstagram).Profile()
+	require.NoError(t, err)
+	assert.Equal(t, UserProfile{Surface: SurfaceInstagram, Instagram: &InstagramProfile{
+		Name:                 "Jane Doe",
+		Username:             "jane",
+		ProfilePicURL:        "https://example.com/jane.jpg",
+		FollowerCount:        42,
+		IsUserFollowBusiness: true,
+	}}, p)
+	assert.Equal(t, "/v2.6/111", graph.requests[0].URL.Path)
+	assert.Equal(t, "name,username,profile_pic,follower_count,is_verified_user,is_user_follow_business,is_business_follow_user", graph.requests[0].URL.Query().Get("fields"))
+
+	graph.response = `{"name":"John Doe","first_name":"John","last_name":"Doe"}`
+	p, err = m.UserProfile(SurfaceMessenger, 222)
+	require.NoError(t, err)
+	assert.Equal(t, UserProfile{Surface: SurfaceMessenger, Facebook: &Profile{Name: "John Doe", FirstName: "John", LastName: "Doe"}}, p)
+	assert.Equal(t, "name,first_name,last_name,profile_pic", graph.requests[1].URL.Query().Get("fields"))
+
+	_, err = NewResponse(ResponseOptions{Recipient: Recipient{ID: 111}}).Profile()
+	assert.Equal(t, ErrNoMessenger, err)
+}
@@ -0,0 +1,116 @@
+package messenger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/paked/messenger/graph"
+	"golang.org/x/xerrors"
+)
+
+// maxBatchSize is the maximum number of requests in a Graph API batch.
+const maxBatchSize = 50
+
+// ProfilesError is returned by ProfilesByID when some of the profiles could
+// not be retrieved.
+type ProfilesError struct {
+	// Errors holds the reason each missing profile could not be retrieved,
+	// keyed by user ID.
+	Errors map[int64]error
+}
+
+func (e *ProfilesError) Error() string {
+	return fmt.Sprintf("could not retrieve %d profiles", len(e.Errors))
+}
+
+type batchRequest struct {
+	Method      string `json:"method"`
+	RelativeURL string `json:"relative_url"`
+}
+
+type batchResponse struct {
+	Code int    `json:"code"`
+	Body string `json:"body"`
+}
+
+// ProfilesByID retrieves the profiles of many users at once, using as few
+// Graph API batch requests as possible. The profiles which could be
+// retrieved are returned even if others could not, in which case the error is
+// a *ProfilesError.
+func (m *Messenger) ProfilesByID(ids []int64, profileFields []string) (map[int64]Profile, error) {
+	profiles := make(map[int64]Profile, len(ids))
+	failed := &ProfilesError{Errors: make(map[int64]error)}
+
+	for start := 0; start < len(ids); start += maxBatchSize {
+		end := start + maxBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		chunk := ids[start:end]
+		results, err := m.profilesBatch(chunk, profileFields)
+		if err != nil {
+			for _, id := range chunk {
+				failed.Errors[id] = err
+			}
+			continue
+		}
+
+		for i, id := range chunk {
+			p, err := decodeBatchProfile(results[i])
+			if err != nil {
+				failed.Errors[id] = err
+				continue
+			}
+			profiles[id] = p
+		}
+	}
+
+	if len(failed.Errors) > 0 {
+		return profiles, failed
+	}
+	return profiles, nil
+}
+
+// profilesBatch makes a single batch request for the profiles of ids,
+// returning one response per ID.
+func (m *Messenger) profilesBatch(ids []int64, profileFields []string) ([]batchResponse, error) {
+	fields := url.QueryEscape(strings.Join(profileFields, ","))
+
+	batch := make([]batchRequest, len(ids))
+	for i, id := range ids {
+		batch[i] = batchRequest{
+			Method:      "GET",
+			RelativeURL: strconv.FormatInt(id, 10) + "?fields=" + fields,
+		}
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []batchResponse
+	if err := m.graphCall("POST", ProfileURL, url.Values{"batch": {string(data)}}, &results); err != nil {
+		return nil, err
+	}
+	if len(results) != len(ids) {
+		return nil, xerrors.Errorf("expected %d batch responses, got %d", len(ids), len(results))
+	}
+
+	return results, nil
+}
+
+func decodeBatchProfile(res batchResponse) (Profile, error) {
+	p := Profile{}
+	if res.Code != http.StatusOK {
+		return p, graph.DecodeError(strings.NewReader(res.Body))
+	}
+
+	err := json.Unmarshal([]byte(res.Body), &p)
+	return p, err
+}
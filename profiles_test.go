@@ -0,0 +1,42 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_ProfilesByID(t *testing.T) {
+	responses, err := json.Marshal([]batchResponse{
+		{Code: 200, Body: `{"first_name":"Jane","last_name":"Doe"}`},
+		{Code: 400, Body: `{"error":{"message":"No profile available for that user.","code":100}}`},
+		{Code: 200, Body: `{"first_name":"John","last_name":"Doe"}`},
+	})
+	require.NoError(t, err)
+
+	graph := newFakeGraph(string(responses))
+	m := New(Options{Token: "token", HTTPClient: graph.client()})
+
+	profiles, err := m.ProfilesByID([]int64{1, 2, 3}, []string{"first_name", "last_name"})
+
+	require.IsType(t, &ProfilesError{}, err)
+	assert.Len(t, err.(*ProfilesError).Errors, 1)
+	assert.Error(t, err.(*ProfilesError).Errors[2])
+	assert.Equal(t, map[int64]Profile{
+		1: {FirstName: "Jane", LastName: "Doe"},
+		3: {FirstName: "John", LastName: "Doe"},
+	}, profiles)
+
+	require.Equal(t, 1, graph.count())
+	form, err := url.ParseQuery(graph.bodies[0])
+	require.NoError(t, err)
+	assert.Equal(t, "token", form.Get("access_token"))
+	assert.JSONEq(t, `[
+		{"method":"GET","relative_url":"1?fields=first_name%2Clast_name"},
+		{"method":"GET","relative_url":"2?fields=first_name%2Clast_name"},
+		{"method":"GET","relative_url":"3?fields=first_name%2Clast_name"}
+	]`, form.Get("batch"))
+}
@@ -0,0 +1,31 @@
+package messenger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// PSIDHasher anonymizes page-scoped IDs for logs and metrics, so events can be
+// correlated per user without storing the raw IDs.
+type PSIDHasher struct {
+	key []byte
+}
+
+// NewPSIDHasher creates a PSIDHasher which hashes IDs with HMAC-SHA256 using
+// key. The same key must be used to correlate hashes across processes.
+func NewPSIDHasher(key []byte) *PSIDHasher {
+	return &PSIDHasher{key: key}
+}
+
+// Hash returns the anonymized form of id. A nil PSIDHasher returns the raw ID.
+func (h *PSIDHasher) Hash(id int64) string {
+	if h == nil {
+		return strconv.FormatInt(id, 10)
+	}
+
+	mac := hmac.New(sha256.New, h.key)
+	mac.Write([]byte(strconv.FormatInt(id, 10)))
+	return hex.EncodeToString(mac.Sum(nil)[:8])
+}
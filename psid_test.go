@@ -0,0 +1,18 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPSIDHasher(t *testing.T) {
+	var raw *PSIDHasher
+	assert.Equal(t, "1234", raw.Hash(1234))
+
+	h := NewPSIDHasher([]byte("key"))
+	assert.Len(t, h.Hash(1234), 16)
+	assert.Equal(t, h.Hash(1234), h.Hash(1234))
+	assert.NotEqual(t, h.Hash(1234), h.Hash(1235))
+	assert.NotEqual(t, h.Hash(1234), NewPSIDHasher([]byte("other key")).Hash(1234))
+}
@@ -0,0 +1,71 @@
+package messenger
+
+import (
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// MaxQuickReplies is the maximum number of quick replies which can be
+	// sent with a message.
+	MaxQuickReplies = 13
+
+	// QuickReplyText is the content type of a quick reply with a title and
+	// payload.
+	QuickReplyText = "text"
+)
+
+// ErrTooManyQuickReplies is returned when sending more than MaxQuickReplies
+// quick replies with a message.
+var ErrTooManyQuickReplies = xerrors.Errorf("too many quick replies, limit is %d", MaxQuickReplies)
+
+// NewQuickReply creates a text quick reply with the given title.
+func NewQuickReply(title string) QuickReply {
+	return QuickReply{
+		ContentType: QuickReplyText,
+		Title:       title,
+	}
+}
+
+// WithPayload sets the payload of the quick reply to the JSON encoding of v,
+// which can be decoded again with DecodePayload once the user picks the
+// reply.
+func (q QuickReply) WithPayload(v interface{}) (QuickReply, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return q, xerrors.Errorf("could not encode quick reply payload: %w", err)
+	}
+
+	q.Payload = string(data)
+	return q, nil
+}
+
+// MustWithPayload is like WithPayload but panics if v can not be encoded.
+// It simplifies building quick replies from values known to be encodable.
+func (q QuickReply) MustWithPayload(v interface{}) QuickReply {
+	q, err := q.WithPayload(v)
+	if err != nil {
+		panic("messenger: " + err.Error())
+	}
+	return q
+}
+
+// WithImage sets the image displayed next to the title of the quick reply.
+func (q QuickReply) WithImage(url string) QuickReply {
+	q.ImageURL = url
+	return q
+}
+
+// DecodePayload decodes a payload set by WithPayload into v.
+func (q QuickReply) DecodePayload(v interface{}) error {
+	return json.Unmarshal([]byte(q.Payload), v)
+}
+
+// checkQuickReplies makes sure replies can be sent with a message.
+func checkQuickReplies(replies []QuickReply) error {
+	if len(replies) > MaxQuickReplies {
+		return ErrTooManyQuickReplies
+	}
+	return nil
+}
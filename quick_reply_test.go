@@ -0,0 +1,48 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickReply_Payload(t *testing.T) {
+	type order struct {
+		ID    int    `json:"id"`
+		Size  string `json:"size"`
+		Extra bool   `json:"extra"`
+	}
+
+	q := NewQuickReply("Large").MustWithPayload(order{ID: 42, Size: "L"}).WithImage("https://example.com/l.png")
+	assert.Equal(t, QuickReplyText, q.ContentType)
+	assert.Equal(t, "Large", q.Title)
+	assert.Equal(t, `{"id":42,"size":"L","extra":false}`, q.Payload)
+	assert.Equal(t, "https://example.com/l.png", q.ImageURL)
+
+	var o order
+	require.NoError(t, q.DecodePayload(&o))
+	assert.Equal(t, order{ID: 42, Size: "L"}, o)
+
+	q, err := NewQuickReply("Small").WithPayload(order{ID: 43, Size: "S"})
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":43,"size":"S","extra":false}`, q.Payload)
+}
+
+func TestQuickReply_InvalidPayload(t *testing.T) {
+	q, err := NewQuickReply("Broken").WithPayload(make(chan int))
+	assert.Error(t, err)
+	assert.Empty(t, q.Payload)
+
+	assert.Panics(t, func() { NewQuickReply("Broken").MustWithPayload(func() {}) })
+}
+
+func TestResponse_TooManyQuickReplies(t *testing.T) {
+	replies := make([]QuickReply, MaxQuickReplies+1)
+	for i := range replies {
+		replies[i] = NewQuickReply("option")
+	}
+
+	r := &Response{}
+	assert.Equal(t, ErrTooManyQuickReplies, r.TextWithReplies("pick one", replies, ResponseType))
+}
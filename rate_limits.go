@@ -0,0 +1,93 @@
+package messenger
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimits is the usage of the rate limits of the Graph API, as last
+// reported by Facebook in the headers of its answers. Each usage is the JSON
+// of its header, giving the percentages of the limits consumed.
+// https://developers.facebook.com/docs/graph-api/overview/rate-limiting
+type RateLimits struct {
+	// AppUsage is the X-App-Usage header.
+	AppUsage string `json:"app_usage,omitempty"`
+	// PageUsage is the X-Page-Usage header.
+	PageUsage string `json:"page_usage,omitempty"`
+	// BusinessUseCaseUsage is the X-Business-Use-Case-Usage header.
+	BusinessUseCaseUsage string `json:"business_use_case_usage,omitempty"`
+	// Updated is when one of the usages was last reported.
+	Updated time.Time `json:"updated"`
+}
+
+// rateLimitTracker keeps the last RateLimits reported. The zero value is
+// ready to use.
+type rateLimitTracker struct {
+	mu     sync.Mutex
+	limits RateLimits
+}
+
+func (t *rateLimitTracker) observe(now time.Time, h http.Header) {
+	app := h.Get("X-App-Usage")
+	page := h.Get("X-Page-Usage")
+	buc := h.Get("X-Business-Use-Case-Usage")
+	if app == "" && page == "" && buc == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if app != "" {
+		t.limits.AppUsage = app
+	}
+	if page != "" {
+		t.limits.PageUsage = page
+	}
+	if buc != "" {
+		t.limits.BusinessUseCaseUsage = buc
+	}
+	t.limits.Updated = now
+}
+
+func (t *rateLimitTracker) snapshot() RateLimits {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limits
+}
+
+// rateLimitTransport reports the rate limit headers of the answers of the
+// Graph API to a rateLimitTracker.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	now     func() time.Time
+	tracker *rateLimitTracker
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if resp != nil {
+		t.tracker.observe(t.now(), resp.Header)
+	}
+	return resp, err
+}
+
+// withRateLimits returns a copy of client whose answers update the RateLimits
+// of the Messenger.
+func (m *Messenger) withRateLimits(client *http.Client) *http.Client {
+	t := &rateLimitTransport{base: client.Transport, now: m.now, tracker: &m.rateLimits}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+
+	c := *client
+	c.Transport = t
+	return &c
+}
+
+// RateLimits returns the usage of the rate limits of the Graph API last
+// reported by Facebook.
+func (m *Messenger) RateLimits() RateLimits {
+	return m.rateLimits.snapshot()
+}
@@ -0,0 +1,50 @@
+package messenger
+
+// Sender actions reacting to messages.
+const (
+	// ReactAction reacts to a message.
+	ReactAction = "react"
+	// UnreactAction removes the reaction to a message.
+	UnreactAction = "unreact"
+)
+
+// Reaction is a reaction to a message.
+type Reaction string
+
+// Reactions which can be sent. Instagram only supports ReactionLove.
+const (
+	ReactionSmile Reaction = "smile"
+	ReactionAngry Reaction = "angry"
+	ReactionSad   Reaction = "sad"
+	ReactionWow   Reaction = "wow"
+	ReactionLove  Reaction = "love"
+	ReactionLike  Reaction = "like"
+)
+
+// SenderActionPayload is the payload of the sender actions about a message.
+type SenderActionPayload struct {
+	// MessageID is the ID of the message the action is about.
+	MessageID string `json:"message_id"`
+	// Reaction is the reaction of a ReactAction.
+	Reaction Reaction `json:"reaction,omitempty"`
+}
+
+// React reacts to the message mid of the user.
+func (r *Response) React(mid string, reaction Reaction) error {
+	return r.DispatchMessage(&SendSenderAction{
+		Recipient:    r.to,
+		SenderAction: ReactAction,
+		PersonaID:    r.persona,
+		Payload:      &SenderActionPayload{MessageID: mid, Reaction: reaction},
+	})
+}
+
+// Unreact removes the reaction to the message mid of the user.
+func (r *Response) Unreact(mid string) error {
+	return r.DispatchMessage(&SendSenderAction{
+		Recipient:    r.to,
+		SenderAction: UnreactAction,
+		PersonaID:    r.persona,
+		Payload:      &SenderActionPayload{MessageID: mid},
+	})
+}
@@ -0,0 +1,21 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_React(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+	r := m.Response(fixturePSID)
+
+	require.NoError(t, r.React("m_abc", ReactionLove))
+	require.NoError(t, r.Unreact("m_abc"))
+
+	require.Equal(t, 2, graph.count())
+	assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"sender_action":"react","payload":{"message_id":"m_abc","reaction":"love"}}`, graph.bodies[0])
+	assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"sender_action":"unreact","payload":{"message_id":"m_abc"}}`, graph.bodies[1])
+}
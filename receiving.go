@@ -1,14 +1,34 @@
 package messenger
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// Objects of a Receive.
+const (
+	// ObjectPage is the object of Messenger webhooks.
+	ObjectPage = "page"
+	// ObjectInstagram is the object of Instagram messaging webhooks.
+	ObjectInstagram = "instagram"
+)
 
 // Receive is the format in which webhook events are sent.
 type Receive struct {
-	// Object should always be `page`. (I don't quite understand why)
+	// Object is ObjectPage for Messenger and ObjectInstagram for Instagram
+	// messaging.
 	Object string `json:"object"`
 	// Entry is all of the different messenger types which were
 	// sent in this event.
 	Entry []Entry `json:"entry"`
+
+	// owned is set while a pooled Receive is in use.
+	owned bool
 }
 
 // Entry is a batch of events which were sent in this webhook trigger.
@@ -19,6 +39,20 @@ type Entry struct {
 	Time int64 `json:"time"`
 	// Messaging is the events that were sent in this Entry
 	Messaging []MessageInfo `json:"messaging"`
+	// Standby is the events of conversations controlled by another app,
+	// sent to secondary receivers in the handover protocol.
+	Standby []MessageInfo `json:"standby,omitempty"`
+	// Changes is the changes to the fields the page is subscribed to, such
+	// as its Commerce orders.
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Change is a change to a field the page is subscribed to.
+type Change struct {
+	// Field is the name of the field, such as "commerce_order".
+	Field string `json:"field"`
+	// Value describes the change, depending on Field.
+	Value json.RawMessage `json:"value"`
 }
 
 // MessageInfo is an event that is fired by the webhook.
@@ -45,6 +79,21 @@ type MessageInfo struct {
 	ReferralMessage *ReferralMessage `json:"referral"`
 
 	AccountLinking *AccountLinking `json:"account_linking"`
+
+	PassThreadControl *PassThreadControl `json:"pass_thread_control"`
+
+	RequestThreadControl *RequestThreadControl `json:"request_thread_control"`
+
+	Payment *Payment `json:"payment"`
+
+	PreCheckout *PreCheckout `json:"pre_checkout"`
+
+	// surface is where the event came from.
+	surface Surface
+	// unknown are the fields of an UnknownAction event, and raw the event
+	// itself.
+	unknown []string
+	raw     []byte
 }
 
 type OptIn struct {
@@ -56,6 +105,14 @@ type OptIn struct {
 	Time time.Time `json:"-"`
 	// Ref is the reference as given
 	Ref string `json:"ref"`
+	// Type is OneTimeNotificationType when the user asked to be notified
+	// after a Response.OneTimeNotificationRequest.
+	Type string `json:"type,omitempty"`
+	// Payload is the payload of the one-time notification request.
+	Payload string `json:"payload,omitempty"`
+	// OneTimeNotifToken is the token to send the one-time notification
+	// with, see Recipient.OneTimeNotifToken.
+	OneTimeNotifToken string `json:"one_time_notif_token,omitempty"`
 }
 
 // ReferralMessage represents referral endpoint
@@ -75,19 +132,108 @@ type Referral struct {
 	// Data originally passed in the ref param
 	Ref string `json:"ref"`
 	// Source type
-	Source string `json:"source"`
+	Source ReferralSource `json:"source"`
 	// The identifier dor the referral
 	Type string `json:"type"`
 }
 
+// ReferralSource is where a user following a Referral came from.
+type ReferralSource string
+
+// Sources of a Referral.
+const (
+	// ReferralSourceShortlink is an m.me link with a ref parameter.
+	ReferralSourceShortlink ReferralSource = "SHORTLINK"
+	// ReferralSourceAds is a click-to-Messenger ad.
+	ReferralSourceAds ReferralSource = "ADS"
+	// ReferralSourceCustomerChat is the customer chat plugin of a website.
+	ReferralSourceCustomerChat ReferralSource = "CUSTOMER_CHAT_PLUGIN"
+	// ReferralSourceMessengerCode is a scanned Messenger code.
+	ReferralSourceMessengerCode ReferralSource = "MESSENGER_CODE"
+	// ReferralSourceDiscoverTab is the Discover tab of Messenger.
+	ReferralSourceDiscoverTab ReferralSource = "DISCOVER_TAB"
+)
+
+// IsFromAd reports whether the user came from a click-to-Messenger ad.
+func (r *Referral) IsFromAd() bool {
+	return r != nil && r.Source == ReferralSourceAds
+}
+
+// IsFromShortlink reports whether the user came from an m.me link.
+func (r *Referral) IsFromShortlink() bool {
+	return r != nil && r.Source == ReferralSourceShortlink
+}
+
+// IsFromCustomerChat reports whether the user came from the customer chat
+// plugin.
+func (r *Referral) IsFromCustomerChat() bool {
+	return r != nil && r.Source == ReferralSourceCustomerChat
+}
+
 // Sender is who the message was sent from.
 type Sender struct {
 	ID int64 `json:"id,string"`
 }
 
-// Recipient is who the message was sent to.
+// Recipient is who the message was sent to. When sending, exactly one of its
+// fields must be set.
 type Recipient struct {
+	// ID is the page-scoped ID of the user.
 	ID int64 `json:"id,string"`
+	// PhoneNumber is the phone number of a user who has not messaged the page
+	// yet, requires the pages_messaging_phone_number permission.
+	PhoneNumber string `json:"phone_number,omitempty"`
+	// UserRef is the reference of a user from the checkbox plugin.
+	UserRef string `json:"user_ref,omitempty"`
+	// CommentID is the ID of a post comment, for private replies.
+	CommentID string `json:"comment_id,omitempty"`
+	// OneTimeNotifToken is the token of a one-time notification, which can
+	// be sent once outside of the 24 hour window.
+	OneTimeNotifToken string `json:"one_time_notif_token,omitempty"`
+}
+
+// MarshalJSON encodes only the field identifying the recipient, so that the
+// payload is accepted by Facebook. The ID is used when no other field is set.
+func (r Recipient) MarshalJSON() ([]byte, error) {
+	fields := map[string]string{}
+	if r.PhoneNumber != "" {
+		fields["phone_number"] = r.PhoneNumber
+	}
+	if r.UserRef != "" {
+		fields["user_ref"] = r.UserRef
+	}
+	if r.CommentID != "" {
+		fields["comment_id"] = r.CommentID
+	}
+	if r.OneTimeNotifToken != "" {
+		fields["one_time_notif_token"] = r.OneTimeNotifToken
+	}
+
+	if len(fields) > 1 || (len(fields) == 1 && r.ID != 0) {
+		return nil, xerrors.Errorf("ambiguous recipient %s: more than one field set", r)
+	}
+	if len(fields) == 0 {
+		fields["id"] = strconv.FormatInt(r.ID, 10)
+	}
+
+	return json.Marshal(fields)
+}
+
+// String describes the recipient for logging. One-time notification tokens
+// are secrets, so only a hash of them is included.
+func (r Recipient) String() string {
+	switch {
+	case r.PhoneNumber != "":
+		return "phone_number:" + r.PhoneNumber
+	case r.UserRef != "":
+		return "user_ref:" + r.UserRef
+	case r.CommentID != "":
+		return "comment_id:" + r.CommentID
+	case r.OneTimeNotifToken != "":
+		sum := sha256.Sum256([]byte(r.OneTimeNotifToken))
+		return "one_time_notif_token:" + hex.EncodeToString(sum[:8])
+	}
+	return "id:" + strconv.FormatInt(r.ID, 10)
 }
 
 // Attachment is a file which used in a message.
@@ -108,6 +254,8 @@ type QuickReply struct {
 	Title string `json:"title,omitempty"`
 	// Payload is the  reply information
 	Payload string `json:"payload"`
+	// ImageURL is the image displayed next to the title
+	ImageURL string `json:"image_url,omitempty"`
 }
 
 // Payload is the information on where an attachment is.
@@ -0,0 +1,61 @@
+package messenger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecipient_MarshalJSON(t *testing.T) {
+	for name, test := range map[string]struct {
+		recipient Recipient
+		wire      string
+		str       string
+	}{
+		// the wire format of previous versions, which only had an ID
+		"id":                    {Recipient{ID: 1254459154682919}, `{"id":"1254459154682919"}`, "id:1254459154682919"},
+		"empty":                 {Recipient{}, `{"id":"0"}`, "id:0"},
+		"phone number":          {Recipient{PhoneNumber: "+16505551234"}, `{"phone_number":"+16505551234"}`, "phone_number:+16505551234"},
+		"user ref":              {Recipient{UserRef: "checkbox-ref"}, `{"user_ref":"checkbox-ref"}`, "user_ref:checkbox-ref"},
+		"comment id":            {Recipient{CommentID: "123_456"}, `{"comment_id":"123_456"}`, "comment_id:123_456"},
+		"one-time notification": {Recipient{OneTimeNotifToken: "otn"}, `{"one_time_notif_token":"otn"}`, "one_time_notif_token:89576ee45b624c6f"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(test.recipient)
+			require.NoError(t, err)
+			assert.Equal(t, test.wire, string(data))
+			assert.Equal(t, test.str, test.recipient.String())
+
+			var decoded Recipient
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, test.recipient, decoded)
+		})
+	}
+
+	t.Run("one-time notifications queue apart", func(t *testing.T) {
+		a := outboxKey(Recipient{OneTimeNotifToken: "otn-a"})
+		b := outboxKey(Recipient{OneTimeNotifToken: "otn-b"})
+		assert.NotEqual(t, a, b)
+		assert.NotContains(t, a, "otn-a")
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		_, err := json.Marshal(Recipient{ID: 1, UserRef: "ref"})
+		assert.Error(t, err)
+
+		_, err = json.Marshal(Recipient{PhoneNumber: "+16505551234", CommentID: "123_456"})
+		assert.Error(t, err)
+	})
+
+	t.Run("in a message", func(t *testing.T) {
+		data, err := json.Marshal(SendMessage{
+			MessagingType: UpdateType,
+			Recipient:     Recipient{UserRef: "checkbox-ref"},
+			Message:       MessageData{Text: "hi"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `{"messaging_type":"UPDATE","recipient":{"user_ref":"checkbox-ref"},"message":{"text":"hi"}}`, string(data))
+	})
+}
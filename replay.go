@@ -0,0 +1,24 @@
+package messenger
+
+import "time"
+
+// staleEntry reports whether the time of entry is further than
+// Options.MaxEventAge from now, in either direction, which is how replayed
+// payloads show.
+func (m *Messenger) staleEntry(entry Entry) bool {
+	if m.maxEventAge <= 0 {
+		return false
+	}
+
+	skew := m.now().Sub(time.Unix(0, entry.Time*int64(time.Millisecond)))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew <= m.maxEventAge {
+		return false
+	}
+
+	// Replays can be large, so rejections are only counted.
+	m.stats.rejectStale()
+	return true
+}
@@ -0,0 +1,36 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_MaxEventAge(t *testing.T) {
+	clock := newFakeClock() // The time of the fixtures.
+	m := New(Options{Clock: clock, MaxEventAge: 5 * time.Minute})
+
+	var texts []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		texts = append(texts, msg.Text)
+	})
+
+	serveFixture(t, m, "message_text.json")
+	clock.Advance(4 * time.Minute)
+	serveFixture(t, m, "message_text.json")
+	assert.Len(t, texts, 2)
+	assert.EqualValues(t, 0, m.EventStats().StaleEntries)
+
+	// Replayed later.
+	clock.Advance(2 * time.Minute)
+	serveFixture(t, m, "message_text.json")
+	assert.Len(t, texts, 2)
+	assert.EqualValues(t, 1, m.EventStats().StaleEntries)
+
+	// Dated in the future.
+	clock.Advance(-time.Hour)
+	serveFixture(t, m, "batched.json")
+	assert.Len(t, texts, 2)
+	assert.EqualValues(t, 3, m.EventStats().StaleEntries)
+}
@@ -2,6 +2,7 @@ package messenger
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"image"
@@ -12,7 +13,9 @@ import (
 	"net/http"
 	"net/textproto"
 	"strings"
+	"sync"
 
+	"github.com/paked/messenger/graph"
 	"golang.org/x/xerrors"
 )
 
@@ -27,6 +30,16 @@ const (
 	SendMessageURL = "https://graph.facebook.com/v2.11/me/messages"
 	// ThreadControlURL is the API endpoint for passing thread control.
 	ThreadControlURL = "https://graph.facebook.com/v2.6/me/pass_thread_control"
+	// TakeThreadControlURL is the API endpoint for taking thread control.
+	TakeThreadControlURL = "https://graph.facebook.com/v2.6/me/take_thread_control"
+	// RequestThreadControlURL is the API endpoint for requesting thread
+	// control.
+	RequestThreadControlURL = "https://graph.facebook.com/v2.6/me/request_thread_control"
+	// SecondaryReceiversURL is the API endpoint for listing the secondary
+	// receivers of the page.
+	SecondaryReceiversURL = "https://graph.facebook.com/v2.6/me/secondary_receivers"
+	// ThreadOwnerURL is the API endpoint for querying the owner of a thread.
+	ThreadOwnerURL = "https://graph.facebook.com/v2.6/me/thread_owner"
 	// InboxPageID is managed by facebook for secondary pass to inbox features: https://developers.facebook.com/docs/messenger-platform/handover-protocol/pass-thread-control
 	InboxPageID = 263902037430900
 
@@ -61,60 +74,99 @@ const (
 
 // QueryResponse is the response sent back by Facebook when setting up things
 // like greetings or call-to-actions
-type QueryResponse struct {
-	Error  *QueryError `json:"error,omitempty"`
-	Result string      `json:"result,omitempty"`
-}
+type QueryResponse = graph.QueryResponse
 
 // QueryError is representing an error sent back by Facebook
-type QueryError struct {
-	Message      string `json:"message"`
-	Type         string `json:"type"`
-	Code         int    `json:"code"`
-	ErrorSubcode int    `json:"error_subcode"`
-	FBTraceID    string `json:"fbtrace_id"`
-}
+type QueryError = graph.QueryError
 
-// QueryError implements error
-func (e QueryError) Error() string {
-	return e.Message
+// Response is used for responding to events with messages. Its recipient is
+// fixed when it is created, and a Response is safe for concurrent use by
+// multiple goroutines.
+type Response struct {
+	token     string
+	to        Recipient
+	messenger *Messenger
+	client    *http.Client
+	persona   string
+	ctx       context.Context
+	replies   *int32
+	surface   Surface
+	report    *sendReport
+
+	mu     sync.Mutex
+	typing *Typing
 }
 
-func checkFacebookError(r io.Reader) error {
-	var err error
-
-	qr := QueryResponse{}
-	err = json.NewDecoder(r).Decode(&qr)
-	if err != nil {
-		return xerrors.Errorf("json unmarshal error: %w", err)
-	}
-	if qr.Error != nil {
-		return xerrors.Errorf("facebook error: %w", qr.Error)
-	}
-
-	return nil
+// ResponseOptions are the settings used when creating a standalone Response.
+type ResponseOptions struct {
+	// Token is the access token of the Facebook page to send messages from.
+	Token string
+	// Recipient is who the messages are sent to.
+	Recipient Recipient
+	// HTTPClient is used for all calls to the Graph API. Defaults to
+	// http.DefaultClient.
+	HTTPClient *http.Client
+	// PersonaID, if set, is the persona the messages are sent as.
+	PersonaID string
 }
 
-// Response is used for responding to events with messages.
-type Response struct {
-	token string
-	to    Recipient
+// NewResponse creates a Response which can be used without a Messenger, for
+// example to send messages from a queue consumer.
+func NewResponse(opts ResponseOptions) *Response {
+	return &Response{
+		token:   opts.Token,
+		to:      opts.Recipient,
+		client:  opts.HTTPClient,
+		persona: opts.PersonaID,
+	}
 }
 
 // SetToken is for using DispatchMessage from outside.
+//
+// Deprecated: create the Response with NewResponse instead, which sets the
+// token once and for all.
 func (r *Response) SetToken(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.token = token
 }
 
+// accessToken returns the token used to send messages.
+func (r *Response) accessToken() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.token
+}
+
 // Text sends a textual message.
 func (r *Response) Text(message string, messagingType MessagingType, tags ...string) error {
 	return r.TextWithReplies(message, nil, messagingType, tags...)
 }
 
+// SendText sends a textual message like Text, and returns the answer of the
+// Send API, whose MessageID identifies the message in deliveries, reads and
+// echoes.
+func (r *Response) SendText(message string, messagingType MessagingType, tags ...string) (SendResponse, error) {
+	return r.SendTextWithReplies(message, nil, messagingType, tags...)
+}
+
 // TextWithReplies sends a textual message with some replies
 // messagingType should be one of the following: "RESPONSE","UPDATE","MESSAGE_TAG","NON_PROMOTIONAL_SUBSCRIPTION"
 // only supply tags when messagingType == "MESSAGE_TAG" (see https://developers.facebook.com/docs/messenger-platform/send-messages#messaging_types for more)
 func (r *Response) TextWithReplies(message string, replies []QuickReply, messagingType MessagingType, tags ...string) error {
+	_, err := r.SendTextWithReplies(message, replies, messagingType, tags...)
+	return err
+}
+
+// SendTextWithReplies sends a textual message with some replies like
+// TextWithReplies, and returns the answer of the Send API.
+func (r *Response) SendTextWithReplies(message string, replies []QuickReply, messagingType MessagingType, tags ...string) (SendResponse, error) {
+	if err := checkQuickReplies(replies); err != nil {
+		return SendResponse{}, err
+	}
+
 	var tag string
 	if len(tags) > 0 {
 		tag = tags[0]
@@ -128,13 +180,25 @@ func (r *Response) TextWithReplies(message string, replies []QuickReply, messagi
 			Attachment:   nil,
 			QuickReplies: replies,
 		},
-		Tag: tag,
+		Tag:       tag,
+		PersonaID: r.persona,
 	}
-	return r.DispatchMessage(&m)
+	return r.Dispatch(&m)
 }
 
 // AttachmentWithReplies sends a attachment message with some replies
 func (r *Response) AttachmentWithReplies(attachment *StructuredMessageAttachment, replies []QuickReply, messagingType MessagingType, tags ...string) error {
+	_, err := r.SendAttachmentWithReplies(attachment, replies, messagingType, tags...)
+	return err
+}
+
+// SendAttachmentWithReplies sends an attachment message with some replies
+// like AttachmentWithReplies, and returns the answer of the Send API.
+func (r *Response) SendAttachmentWithReplies(attachment *StructuredMessageAttachment, replies []QuickReply, messagingType MessagingType, tags ...string) (SendResponse, error) {
+	if err := checkQuickReplies(replies); err != nil {
+		return SendResponse{}, err
+	}
+
 	var tag string
 	if len(tags) > 0 {
 		tag = tags[0]
@@ -147,9 +211,10 @@ func (r *Response) AttachmentWithReplies(attachment *StructuredMessageAttachment
 			Attachment:   attachment,
 			QuickReplies: replies,
 		},
-		Tag: tag,
+		Tag:       tag,
+		PersonaID: r.persona,
 	}
-	return r.DispatchMessage(&m)
+	return r.Dispatch(&m)
 }
 
 // Image sends an image.
@@ -165,6 +230,13 @@ func (r *Response) Image(im image.Image) error {
 
 // Attachment sends an image, sound, video or a regular file to a chat.
 func (r *Response) Attachment(dataType AttachmentType, url string, messagingType MessagingType, tags ...string) error {
+	_, err := r.SendAttachment(dataType, url, messagingType, tags...)
+	return err
+}
+
+// SendAttachment sends the file at url like Attachment, and returns the
+// answer of the Send API.
+func (r *Response) SendAttachment(dataType AttachmentType, url string, messagingType MessagingType, tags ...string) (SendResponse, error) {
 	var tag string
 	if len(tags) > 0 {
 		tag = tags[0]
@@ -181,9 +253,10 @@ func (r *Response) Attachment(dataType AttachmentType, url string, messagingType
 				},
 			},
 		},
-		Tag: tag,
+		Tag:       tag,
+		PersonaID: r.persona,
 	}
-	return r.DispatchMessage(&m)
+	return r.Dispatch(&m)
 }
 
 // copied from multipart package
@@ -206,10 +279,20 @@ func createFormFile(filename string, w *multipart.Writer, contentType string) (i
 
 // AttachmentData sends an image, sound, video or a regular file to a chat via an io.Reader.
 func (r *Response) AttachmentData(dataType AttachmentType, filename string, filedata io.Reader) error {
+	_, err := r.SendAttachmentData(dataType, filename, filedata)
+	return err
+}
+
+// SendAttachmentData uploads a file like AttachmentData, and returns the
+// answer of the Send API.
+func (r *Response) SendAttachmentData(dataType AttachmentType, filename string, filedata io.Reader) (SendResponse, error) {
+	if dataType == FileAttachment && r.Surface() == SurfaceInstagram {
+		return SendResponse{}, xerrors.Errorf("file attachment: %w", ErrUnsupportedOnSurface)
+	}
 
 	filedataBytes, err := ioutil.ReadAll(filedata)
 	if err != nil {
-		return err
+		return SendResponse{}, err
 	}
 	contentType := http.DetectContentType(filedataBytes[:512])
 	fmt.Println("Content-type detected:", contentType)
@@ -218,38 +301,58 @@ func (r *Response) AttachmentData(dataType AttachmentType, filename string, file
 	multipartWriter := multipart.NewWriter(&body)
 	data, err := createFormFile(filename, multipartWriter, contentType)
 	if err != nil {
-		return err
+		return SendResponse{}, err
 	}
 
 	_, err = bytes.NewBuffer(filedataBytes).WriteTo(data)
 	if err != nil {
-		return err
+		return SendResponse{}, err
 	}
 
-	multipartWriter.WriteField("recipient", fmt.Sprintf(`{"id":"%v"}`, r.to.ID))
-	multipartWriter.WriteField("message", fmt.Sprintf(`{"attachment":{"type":"%v", "payload":{}}}`, dataType))
-
-	req, err := http.NewRequest("POST", SendMessageURL, &body)
+	recipient, err := json.Marshal(r.to)
 	if err != nil {
-		return err
+		return SendResponse{}, err
 	}
 
-	req.URL.RawQuery = "access_token=" + r.token
-
-	req.Header.Set("Content-Type", multipartWriter.FormDataContentType())
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
+	multipartWriter.WriteField("recipient", string(recipient))
+	multipartWriter.WriteField("message", fmt.Sprintf(`{"attachment":{"type":"%v", "payload":{}}}`, dataType))
+	if r.persona != "" {
+		multipartWriter.WriteField("persona_id", r.persona)
 	}
-	defer resp.Body.Close()
 
-	return checkFacebookError(resp.Body)
+	var (
+		sent   SendResponse
+		answer []byte
+	)
+	err = r.graphCall("POST", SendMessageURL, graph.RawBody{
+		ContentType: multipartWriter.FormDataContentType(),
+		Data:        body.Bytes(),
+	}, &answer)
+	if err == nil {
+		json.Unmarshal(answer, &sent)
+	}
+	r.noteSent(filename, err)
+	if r.messenger != nil {
+		message := map[string]interface{}{
+			"attachment": map[string]interface{}{
+				"type":     dataType,
+				"filename": filename,
+			},
+		}
+		r.messenger.afterSend(r.to, message, err)
+	}
+	return sent, err
 }
 
 // ButtonTemplate sends a message with the main contents being button elements
 func (r *Response) ButtonTemplate(text string, buttons *[]StructuredMessageButton, messagingType MessagingType, tags ...string) error {
+	_, err := r.SendButtonTemplate(text, buttons, messagingType, tags...)
+	return err
+}
+
+// SendButtonTemplate sends a button template like ButtonTemplate, and
+// returns the answer of the Send API.
+func (r *Response) SendButtonTemplate(text string, buttons *[]StructuredMessageButton, messagingType MessagingType, tags ...string) (SendResponse, error) {
 	var tag string
 	if len(tags) > 0 {
 		tag = tags[0]
@@ -269,14 +372,22 @@ func (r *Response) ButtonTemplate(text string, buttons *[]StructuredMessageButto
 				},
 			},
 		},
-		Tag: tag,
+		Tag:       tag,
+		PersonaID: r.persona,
 	}
 
-	return r.DispatchMessage(&m)
+	return r.Dispatch(&m)
 }
 
 // GenericTemplate is a message which allows for structural elements to be sent
 func (r *Response) GenericTemplate(elements *[]StructuredMessageElement, messagingType MessagingType, tags ...string) error {
+	_, err := r.SendGenericTemplate(elements, messagingType, tags...)
+	return err
+}
+
+// SendGenericTemplate sends a generic template like GenericTemplate, and
+// returns the answer of the Send API.
+func (r *Response) SendGenericTemplate(elements *[]StructuredMessageElement, messagingType MessagingType, tags ...string) (SendResponse, error) {
 	var tag string
 	if len(tags) > 0 {
 		tag = tags[0]
@@ -295,13 +406,21 @@ func (r *Response) GenericTemplate(elements *[]StructuredMessageElement, messagi
 				},
 			},
 		},
-		Tag: tag,
+		Tag:       tag,
+		PersonaID: r.persona,
 	}
-	return r.DispatchMessage(&m)
+	return r.Dispatch(&m)
 }
 
 // ListTemplate sends a list of elements
 func (r *Response) ListTemplate(elements *[]StructuredMessageElement, messagingType MessagingType, tags ...string) error {
+	_, err := r.SendListTemplate(elements, messagingType, tags...)
+	return err
+}
+
+// SendListTemplate sends a list template like ListTemplate, and returns
+// the answer of the Send API.
+func (r *Response) SendListTemplate(elements *[]StructuredMessageElement, messagingType MessagingType, tags ...string) (SendResponse, error) {
 	var tag string
 	if len(tags) > 0 {
 		tag = tags[0]
@@ -321,9 +440,10 @@ func (r *Response) ListTemplate(elements *[]StructuredMessageElement, messagingT
 				},
 			},
 		},
-		Tag: tag,
+		Tag:       tag,
+		PersonaID: r.persona,
 	}
-	return r.DispatchMessage(&m)
+	return r.Dispatch(&m)
 }
 
 // SenderAction sends a info about sender action
@@ -331,73 +451,102 @@ func (r *Response) SenderAction(action string) error {
 	m := SendSenderAction{
 		Recipient:    r.to,
 		SenderAction: action,
+		PersonaID:    r.persona,
 	}
 	return r.DispatchMessage(&m)
 }
 
+// SendResponse is the answer of the Send API to a message which was sent.
+type SendResponse struct {
+	// RecipientID is the page-scoped ID of the user the message was sent
+	// to.
+	RecipientID string `json:"recipient_id"`
+	// MessageID is the mid of the message, as found in the deliveries,
+	// reads and echoes about it.
+	MessageID string `json:"message_id,omitempty"`
+}
+
 // DispatchMessage posts the message to messenger, return the error if there's any
 func (r *Response) DispatchMessage(m interface{}) error {
-	data, err := json.Marshal(m)
-	if err != nil {
-		return err
-	}
+	_, err := r.Dispatch(m)
+	return err
+}
 
-	req, err := http.NewRequest("POST", SendMessageURL, bytes.NewBuffer(data))
-	if err != nil {
-		return err
+// Dispatch posts the message to messenger like DispatchMessage, and returns
+// the answer of the Send API. The SendResponse is empty for messages which
+// were queued or offloaded.
+func (r *Response) Dispatch(m interface{}) (SendResponse, error) {
+	if r.messenger != nil && r.messenger.shouldOffload(m) {
+		return SendResponse{}, r.offload(m.(*SendMessage))
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.URL.RawQuery = "access_token=" + r.token
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+	var sent SendResponse
+	m, err := r.adaptForSurface(m)
+	if err == nil && r.messenger != nil {
+		err = r.messenger.beforeSend(r.Context(), r.to, m)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode == 200 {
-		return nil
+	if err == nil {
+		sent, err = r.send(m)
 	}
-	return checkFacebookError(resp.Body)
+	if err == nil && !isSenderAction(m) {
+		r.stopTyping()
+	}
+	r.noteSent(m, err)
+	if r.messenger != nil {
+		r.messenger.afterSend(r.to, m, err)
+	}
+	return sent, err
 }
 
-// PassThreadToInbox Uses Messenger Handover Protocol for live inbox
-// https://developers.facebook.com/docs/messenger-platform/handover-protocol/#inbox
-func (r *Response) PassThreadToInbox() error {
-	p := passThreadControl{
-		Recipient:   r.to,
-		TargetAppID: InboxPageID,
-		Metadata:    "Passing to inbox secondary app",
+// postMessage posts an encoded message to the Send API.
+func (r *Response) postMessage(data []byte) (SendResponse, error) {
+	var (
+		sent   SendResponse
+		answer []byte
+	)
+	if err := r.graphCall("POST", SendMessageURL, json.RawMessage(data), &answer); err != nil {
+		return sent, err
 	}
 
-	data, err := json.Marshal(p)
-	if err != nil {
-		return err
-	}
+	// The message was sent even if the answer can not be decoded.
+	json.Unmarshal(answer, &sent)
+	return sent, nil
+}
 
-	req, err := http.NewRequest("POST", ThreadControlURL, bytes.NewBuffer(data))
-	if err != nil {
-		return err
+func isSenderAction(m interface{}) bool {
+	switch m.(type) {
+	case SendSenderAction, *SendSenderAction:
+		return true
 	}
+	return false
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.URL.RawQuery = "access_token=" + r.token
+// PassThreadToInbox Uses Messenger Handover Protocol for live inbox
+// https://developers.facebook.com/docs/messenger-platform/handover-protocol/#inbox
+func (r *Response) PassThreadToInbox() error {
+	return r.PassThreadControl(InboxPageID, "Passing to inbox secondary app")
+}
 
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return err
+// PassThreadControl passes control of the conversation to another app, such
+// as a live chat tool, using the handover protocol.
+// https://developers.facebook.com/docs/messenger-platform/handover-protocol/pass-thread-control
+func (r *Response) PassThreadControl(targetAppID int64, metadata string) error {
+	p := passThreadControl{
+		Recipient:   r.to,
+		TargetAppID: targetAppID,
+		Metadata:    metadata,
 	}
-	defer resp.Body.Close()
 
-	return checkFacebookError(resp.Body)
+	return r.graphCall("POST", ThreadControlURL, p, nil)
 }
 
 // SendMessage is the information sent in an API request to Facebook.
 type SendMessage struct {
-	MessagingType MessagingType `json:"messaging_type"`
+	MessagingType MessagingType `json:"messaging_type,omitempty"`
 	Recipient     Recipient     `json:"recipient"`
 	Message       MessageData   `json:"message"`
 	Tag           string        `json:"tag,omitempty"`
+	PersonaID     string        `json:"persona_id,omitempty"`
 }
 
 // MessageData is a message consisting of text or an attachment, with an additional selection of optional quick replies.
@@ -405,19 +554,22 @@ type MessageData struct {
 	Text         string                       `json:"text,omitempty"`
 	Attachment   *StructuredMessageAttachment `json:"attachment,omitempty"`
 	QuickReplies []QuickReply                 `json:"quick_replies,omitempty"`
+	Metadata     string                       `json:"metadata,omitempty"`
 }
 
 // SendStructuredMessage is a structured message template.
 type SendStructuredMessage struct {
-	MessagingType MessagingType         `json:"messaging_type"`
+	MessagingType MessagingType         `json:"messaging_type,omitempty"`
 	Recipient     Recipient             `json:"recipient"`
 	Message       StructuredMessageData `json:"message"`
 	Tag           string                `json:"tag,omitempty"`
+	PersonaID     string                `json:"persona_id,omitempty"`
 }
 
 // StructuredMessageData is an attachment sent with a structured message.
 type StructuredMessageData struct {
 	Attachment StructuredMessageAttachment `json:"attachment"`
+	Metadata   string                      `json:"metadata,omitempty"`
 }
 
 // StructuredMessageAttachment is the attachment of a structured message.
@@ -479,6 +631,8 @@ type StructuredMessageButton struct {
 
 // SendSenderAction is the information about sender action
 type SendSenderAction struct {
-	Recipient    Recipient `json:"recipient"`
-	SenderAction string    `json:"sender_action"`
+	Recipient    Recipient            `json:"recipient"`
+	SenderAction string               `json:"sender_action"`
+	PersonaID    string               `json:"persona_id,omitempty"`
+	Payload      *SenderActionPayload `json:"payload,omitempty"`
 }
@@ -0,0 +1,83 @@
+package messenger
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResponse_Concurrent is meant to be run with the race detector.
+func TestResponse_Concurrent(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{Token: "token", HTTPClient: graph.client()})
+	r := m.Response(111)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, r.Text("hello", ResponseType))
+		}()
+		go func() {
+			defer wg.Done()
+			typing, err := r.TypingOn(true)
+			if assert.NoError(t, err) {
+				assert.NoError(t, typing.Stop())
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			r.SetToken("token")
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, r.WithPersona("persona").SenderAction(MarkSeenAction))
+		}()
+	}
+	wg.Wait()
+
+	// typing_off is not sent when a message has already cleared the indicator
+	assert.True(t, graph.count() >= 30)
+}
+
+func TestResponse_Dispatch(t *testing.T) {
+	graph := newFakeGraph(`{"recipient_id":"1254459154682919","message_id":"m_sent"}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	sent, err := m.Response(fixturePSID).Dispatch(&SendMessage{
+		MessagingType: ResponseType,
+		Recipient:     Recipient{ID: fixturePSID},
+		Message:       MessageData{Text: "hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SendResponse{RecipientID: "1254459154682919", MessageID: "m_sent"}, sent)
+
+	sent, err = m.Response(fixturePSID).SendText("hello", ResponseType)
+	require.NoError(t, err)
+	assert.Equal(t, "m_sent", sent.MessageID)
+
+	sent, err = m.Response(fixturePSID).SendButtonTemplate("Pick one", &[]StructuredMessageButton{
+		{Type: ButtonPostback, Title: "Yes", Payload: "YES"},
+	}, ResponseType)
+	require.NoError(t, err)
+	assert.Equal(t, "m_sent", sent.MessageID)
+
+	sent, err = m.Response(fixturePSID).SendAttachmentData(ImageAttachment, "pixel.png", bytes.NewReader(make([]byte, 512)))
+	require.NoError(t, err)
+	assert.Equal(t, "m_sent", sent.MessageID)
+
+	graph.status = http.StatusBadRequest
+	graph.response = `{"error":{"message":"Invalid parameter","code":100}}`
+	sent, err = m.Response(fixturePSID).Dispatch(&SendMessage{
+		MessagingType: ResponseType,
+		Recipient:     Recipient{ID: fixturePSID},
+		Message:       MessageData{Text: "hello"},
+	})
+	assert.Error(t, err)
+	assert.Equal(t, SendResponse{}, sent)
+}
@@ -0,0 +1,36 @@
+package messenger
+
+import "net/http"
+
+// Route is an HTTP route registered by the Messenger.
+type Route struct {
+	// Path is the pattern the route is registered with on the mux.
+	Path string
+	// Methods are the HTTP methods the route answers.
+	Methods []string
+}
+
+// Routes returns the routes the Messenger registered on its mux.
+func (m *Messenger) Routes() []Route {
+	return []Route{{Path: m.webhookURL, Methods: []string{"GET", "POST"}}}
+}
+
+// Mux returns the mux the Messenger registered its routes on, which is the
+// Mux set in Options if there was one.
+func (m *Messenger) Mux() *http.ServeMux {
+	return m.mux
+}
+
+// WebhookHandlerFunc returns the handler of webhook requests, answering the
+// verification requests and receiving the events, for use without the mux
+// returned by Handler. It does not check the path of the requests.
+func (m *Messenger) WebhookHandlerFunc() http.HandlerFunc {
+	return m.handle
+}
+
+// VerifyHandlerFunc returns the handler of webhook verification requests
+// alone, for mounting on a separate path. It applies the
+// VerifyRequestPolicy and calls OnVerify like the webhook handler.
+func (m *Messenger) VerifyHandlerFunc() http.HandlerFunc {
+	return m.handleVerify
+}
@@ -0,0 +1,39 @@
+package messenger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_WebhookHandlerFunc(t *testing.T) {
+	mux := http.NewServeMux()
+	m := New(Options{Mux: mux, WebhookURL: "/webhook", VerifyToken: "secret"})
+
+	assert.Equal(t, []Route{{Path: "/webhook", Methods: []string{"GET", "POST"}}}, m.Routes())
+	assert.Equal(t, mux, m.Mux())
+
+	var texts []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		texts = append(texts, msg.Text)
+	})
+
+	// Mounted elsewhere, without the mux.
+	server := http.NewServeMux()
+	server.Handle("/hooks/messenger", m.WebhookHandlerFunc())
+
+	f, err := os.Open("testdata/webhooks/message_text.json")
+	require.NoError(t, err)
+	defer f.Close()
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("POST", "/hooks/messenger", f))
+	assert.Equal(t, []string{"hello, world!"}, texts)
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest("GET", "/hooks/messenger?hub.mode=subscribe&hub.verify_token=secret&hub.challenge=42", nil))
+	assert.Equal(t, "42\n", w.Body.String())
+}
@@ -0,0 +1,203 @@
+package messenger
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// jsonSchemaDialect is the version of JSON Schema produced by JSONSchema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaTypes are the types whose schemas are returned by JSONSchemas: the
+// webhook events, the payloads sent to Facebook and the records of the
+// transcripts.
+var schemaTypes = []interface{}{
+	Receive{},
+	MessageInfo{},
+	Message{},
+	Delivery{},
+	Read{},
+	PostBack{},
+	OptIn{},
+	ReferralMessage{},
+	AccountLinking{},
+	PassThreadControl{},
+	RequestThreadControl{},
+	Payment{},
+	PreCheckout{},
+	SendMessage{},
+	SendStructuredMessage{},
+	SendTemplateMessage{},
+	SendSenderAction{},
+	TranscriptRecord{},
+}
+
+// JSONSchemas returns the JSON Schemas of the webhook events, of the payloads
+// sent to Facebook and of transcript records, keyed by the name of their Go
+// type, so that consumers in other languages can validate them.
+func JSONSchemas() (map[string]json.RawMessage, error) {
+	schemas := make(map[string]json.RawMessage, len(schemaTypes))
+	for _, v := range schemaTypes {
+		s, err := JSONSchema(v)
+		if err != nil {
+			return nil, err
+		}
+		schemas[reflect.TypeOf(v).Name()] = s
+	}
+	return schemas, nil
+}
+
+// JSONSchema returns the JSON Schema of the JSON encoding of values of the
+// type of v, as produced by encoding/json. Structs are described by their
+// exported fields; no property is required.
+func JSONSchema(v interface{}) (json.RawMessage, error) {
+	g := schemaGenerator{defs: make(map[string]interface{})}
+	root, err := g.schema(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	root["$schema"] = jsonSchemaDialect
+	if len(g.defs) > 0 {
+		root["$defs"] = g.defs
+	}
+	return json.Marshal(root)
+}
+
+// schemaGenerator builds schemas, defining every named struct once.
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *schemaGenerator) schema(t reflect.Type) (map[string]interface{}, error) {
+	if t == nil {
+		return map[string]interface{}{}, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case rawMessageType:
+		return map[string]interface{}{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+		}
+		items, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": []string{"array", "null"}, "items": items}, nil
+	case reflect.Map:
+		values, err := g.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": []string{"object", "null"}, "additionalProperties": values}, nil
+	case reflect.Struct:
+		return g.structRef(t)
+	}
+
+	return nil, xerrors.Errorf("no JSON schema for %s", t)
+}
+
+// structRef returns a reference to the definition of a struct, adding the
+// definition on first use. Anonymous structs are inlined.
+func (g *schemaGenerator) structRef(t reflect.Type) (map[string]interface{}, error) {
+	if t.Name() == "" {
+		return g.object(t)
+	}
+
+	ref := map[string]interface{}{"$ref": "#/$defs/" + t.Name()}
+	if _, ok := g.defs[t.Name()]; ok {
+		return ref, nil
+	}
+
+	// Reserve the name first, for recursive types.
+	g.defs[t.Name()] = nil
+	def, err := g.object(t)
+	if err != nil {
+		return nil, err
+	}
+	g.defs[t.Name()] = def
+	return ref, nil
+}
+
+// object describes the properties of a struct, including those of its
+// embedded structs.
+func (g *schemaGenerator) object(t reflect.Type) (map[string]interface{}, error) {
+	properties := make(map[string]interface{})
+	if err := g.properties(t, properties); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}, nil
+}
+
+func (g *schemaGenerator) properties(t reflect.Type, properties map[string]interface{}) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if err := g.properties(ft, properties); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s, err := g.schema(f.Type)
+		if err != nil {
+			return xerrors.Errorf("%s.%s: %w", t.Name(), f.Name, err)
+		}
+		for _, o := range strings.Split(opts, ",") {
+			if o == "string" {
+				s = map[string]interface{}{"type": "string"}
+			}
+		}
+		properties[name] = s
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package messenger
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema(MessageInfo{})
+	require.NoError(t, err)
+
+	var schema struct {
+		Schema string                            `json:"$schema"`
+		Ref    string                            `json:"$ref"`
+		Defs   map[string]map[string]interface{} `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "#/$defs/MessageInfo", schema.Ref)
+
+	info := schema.Defs["MessageInfo"]["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"$ref": "#/$defs/Message"}, info["message"])
+	assert.Equal(t, map[string]interface{}{"type": "integer"}, info["timestamp"])
+
+	// The fields of embedded structs are promoted, those tagged "-" skipped
+	// and those encoded as strings described so.
+	referral := schema.Defs["ReferralMessage"]["properties"].(map[string]interface{})
+	assert.Contains(t, referral, "ref")
+	assert.NotContains(t, referral, "Sender")
+	sender := schema.Defs["Sender"]["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string"}, sender["id"])
+
+	message := schema.Defs["Message"]["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{}, message["nlp"])
+	assert.Equal(t, "array", message["attachments"].(map[string]interface{})["type"].([]interface{})[0])
+}
+
+func TestJSONSchemas(t *testing.T) {
+	schemas, err := JSONSchemas()
+	require.NoError(t, err)
+	assert.Len(t, schemas, len(schemaTypes))
+	assert.Contains(t, schemas, "SendMessage")
+	assert.Contains(t, schemas, "TranscriptRecord")
+}
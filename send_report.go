@@ -0,0 +1,96 @@
+package messenger
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SendCall is a Graph API call made by a Response.
+type SendCall struct {
+	// Endpoint is the URL called, without its query.
+	Endpoint string
+	// MessageID is the mid of the message sent by the call, if any.
+	MessageID string
+	// Latency is how long the call took.
+	Latency time.Duration
+	// Err is why the call failed, nil if it succeeded.
+	Err error
+}
+
+// SendReport describes the Graph API calls made by helpers which may make
+// several of them, such as Carousel, TypingOn followed by Text, or
+// AttachmentData, in order. Messages which were queued in the Outbox or
+// offloaded are not part of it.
+type SendReport struct {
+	Calls []SendCall
+}
+
+// MessageIDs returns the mids of the messages sent, in order.
+func (s SendReport) MessageIDs() []string {
+	var ids []string
+	for _, c := range s.Calls {
+		if c.MessageID != "" {
+			ids = append(ids, c.MessageID)
+		}
+	}
+	return ids
+}
+
+// Latency returns the time spent in the calls.
+func (s SendReport) Latency() time.Duration {
+	var total time.Duration
+	for _, c := range s.Calls {
+		total += c.Latency
+	}
+	return total
+}
+
+// Report calls f with a copy of r recording the Graph API calls it makes,
+// and returns them along with the error of f:
+//
+//	report, err := r.Report(func(r *Response) error {
+//		return r.Carousel(ctx, elements, ResponseType)
+//	})
+func (r *Response) Report(f func(r *Response) error) (SendReport, error) {
+	c := r.clone()
+	c.report = &sendReport{}
+	err := f(c)
+	return c.report.get(), err
+}
+
+// sendReport collects the calls of a SendReport, which may be made from
+// several goroutines, such as the one refreshing a typing indicator.
+type sendReport struct {
+	mu    sync.Mutex
+	calls []SendCall
+}
+
+// add records a call to endpoint, whose answer was stored into v.
+func (s *sendReport) add(endpoint string, latency time.Duration, v interface{}, err error) {
+	if s == nil {
+		return
+	}
+
+	call := SendCall{
+		Endpoint: strings.SplitN(endpoint, "?", 2)[0],
+		Latency:  latency,
+		Err:      err,
+	}
+	if answer, ok := v.(*[]byte); ok && err == nil {
+		var sent SendResponse
+		json.Unmarshal(*answer, &sent)
+		call.MessageID = sent.MessageID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func (s *sendReport) get() SendReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SendReport{Calls: append([]SendCall(nil), s.calls...)}
+}
@@ -0,0 +1,47 @@
+package messenger
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponse_Report(t *testing.T) {
+	graph := newFakeGraph(`{"recipient_id":"1254459154682919","message_id":"m1"}`)
+	m := New(Options{HTTPClient: graph.client()})
+	r := m.Response(fixturePSID)
+
+	elements := make([]StructuredMessageElement, 12)
+	report, err := r.Report(func(r *Response) error {
+		if _, err := r.TypingOn(false); err != nil {
+			return err
+		}
+		return r.Carousel(context.Background(), elements, ResponseType)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, report.Calls, 3)
+	for _, c := range report.Calls {
+		assert.Equal(t, SendMessageURL, c.Endpoint)
+		assert.NoError(t, c.Err)
+	}
+	assert.Equal(t, []string{"m1", "m1", "m1"}, report.MessageIDs())
+	assert.Equal(t, report.Calls[0].Latency+report.Calls[1].Latency+report.Calls[2].Latency, report.Latency())
+
+	// Calls made outside of Report are not recorded.
+	require.NoError(t, r.Text("hello", ResponseType))
+	assert.Len(t, report.Calls, 3)
+
+	graph.status = http.StatusBadRequest
+	graph.response = `{"error":{"message":"(#100) No matching user found","code":100}}`
+	report, err = r.Report(func(r *Response) error {
+		return r.Text("hello", ResponseType)
+	})
+	require.Error(t, err)
+	require.Len(t, report.Calls, 1)
+	assert.Equal(t, err, report.Calls[0].Err)
+	assert.Empty(t, report.MessageIDs())
+}
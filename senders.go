@@ -0,0 +1,68 @@
+package messenger
+
+import "sync"
+
+// senderFilter decides which senders the Messenger handles events from.
+type senderFilter struct {
+	mu      sync.RWMutex
+	allowed map[int64]bool
+	blocked map[int64]bool
+	filter  func(psid int64) bool
+}
+
+// set configures the senders allowed and blocked from the start.
+func (f *senderFilter) set(allowed, blocked []int64, filter func(psid int64) bool) {
+	f.filter = filter
+	f.blocked = make(map[int64]bool)
+	for _, id := range blocked {
+		f.blocked[id] = true
+	}
+
+	if len(allowed) > 0 {
+		f.allowed = make(map[int64]bool)
+		for _, id := range allowed {
+			f.allowed[id] = true
+		}
+	}
+}
+
+// enabled reports whether some senders are filtered out.
+func (f *senderFilter) enabled() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.allowed != nil || len(f.blocked) > 0 || f.filter != nil
+}
+
+func (f *senderFilter) allows(psid int64) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.blocked[psid] {
+		return false
+	}
+	if f.allowed != nil && !f.allowed[psid] {
+		return false
+	}
+	return f.filter == nil || f.filter(psid)
+}
+
+// BlockSender ignores all further events from a user, such as an abusive
+// one.
+func (m *Messenger) BlockSender(psid int64) {
+	m.senders.mu.Lock()
+	defer m.senders.mu.Unlock()
+
+	if m.senders.blocked == nil {
+		m.senders.blocked = make(map[int64]bool)
+	}
+	m.senders.blocked[psid] = true
+}
+
+// UnblockSender handles events from a user blocked with BlockSender or
+// Options.BlockedSenders again.
+func (m *Messenger) UnblockSender(psid int64) {
+	m.senders.mu.Lock()
+	defer m.senders.mu.Unlock()
+
+	delete(m.senders.blocked, psid)
+}
@@ -0,0 +1,46 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_Senders(t *testing.T) {
+	tests := []struct {
+		name    string
+		options Options
+		handled bool
+	}{
+		{name: "default", handled: true},
+		{name: "allowed", options: Options{AllowedSenders: []int64{fixturePSID}}, handled: true},
+		{name: "not allowed", options: Options{AllowedSenders: []int64{111}}},
+		{name: "blocked", options: Options{BlockedSenders: []int64{fixturePSID}}},
+		{name: "filtered", options: Options{SenderFilter: func(psid int64) bool { return psid != fixturePSID }}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := New(test.options)
+			handled := false
+			m.HandleMessage(func(Message, *Response) { handled = true })
+
+			serveFixture(t, m, "message_text.json")
+			assert.Equal(t, test.handled, handled)
+		})
+	}
+}
+
+func TestMessenger_BlockSender(t *testing.T) {
+	m := New(Options{})
+	var handled int
+	m.HandleMessage(func(Message, *Response) { handled++ })
+
+	m.BlockSender(fixturePSID)
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 0, handled)
+
+	m.UnblockSender(fixturePSID)
+	serveFixture(t, m, "message_text.json")
+	assert.Equal(t, 1, handled)
+}
@@ -0,0 +1,101 @@
+package messenger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// SignatureHeader is the header carrying the SHA1 signature of webhook
+	// requests.
+	SignatureHeader = "X-Hub-Signature"
+	// SignatureSHA256Header is the header carrying the SHA256 signature of
+	// webhook requests.
+	SignatureSHA256Header = "X-Hub-Signature-256"
+)
+
+// VerifySignature checks that header, the value of a signature header such
+// as "sha1=<hex digest>", is the signature of body using appSecret. Both sha1
+// and sha256 signatures are supported.
+func VerifySignature(appSecret string, body []byte, header string) error {
+	if appSecret == "" {
+		return xerrors.New("missing app secret")
+	}
+
+	sig := strings.SplitN(header, "=", 2)
+	if len(sig) == 1 {
+		if sig[0] == "" {
+			return xerrors.New("missing signature")
+		}
+		return xerrors.Errorf("malformed signature: %v", header)
+	}
+
+	var h func() hash.Hash
+	switch strings.ToLower(sig[0]) {
+	case "sha1":
+		h = sha1.New
+	case "sha256":
+		h = sha256.New
+	default:
+		return xerrors.Errorf("unknown signature encoding, expected sha1 or sha256: %s", sig[0])
+	}
+
+	expected, err := hex.DecodeString(sig[1])
+	if err != nil {
+		return xerrors.Errorf("invalid signature: %s", sig[1])
+	}
+
+	mac := hmac.New(h, []byte(appSecret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return xerrors.Errorf("invalid signature: %s", sig[1])
+	}
+
+	return nil
+}
+
+// VerifySignatureMiddleware only lets through requests signed with appSecret,
+// answering others with 401 Unauthorized. The SHA256 signature is checked
+// when present, the SHA1 one otherwise. It can protect the webhooks of any
+// Facebook object, not only those handled by Messenger.
+func VerifySignatureMiddleware(appSecret string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "could not read request", http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+
+		if err := verifyRequestSignature(appSecret, body, r.Header); err != nil {
+			http.Error(w, "could not verify request", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// verifyRequestSignature checks the strongest signature carried by header.
+func verifyRequestSignature(appSecret string, body []byte, header http.Header) error {
+	if sig := header.Get(SignatureSHA256Header); sig != "" {
+		if err := VerifySignature(appSecret, body, sig); err != nil {
+			return xerrors.Errorf("%s header: %w", SignatureSHA256Header, err)
+		}
+		return nil
+	}
+
+	if err := VerifySignature(appSecret, body, header.Get(SignatureHeader)); err != nil {
+		return xerrors.Errorf("%s header: %w", SignatureHeader, err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package messenger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(h func() hash.Hash, secret string, body []byte) string {
+	mac := hmac.New(h, []byte(secret))
+	mac.Write(body)
+	return fmt.Sprintf("%x", mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(testPayload)
+
+	assert.NoError(t, VerifySignature("secret", body, "sha1="+sign(sha1.New, "secret", body)))
+	assert.NoError(t, VerifySignature("secret", body, "SHA256="+sign(sha256.New, "secret", body)))
+
+	assert.Error(t, VerifySignature("", body, "sha1="+sign(sha1.New, "", body)))
+	assert.Error(t, VerifySignature("secret", body, ""))
+	assert.Error(t, VerifySignature("secret", body, "sha1"))
+	assert.Error(t, VerifySignature("secret", body, "md5="+sign(sha1.New, "secret", body)))
+	assert.Error(t, VerifySignature("secret", body, "sha1=not-hex"))
+	assert.Error(t, VerifySignature("secret", body, "sha1="+sign(sha1.New, "other", body)))
+}
+
+func TestVerifySignatureMiddleware(t *testing.T) {
+	body := []byte(testPayload)
+	h := VerifySignatureMiddleware("secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for name, test := range map[string]struct {
+		header http.Header
+		code   int
+	}{
+		"sha1":     {http.Header{SignatureHeader: {"sha1=" + sign(sha1.New, "secret", body)}}, http.StatusNoContent},
+		"sha256":   {http.Header{SignatureSHA256Header: {"sha256=" + sign(sha256.New, "secret", body)}}, http.StatusNoContent},
+		"missing":  {http.Header{}, http.StatusUnauthorized},
+		"mismatch": {http.Header{SignatureHeader: {"sha1=" + sign(sha1.New, "other", body)}}, http.StatusUnauthorized},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+			req.Header = test.header
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			assert.Equal(t, test.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package messenger
+
+import (
+	"context"
+	"time"
+)
+
+// StandbyEvent is an event of a conversation controlled by another app in
+// the handover protocol, received on the standby channel by secondary
+// receivers.
+type StandbyEvent struct {
+	// Action is the kind of event, which says which of the fields of
+	// MessageInfo is set.
+	Action Action
+	MessageInfo
+}
+
+// StandbyHandler is a handler used to observe the conversations controlled
+// by another app. Its Response can not send messages until the app takes
+// thread control.
+type StandbyHandler func(StandbyEvent, *Response)
+
+// HandleStandby adds a new StandbyHandler to the Messenger which will be
+// triggered for every event received on the standby channel. The page must
+// be subscribed to the standby field.
+func (m *Messenger) HandleStandby(f StandbyHandler) {
+	m.standbyHandlers = append(m.standbyHandlers, f)
+}
+
+// HandleStandbyMessage adds a new MessageHandler to the Messenger which will
+// be triggered for every message received on the standby channel.
+func (m *Messenger) HandleStandbyMessage(f MessageHandler) {
+	m.HandleStandby(func(e StandbyEvent, r *Response) {
+		if e.Action != TextAction {
+			return
+		}
+
+		message := *e.Message
+		message.Sender = e.Sender
+		message.Recipient = e.Recipient
+		message.Time = time.Unix(e.Timestamp/int64(time.Microsecond), 0)
+		f(message, r)
+	})
+}
+
+// dispatchStandby triggers the standby handlers of the standby events of an
+// entry.
+func (m *Messenger) dispatchStandby(ctx context.Context, object string, entry Entry) {
+	for _, info := range entry.Standby {
+		ctx := m.withCorrelationID(ctx)
+		info.surface = surfaceOf(object)
+		a := m.classify(info)
+		if a == UnknownAction {
+			m.noteUnknown(info.unknown, info.raw)
+			continue
+		}
+		if !m.senders.allows(info.Sender.ID) {
+			continue
+		}
+
+		resp := m.newResponse(Recipient{ID: info.Sender.ID})
+		resp.surface = info.surface
+		resp.ctx = ctx
+		e := StandbyEvent{Action: a, MessageInfo: info}
+		for _, f := range m.standbyHandlers {
+			f := f
+			m.invoke(a, resp, func(r *Response) { f(e, r) })
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_HandleStandby(t *testing.T) {
+	m := New(Options{})
+
+	var actions []Action
+	m.HandleStandby(func(e StandbyEvent, r *Response) {
+		actions = append(actions, e.Action)
+	})
+	var standby []Message
+	m.HandleStandbyMessage(func(msg Message, r *Response) {
+		standby = append(standby, msg)
+	})
+	var messages []Message
+	m.HandleMessage(func(msg Message, r *Response) {
+		messages = append(messages, msg)
+	})
+	serveFixture(t, m, "standby.json")
+
+	assert.Equal(t, []Action{TextAction}, actions)
+	assert.Empty(t, messages)
+	if assert.Len(t, standby, 1) {
+		assert.Equal(t, "are you there?", standby[0].Text)
+		assert.Equal(t, int64(fixturePSID), standby[0].Sender.ID)
+	}
+}
@@ -0,0 +1,156 @@
+package messenger
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// statsWindow is how far back EventsPerSecond looks.
+const statsWindow = 60
+
+// latencySamples is how many of the most recent handler latencies are kept.
+const latencySamples = 1024
+
+// EventStats describe the recent load of a Messenger, for autoscalers to
+// poll.
+type EventStats struct {
+	// EventsPerSecond is the average number of webhook events dispatched per
+	// second over the last minute.
+	EventsPerSecond float64 `json:"events_per_second"`
+	// Latency summarizes how long the handlers of the most recent events
+	// took.
+	Latency LatencySummary `json:"latency"`
+	// StaleEntries is the number of webhook entries rejected for being too
+	// old or too far in the future, see Options.MaxEventAge.
+	StaleEntries int64 `json:"stale_entries"`
+	// Phases summarizes how long the phases of the most recent webhook
+	// requests took.
+	Phases PhaseLatencies `json:"phases"`
+	// UnknownEvents is the number of events received with fields the
+	// Messenger does not support, by field. See Messenger.UnknownEvents.
+	UnknownEvents map[string]int64 `json:"unknown_events,omitempty"`
+	// OutboxDepth is the number of messages waiting in the Outbox.
+	OutboxDepth int `json:"outbox_depth"`
+	// RateLimits is the usage of the rate limits of the Graph API last
+	// reported by Facebook.
+	RateLimits RateLimits `json:"rate_limits"`
+}
+
+// LatencySummary summarizes a set of durations.
+type LatencySummary struct {
+	Count int           `json:"count"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// StatsProvider is implemented by Messenger. Autoscaling adapters should
+// depend on it rather than on Messenger, so that several bots can be
+// aggregated.
+type StatsProvider interface {
+	EventStats() EventStats
+}
+
+// eventStats counts events per second and keeps recent latencies.
+type eventStats struct {
+	mu      sync.Mutex
+	buckets [statsWindow]struct {
+		second int64
+		count  int
+	}
+	latencies [latencySamples]time.Duration
+	next      int
+	full      bool
+	stale     int64
+}
+
+func (s *eventStats) observe(at time.Time, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	second := at.Unix()
+	b := &s.buckets[second%statsWindow]
+	if b.second != second {
+		b.second = second
+		b.count = 0
+	}
+	b.count++
+
+	s.latencies[s.next] = latency
+	s.next = (s.next + 1) % latencySamples
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+func (s *eventStats) rejectStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stale++
+}
+
+func (s *eventStats) snapshot(now time.Time) EventStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events int
+	for _, b := range s.buckets {
+		if age := now.Unix() - b.second; age >= 0 && age < statsWindow {
+			events += b.count
+		}
+	}
+
+	n := s.next
+	if s.full {
+		n = latencySamples
+	}
+	latencies := make([]time.Duration, n)
+	copy(latencies, s.latencies[:n])
+
+	return EventStats{
+		EventsPerSecond: float64(events) / statsWindow,
+		Latency:         summarize(latencies),
+		StaleEntries:    s.stale,
+	}
+}
+
+// summarize sorts durations and summarizes them.
+func summarize(durations []time.Duration) LatencySummary {
+	if len(durations) == 0 {
+		return LatencySummary{}
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	percentile := func(p int) time.Duration {
+		return durations[(len(durations)-1)*p/100]
+	}
+
+	return LatencySummary{
+		Count: len(durations),
+		Mean:  total / time.Duration(len(durations)),
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P99:   percentile(99),
+		Max:   durations[len(durations)-1],
+	}
+}
+
+// EventStats returns the recent load of the Messenger.
+func (m *Messenger) EventStats() EventStats {
+	stats := m.stats.snapshot(m.now())
+	stats.Phases = m.phases.snapshot()
+	stats.UnknownEvents = m.unknownCounts()
+	stats.OutboxDepth = m.outboxDepth()
+	stats.RateLimits = m.RateLimits()
+	return stats
+}
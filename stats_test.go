@@ -0,0 +1,56 @@
+package messenger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessenger_EventStats(t *testing.T) {
+	clock := newFakeClock()
+	m := New(Options{Clock: clock})
+
+	var calls int
+	m.HandleMessage(func(Message, *Response) {
+		calls++
+		clock.Advance(time.Duration(calls) * 10 * time.Millisecond)
+	})
+
+	for i := 0; i < 3; i++ {
+		serveFixture(t, m, "message_text.json")
+	}
+
+	stats := m.EventStats()
+	assert.Equal(t, 3.0/60, stats.EventsPerSecond)
+	assert.Equal(t, LatencySummary{
+		Count: 3,
+		Mean:  20 * time.Millisecond,
+		P50:   20 * time.Millisecond,
+		P90:   20 * time.Millisecond,
+		P99:   20 * time.Millisecond,
+		Max:   30 * time.Millisecond,
+	}, stats.Latency)
+
+	clock.Advance(2 * time.Minute)
+	stats = m.EventStats()
+	assert.Equal(t, 0.0, stats.EventsPerSecond)
+	assert.Equal(t, 3, stats.Latency.Count)
+}
+
+func TestSummarize(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i > 0; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	s := summarize(durations)
+	assert.Equal(t, 100, s.Count)
+	assert.Equal(t, 50*time.Millisecond, s.P50)
+	assert.Equal(t, 90*time.Millisecond, s.P90)
+	assert.Equal(t, 99*time.Millisecond, s.P99)
+	assert.Equal(t, 100*time.Millisecond, s.Max)
+	assert.Equal(t, LatencySummary{}, summarize(nil))
+}
+
+var _ StatsProvider = (*Messenger)(nil)
@@ -0,0 +1,168 @@
+package messenger
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrNotFound is returned by a Store when a key does not exist.
+var ErrNotFound = xerrors.New("not found")
+
+// Store is a key-value store used to persist conversation data, such as
+// dialog sessions. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the value of key, or ErrNotFound.
+	Get(key string) ([]byte, error)
+	// Set sets the value of key.
+	Set(key string, value []byte) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// Keys returns the keys starting with prefix, in lexical order.
+	Keys(prefix string) ([]string, error)
+}
+
+// MemoryStore is a Store keeping its data in memory, for tests and bots which
+// do not need their data to survive restarts.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{data: make(map[string][]byte)}
+}
+
+// Get returns the value of key, or ErrNotFound.
+func (s *MemoryStore) Get(key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	value, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Set sets the value of key.
+func (s *MemoryStore) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data[key] = append([]byte(nil), value...)
+	return nil
+}
+
+// Delete removes key.
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+	return nil
+}
+
+// Keys returns the keys starting with prefix, in lexical order.
+func (s *MemoryStore) Keys(prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// FileStore is a Store keeping each value in its own file of a directory, so
+// that data survives restarts without a database.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a FileStore in dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// path returns the file of key. Names are prefixed so that no key can refer
+// to a special entry such as "..", or collide with temporary files.
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, "k"+url.PathEscape(key))
+}
+
+// Get returns the value of key, or ErrNotFound.
+func (s *FileStore) Get(key string) ([]byte, error) {
+	value, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Set sets the value of key. The value is written to a temporary file first,
+// so that a crash never leaves it half written.
+func (s *FileStore) Set(key string, value []byte) error {
+	f, err := ioutil.TempFile(s.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path(key))
+}
+
+// Delete removes key.
+func (s *FileStore) Delete(key string) error {
+	err := os.Remove(s.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Keys returns the keys starting with prefix, in lexical order.
+func (s *FileStore) Keys(prefix string) ([]string, error) {
+	files, err := ioutil.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), "k") {
+			continue
+		}
+
+		key, err := url.PathUnescape(f.Name()[1:])
+		if err != nil || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
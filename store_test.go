@@ -0,0 +1,33 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+
+	_, err := s.Get("a")
+	assert.Equal(t, ErrNotFound, err)
+
+	value := []byte("1")
+	require.NoError(t, s.Set("a", value))
+	value[0] = '2'
+
+	got, err := s.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), got)
+
+	require.NoError(t, s.Set("b", nil))
+	keys, err := s.Keys("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	require.NoError(t, s.Delete("a"))
+	require.NoError(t, s.Delete("a"))
+	_, err = s.Get("a")
+	assert.Equal(t, ErrNotFound, err)
+}
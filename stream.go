@@ -0,0 +1,230 @@
+package messenger
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultStreamInterval is the least time between two messages of a stream
+// when StreamOptions does not set its own.
+const DefaultStreamInterval = 2 * time.Second
+
+// DefaultStreamMinLength is the least number of characters in a message of a
+// stream, other than the last one, when StreamOptions does not set its own.
+const DefaultStreamMinLength = 80
+
+// StreamOptions control how streamed text is split into messages.
+type StreamOptions struct {
+	// Interval is the least time between two messages, so that users are not
+	// flooded with one message per token. Defaults to DefaultStreamInterval.
+	Interval time.Duration
+	// MinLength is the least number of characters of a message, other than
+	// the last one. Defaults to DefaultStreamMinLength.
+	MinLength int
+	// MessagingType is the messaging type of the messages. Defaults to
+	// ResponseType.
+	MessagingType MessagingType
+	// Tag is the tag of the messages when MessagingType is MessageTagType.
+	Tag string
+}
+
+// Stream sends text arriving on texts, such as the tokens of a language
+// model, as it comes. Messenger has no streaming messages, so the text is
+// sent as several messages split at paragraph or sentence boundaries, at
+// most one every Interval, with the typing indicator displayed in between.
+// It returns once texts is closed and everything has been sent, or ctx is
+// done.
+func (r *Response) Stream(ctx context.Context, texts <-chan string, opts StreamOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultStreamInterval
+	}
+	if opts.MinLength <= 0 {
+		opts.MinLength = DefaultStreamMinLength
+	}
+	if opts.MessagingType == "" {
+		opts.MessagingType = ResponseType
+	}
+	var tags []string
+	if opts.Tag != "" {
+		tags = append(tags, opts.Tag)
+	}
+
+	typing, err := r.TypingOn(true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		typing.Stop()
+	}()
+
+	send := func(text string) error {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil
+		}
+		if err := r.Text(text, opts.MessagingType, tags...); err != nil {
+			return xerrors.Errorf("could not send streamed text: %w", err)
+		}
+		return nil
+	}
+	// sendMore sends text while more is to come, turning the typing
+	// indicator back on.
+	sendMore := func(text string) error {
+		if err := send(text); err != nil {
+			return err
+		}
+		typing, err = r.TypingOn(true)
+		return err
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	var buf string
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case text, ok := <-texts:
+			if !ok {
+				for buf != "" {
+					var head string
+					head, buf = splitStream(buf, MaxTextLength, true)
+					if err := send(head); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			buf += text
+			// Text too long for a single message can't wait.
+			for TextLength(buf) > MaxTextLength {
+				var head string
+				head, buf = splitStream(buf, MaxTextLength, false)
+				if err := sendMore(head); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if TextLength(buf) < opts.MinLength {
+				continue
+			}
+
+			var head string
+			head, buf = splitStream(buf, MaxTextLength, false)
+			if head == "" {
+				continue
+			}
+			if err := sendMore(head); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamReader is Stream for text read from rd, such as the body of a
+// streaming HTTP response. It returns the first error from rd other than
+// io.EOF.
+func (r *Response) StreamReader(ctx context.Context, rd io.Reader, opts StreamOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	texts := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(texts)
+
+		buf := make([]byte, 512)
+		var pending []byte
+		for {
+			n, err := rd.Read(buf)
+			pending = append(pending, buf[:n]...)
+
+			// Keep an incomplete character for the next read.
+			valid := len(pending)
+			for i := 1; i < utf8.UTFMax && i <= len(pending); i++ {
+				if utf8.RuneStart(pending[len(pending)-i]) {
+					if !utf8.FullRune(pending[len(pending)-i:]) {
+						valid = len(pending) - i
+					}
+					break
+				}
+			}
+			if err != nil {
+				valid = len(pending)
+			}
+
+			if valid > 0 {
+				select {
+				case texts <- string(pending[:valid]):
+				case <-ctx.Done():
+					return
+				}
+				pending = pending[valid:]
+			}
+
+			if err != nil {
+				if err != io.EOF {
+					readErr <- err
+				}
+				return
+			}
+		}
+	}()
+
+	if err := r.Stream(ctx, texts, opts); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-readErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+// splitStream splits buf into the text to send now and the rest. The text to
+// send is at most limit characters long and ends at the last paragraph,
+// sentence or word boundary. Without a boundary nothing is sent, unless buf
+// is too long or final is set.
+func splitStream(buf string, limit int, final bool) (string, string) {
+	head := TruncateText(buf, limit)
+	if final && len(head) == len(buf) {
+		return buf, ""
+	}
+
+	if i := streamBoundary(head); i > 0 {
+		return buf[:i], buf[i:]
+	}
+
+	if len(head) < len(buf) {
+		return head, buf[len(head):]
+	}
+	return "", buf
+}
+
+// streamBoundary returns the index just after the last paragraph break,
+// sentence end or space of s, in that order of preference, or 0.
+func streamBoundary(s string) int {
+	if i := strings.LastIndex(s, "\n\n"); i > 0 {
+		return i + 2
+	}
+
+	for i := len(s) - 1; i > 0; i-- {
+		if (s[i] == ' ' || s[i] == '\n') && strings.IndexByte(".!?", s[i-1]) >= 0 {
+			return i + 1
+		}
+	}
+
+	if i := strings.LastIndexAny(s, " \n"); i > 0 {
+		return i + 1
+	}
+	return 0
+}
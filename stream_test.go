@@ -0,0 +1,98 @@
+package messenger
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentTexts returns the texts of the messages sent through graph, leaving out
+// sender actions.
+func sentTexts(t *testing.T, graph *fakeGraph) []string {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+
+	var texts []string
+	for _, body := range graph.bodies {
+		var m SendMessage
+		require.NoError(t, json.Unmarshal([]byte(body), &m))
+		if m.Message.Text != "" {
+			texts = append(texts, m.Message.Text)
+		}
+	}
+	return texts
+}
+
+func TestSplitStream(t *testing.T) {
+	tests := []struct {
+		buf, head, rest string
+		final           bool
+	}{
+		{buf: "Hello there. How are", head: "Hello there. ", rest: "How are"},
+		{buf: "First.\n\nSecond. Thi", head: "First.\n\n", rest: "Second. Thi"},
+		{buf: "no boundary", head: "no ", rest: "boundary"},
+		{buf: "word", head: "", rest: "word"},
+		{buf: "word", head: "word", rest: "", final: true},
+	}
+	for _, test := range tests {
+		head, rest := splitStream(test.buf, MaxTextLength, test.final)
+		assert.Equal(t, test.head, head, test.buf)
+		assert.Equal(t, test.rest, rest, test.buf)
+	}
+
+	long := strings.Repeat("a", MaxTextLength+10)
+	head, rest := splitStream(long, MaxTextLength, false)
+	assert.Len(t, head, MaxTextLength)
+	assert.Len(t, rest, 10)
+}
+
+func TestResponse_Stream(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	r := NewResponse(ResponseOptions{Recipient: Recipient{ID: 111}, HTTPClient: graph.client()})
+
+	texts := make(chan string)
+	go func() {
+		defer close(texts)
+		for _, token := range []string{"Paris ", "is the ", "capital. ", strings.Repeat("It is big. ", 200), "The end"} {
+			texts <- token
+		}
+	}()
+
+	require.NoError(t, r.Stream(context.Background(), texts, StreamOptions{Interval: time.Hour}))
+
+	sent := sentTexts(t, graph)
+	require.Len(t, sent, 2)
+	for _, text := range sent {
+		assert.True(t, TextLength(text) <= MaxTextLength)
+	}
+	assert.True(t, strings.HasPrefix(sent[0], "Paris is the capital. It is big."))
+	assert.True(t, strings.HasSuffix(sent[1], "It is big. The end"))
+
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+	assert.Contains(t, graph.bodies[0], TypingOnAction)
+}
+
+func TestResponse_StreamReader(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	r := NewResponse(ResponseOptions{Recipient: Recipient{ID: 111}, HTTPClient: graph.client()})
+
+	err := r.StreamReader(context.Background(), strings.NewReader("Bonjour, ça va ? 😀"), StreamOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bonjour, ça va ? 😀"}, sentTexts(t, graph))
+}
+
+func TestResponse_StreamCancel(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	r := NewResponse(ResponseOptions{Recipient: Recipient{ID: 111}, HTTPClient: graph.client()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := r.Stream(ctx, make(chan string), StreamOptions{})
+	assert.Equal(t, context.Canceled, err)
+}
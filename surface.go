@@ -0,0 +1,123 @@
+package messenger
+
+import "golang.org/x/xerrors"
+
+// Surface is the app through which users talk to the Page.
+type Surface string
+
+const (
+	// SurfaceMessenger is Facebook Messenger, the default.
+	SurfaceMessenger Surface = "messenger"
+	// SurfaceInstagram is Instagram messaging, which supports a subset of
+	// the messages Messenger does.
+	SurfaceInstagram Surface = "instagram"
+)
+
+// ErrUnsupportedOnSurface is returned when sending a message which the
+// surface of the recipient does not support, and which can not be adapted.
+var ErrUnsupportedOnSurface = xerrors.New("message not supported on this surface")
+
+// surfaceOf returns the surface of the events of a webhook object.
+func surfaceOf(object string) Surface {
+	if object == ObjectInstagram {
+		return SurfaceInstagram
+	}
+	return SurfaceMessenger
+}
+
+// Surface returns the surface the messages of r are sent to. A Response
+// passed to handlers answers on the surface the event came from.
+func (r *Response) Surface() Surface {
+	if r.surface == "" {
+		return SurfaceMessenger
+	}
+	return r.surface
+}
+
+// WithSurface returns a copy of r sending its messages to the given surface.
+func (r *Response) WithSurface(s Surface) *Response {
+	c := r.clone()
+	c.surface = s
+	return c
+}
+
+// adaptForSurface returns msg if the surface of r supports it, or else a
+// variant of it which the surface supports: Instagram gets the button
+// template as text with quick replies. It fails with
+// ErrUnsupportedOnSurface if there is no such variant.
+func (r *Response) adaptForSurface(msg interface{}) (interface{}, error) {
+	if r.Surface() != SurfaceInstagram {
+		return msg, nil
+	}
+
+	switch m := msg.(type) {
+	case *SendMessage:
+		if err := instagramQuickReplies(m.Message.QuickReplies); err != nil {
+			return msg, err
+		}
+		if m.Message.Attachment != nil {
+			return msg, instagramAttachment(m.Message.Attachment)
+		}
+	case *SendStructuredMessage:
+		p := m.Message.Attachment.Payload
+		if p.TemplateType == "button" {
+			return buttonsAsQuickReplies(m)
+		}
+		return msg, instagramAttachment(&m.Message.Attachment)
+	case *SendTemplateMessage:
+		return msg, xerrors.Errorf("template: %w", ErrUnsupportedOnSurface)
+	}
+	return msg, nil
+}
+
+// instagramQuickReplies checks that Instagram supports replies: only text
+// quick replies are.
+func instagramQuickReplies(replies []QuickReply) error {
+	for _, qr := range replies {
+		if qr.ContentType != QuickReplyText {
+			return xerrors.Errorf("%s quick reply: %w", qr.ContentType, ErrUnsupportedOnSurface)
+		}
+	}
+	return nil
+}
+
+// instagramAttachment checks that Instagram supports a: files and templates
+// other than the generic template are not.
+func instagramAttachment(a *StructuredMessageAttachment) error {
+	if a.Type == FileAttachment {
+		return xerrors.Errorf("file attachment: %w", ErrUnsupportedOnSurface)
+	}
+	if a.Type == "template" && a.Payload.TemplateType != "generic" {
+		return xerrors.Errorf("%s template: %w", a.Payload.TemplateType, ErrUnsupportedOnSurface)
+	}
+	return nil
+}
+
+// buttonsAsQuickReplies turns a button template made of postback buttons
+// into a text with quick replies.
+func buttonsAsQuickReplies(m *SendStructuredMessage) (interface{}, error) {
+	var buttons []StructuredMessageButton
+	if m.Message.Attachment.Payload.Buttons != nil {
+		buttons = *m.Message.Attachment.Payload.Buttons
+	}
+
+	replies := make([]QuickReply, 0, len(buttons))
+	for _, b := range buttons {
+		if b.Type != "postback" {
+			return m, xerrors.Errorf("button template with %s button: %w", b.Type, ErrUnsupportedOnSurface)
+		}
+		replies = append(replies, QuickReply{ContentType: QuickReplyText, Title: b.Title, Payload: b.Payload})
+	}
+
+	return &SendMessage{
+		MessagingType: m.MessagingType,
+		Recipient:     m.Recipient,
+		Message: MessageData{
+			Text:         m.Message.Attachment.Payload.Text,
+			QuickReplies: replies,
+			Metadata:     m.Message.Metadata,
+		},
+		Tag:       m.Tag,
+		PersonaID: m.PersonaID,
+	}, nil
+}
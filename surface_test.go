@@ -0,0 +1,69 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestResponse_SurfaceInstagram(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client()})
+
+	var surfaces []Surface
+	m.HandleMessage(func(msg Message, r *Response) {
+		surfaces = append(surfaces, r.Surface())
+	})
+	for _, name := range []string{"messengertest/corpus/instagram_message_text.json", "testdata/webhooks/message_text.json"} {
+		f, err := os.Open(name)
+		require.NoError(t, err)
+		m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", f))
+		f.Close()
+	}
+	assert.Equal(t, []Surface{SurfaceInstagram, SurfaceMessenger}, surfaces)
+
+	r := m.Response(111).WithSurface(SurfaceInstagram)
+
+	// Button templates of postbacks become quick replies.
+	require.NoError(t, r.ButtonTemplate("Pick a size", &[]StructuredMessageButton{
+		{Type: "postback", Title: "Small", Payload: "S"},
+		{Type: "postback", Title: "Large", Payload: "L"},
+	}, ResponseType))
+	var sent SendMessage
+	require.NoError(t, json.Unmarshal([]byte(graph.bodies[0]), &sent))
+	assert.Equal(t, MessageData{Text: "Pick a size", QuickReplies: []QuickReply{
+		{ContentType: QuickReplyText, Title: "Small", Payload: "S"},
+		{ContentType: QuickReplyText, Title: "Large", Payload: "L"},
+	}}, sent.Message)
+
+	for _, send := range []func() error{
+		func() error {
+			return r.ButtonTemplate("Visit", &[]StructuredMessageButton{{Type: "web_url", Title: "Site", URL: "https://example.com"}}, ResponseType)
+		},
+		func() error {
+			return r.ListTemplate(&[]StructuredMessageElement{{Title: "a"}, {Title: "b"}}, ResponseType)
+		},
+		func() error {
+			return r.Attachment(FileAttachment, "https://example.com/a.pdf", ResponseType)
+		},
+		func() error {
+			return r.AttachmentData(FileAttachment, "a.pdf", strings.NewReader("%PDF"))
+		},
+		func() error {
+			return r.TextWithReplies("Where?", []QuickReply{{ContentType: "location"}}, ResponseType)
+		},
+	} {
+		assert.True(t, xerrors.Is(send(), ErrUnsupportedOnSurface))
+	}
+	assert.Equal(t, 1, graph.count())
+
+	// Messenger keeps the templates.
+	require.NoError(t, m.Response(111).ButtonTemplate("Visit", &[]StructuredMessageButton{{Type: "web_url", Title: "Site", URL: "https://example.com"}}, ResponseType))
+	assert.Contains(t, graph.bodies[1], `"template_type":"button"`)
+}
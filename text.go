@@ -0,0 +1,77 @@
+package messenger
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextLength counts the characters of s the way Facebook does, that is in
+// UTF-16 code units, so that most emoji count as two characters.
+func TextLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeLength(r)
+	}
+	return n
+}
+
+// SanitizeText removes the characters Facebook rejects from s: invalid
+// UTF-8, control characters other than newlines and tabs, and Unicode
+// noncharacters. Carriage returns are normalized to newlines.
+//
+// It is useful when echoing user generated content back, which would
+// otherwise sporadically fail with error code 100.
+func SanitizeText(s string) string {
+	s = strings.Replace(s, "\r\n", "\n", -1)
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i, r := range s {
+		switch {
+		case r == utf8.RuneError:
+			if _, size := utf8.DecodeRuneInString(s[i:]); size == 1 {
+				continue
+			}
+			b.WriteRune(r)
+		case r == '\r':
+			b.WriteRune('\n')
+		case r == '\n' || r == '\t':
+			b.WriteRune(r)
+		case unicode.IsControl(r), isNoncharacter(r):
+			continue
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// TruncateText shortens s to at most limit characters, as counted by
+// TextLength, without splitting a character in two.
+func TruncateText(s string, limit int) string {
+	n := 0
+	for i, r := range s {
+		n += runeLength(r)
+		if n > limit {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// isNoncharacter reports whether r is one of the code points permanently
+// reserved by Unicode for internal use.
+func isNoncharacter(r rune) bool {
+	return (r >= 0xFDD0 && r <= 0xFDEF) || r&0xFFFE == 0xFFFE
+}
+
+// runeLength is the number of UTF-16 code units needed to encode r.
+func runeLength(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,29 @@
+package messenger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextLength(t *testing.T) {
+	assert.Equal(t, 5, TextLength("hello"))
+	assert.Equal(t, 5, TextLength("héllo"))
+	assert.Equal(t, 2, TextLength("😀"))
+	assert.Equal(t, 7, TextLength("👍🏽 ok"))
+}
+
+func TestSanitizeText(t *testing.T) {
+	assert.Equal(t, "line\nline\ttab", SanitizeText("line\r\nline\ttab"))
+	assert.Equal(t, "bell", SanitizeText("be\x07ll"))
+	assert.Equal(t, "invalid", SanitizeText("inv\xffalid"))
+	assert.Equal(t, "nonchar", SanitizeText("non\uFFFEchar\uFDD0"))
+	assert.Equal(t, "emoji 😀 kept", SanitizeText("emoji 😀 kept"))
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "hello", TruncateText("hello", 10))
+	assert.Equal(t, "hel", TruncateText("hello", 3))
+	assert.Equal(t, "a", TruncateText("a😀", 2))
+	assert.Equal(t, "a😀", TruncateText("a😀", 3))
+}
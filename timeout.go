@@ -0,0 +1,151 @@
+package messenger
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ErrHandlerTimeout is recorded when a handler does not return within
+// Options.HandlerTimeout.
+var ErrHandlerTimeout = xerrors.New("handler timed out")
+
+// HandlerTimeout describes a handler invocation which did not return within
+// Options.HandlerTimeout.
+type HandlerTimeout struct {
+	// Action is the kind of event the handler was called for.
+	Action Action
+	// PSID is the user who sent the event.
+	PSID int64
+	// Timeout is how long the handler was given.
+	Timeout time.Duration
+}
+
+// Context returns the context of the Response. For a Response passed to a
+// handler it carries the values of the context of the webhook request, but
+// is not done when the request ends, so that handlers can keep sending from
+// other goroutines. It is done once the handler times out, see
+// Options.HandlerTimeout. Messages sent by the Response are cancelled when
+// it is done.
+func (r *Response) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// WithContext returns a copy of r using ctx.
+func (r *Response) WithContext(ctx context.Context) *Response {
+	c := r.clone()
+	c.ctx = ctx
+	return c
+}
+
+// detachedContext is a context with the values of its parent, which is never
+// done.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+// detach returns a context with the values of ctx but not its cancellation.
+func detach(ctx context.Context) context.Context {
+	return detachedContext{ctx}
+}
+
+// handlerContext is the context of a handler run with a timeout. Unlike a
+// context.WithTimeout it is only done when the handler is abandoned, not
+// once its deadline passes after it returned, so that goroutines started by
+// the handler can still send.
+type handlerContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+	once     sync.Once
+	err      error
+}
+
+func newHandlerContext(parent context.Context, timeout time.Duration) *handlerContext {
+	return &handlerContext{
+		Context:  parent,
+		deadline: time.Now().Add(timeout),
+		done:     make(chan struct{}),
+	}
+}
+
+func (c *handlerContext) Deadline() (time.Time, bool) { return c.deadline, true }
+func (c *handlerContext) Done() <-chan struct{}       { return c.done }
+
+func (c *handlerContext) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
+// abandon makes the context done with err.
+func (c *handlerContext) abandon(err error) {
+	c.once.Do(func() {
+		c.err = err
+		close(c.done)
+	})
+}
+
+// invoke calls handler with resp. If a HandlerTimeout is set the handler
+// runs on its own goroutine and is abandoned, with its Response's context
+// done, once the timeout expires, so that it does not hold up the handlers
+// after it or the answer to the webhook. It returns an error wrapping
+// ErrHandlerTimeout when the handler timed out, or the error of the
+// Response's context when it was done first.
+func (m *Messenger) invoke(a Action, resp *Response, handler func(*Response)) error {
+	if m.handlerTimeout <= 0 {
+		handler(resp)
+		return nil
+	}
+
+	ctx := newHandlerContext(resp.Context(), m.handlerTimeout)
+	r := resp.WithContext(ctx)
+	timer := time.NewTimer(m.handlerTimeout)
+	defer timer.Stop()
+
+	var panicked interface{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if p := recover(); p != nil {
+				panicked = p
+			}
+		}()
+		handler(r)
+	}()
+
+	select {
+	case <-done:
+		if panicked != nil {
+			panic(panicked)
+		}
+		return nil
+	case <-resp.Context().Done():
+		// The caller gave up on the event before the handler timed out.
+		ctx.abandon(resp.Context().Err())
+		return resp.Context().Err()
+	case <-timer.C:
+		ctx.abandon(context.DeadlineExceeded)
+		err := xerrors.Errorf("handler for %s: %w", m.psidHasher.Hash(resp.to.ID), ErrHandlerTimeout)
+		m.recordError(err)
+		logEvent(resp.Context(), err)
+		if m.onHandlerTimeout != nil {
+			m.onHandlerTimeout(HandlerTimeout{Action: a, PSID: resp.to.ID, Timeout: m.handlerTimeout})
+		}
+		return err
+	}
+}
@@ -0,0 +1,114 @@
+package messenger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_HandlerTimeout(t *testing.T) {
+	var timeouts []HandlerTimeout
+	m := New(Options{
+		HandlerTimeout: 20 * time.Millisecond,
+		OnHandlerTimeout: func(ht HandlerTimeout) {
+			timeouts = append(timeouts, ht)
+		},
+	})
+
+	abandoned := make(chan error, 1)
+	m.HandleMessage(func(msg Message, r *Response) {
+		<-r.Context().Done()
+		abandoned <- r.Context().Err()
+	})
+	var texts []string
+	m.HandleMessage(func(msg Message, r *Response) {
+		texts = append(texts, msg.Text)
+	})
+
+	serveFixture(t, m, "message_text.json")
+
+	assert.Equal(t, []string{"hello, world!"}, texts)
+	require.Len(t, timeouts, 1)
+	assert.Equal(t, HandlerTimeout{Action: TextAction, PSID: fixturePSID, Timeout: 20 * time.Millisecond}, timeouts[0])
+	assert.Equal(t, context.DeadlineExceeded, <-abandoned)
+}
+
+func TestMessenger_HandlerTimeoutCancelled(t *testing.T) {
+	var timeouts []HandlerTimeout
+	m := New(Options{
+		HandlerTimeout: time.Second,
+		OnHandlerTimeout: func(ht HandlerTimeout) {
+			timeouts = append(timeouts, ht)
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := m.Response(fixturePSID).WithContext(ctx)
+	m.invoke(TextAction, resp, func(r *Response) {
+		cancel()
+		<-r.Context().Done()
+	})
+
+	assert.Empty(t, timeouts)
+	assert.Empty(t, m.RecentErrors())
+}
+
+func TestMessenger_HandlerTimeoutPanic(t *testing.T) {
+	m := New(Options{HandlerTimeout: time.Second})
+	resp := m.Response(fixturePSID)
+
+	assert.Panics(t, func() {
+		m.invoke(TextAction, resp, func(*Response) { panic("boom") })
+	})
+}
+
+func TestResponse_Context(t *testing.T) {
+	r := NewResponse(ResponseOptions{Recipient: Recipient{ID: 111}})
+	assert.Equal(t, context.Background(), r.Context())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := r.WithContext(ctx).WithPersona("42")
+	assert.Equal(t, ctx, c.Context())
+	assert.Error(t, c.Text("hello", ResponseType))
+}
+
+func TestResponse_SendAfterWebhook(t *testing.T) {
+	graph := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient_id":"1254459154682919","message_id":"m_later"}`))
+	}))
+	defer graph.Close()
+
+	for name, timeout := range map[string]time.Duration{"no timeout": 0, "timeout": time.Second} {
+		t.Run(name, func(t *testing.T) {
+			m := New(Options{Endpoints: MockEndpoints(graph.URL), HandlerTimeout: timeout})
+
+			release := make(chan struct{})
+			sent := make(chan error, 1)
+			m.HandleMessage(func(msg Message, r *Response) {
+				go func() {
+					<-release
+					sent <- r.Text("later", ResponseType)
+				}()
+			})
+
+			webhook := httptest.NewServer(m.Handler())
+			defer webhook.Close()
+			body, err := os.Open("testdata/webhooks/message_text.json")
+			require.NoError(t, err)
+			defer body.Close()
+			resp, err := http.Post(webhook.URL, "application/json", body)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			close(release)
+			assert.NoError(t, <-sent)
+		})
+	}
+}
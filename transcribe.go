@@ -0,0 +1,47 @@
+package messenger
+
+import (
+	"context"
+	"strings"
+)
+
+// Transcriber converts the audio of an incoming attachment into text. It is
+// invoked for every audio attachment before any MessageHandler runs, making
+// the result available in Message.TranscribedText.
+type Transcriber interface {
+	Transcribe(a Attachment) (string, error)
+}
+
+// TranscriberFunc is an adapter to allow the use of ordinary functions as a
+// Transcriber.
+type TranscriberFunc func(a Attachment) (string, error)
+
+// Transcribe calls f(a).
+func (f TranscriberFunc) Transcribe(a Attachment) (string, error) {
+	return f(a)
+}
+
+// transcribe runs the configured Transcriber over the audio attachments of
+// msg, joining the transcripts of multiple voice notes with newlines.
+func (m *Messenger) transcribe(ctx context.Context, msg *Message) {
+	if m.transcriber == nil {
+		return
+	}
+
+	var texts []string
+	for _, a := range msg.Attachments {
+		if a.Type != string(AudioAttachment) {
+			continue
+		}
+
+		text, err := m.transcriber.Transcribe(a)
+		if err != nil {
+			logEvent(ctx, "could not transcribe audio:", err)
+			continue
+		}
+
+		texts = append(texts, text)
+	}
+
+	msg.TranscribedText = strings.Join(texts, "\n")
+}
@@ -0,0 +1,32 @@
+package messenger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/xerrors"
+)
+
+func TestMessenger_Transcribe(t *testing.T) {
+	m := New(Options{
+		Transcriber: TranscriberFunc(func(a Attachment) (string, error) {
+			if a.Payload.URL == "broken" {
+				return "", xerrors.New("could not fetch audio")
+			}
+			return "transcript of " + a.Payload.URL, nil
+		}),
+	})
+
+	msg := Message{
+		Attachments: []Attachment{
+			{Type: "audio", Payload: Payload{URL: "first"}},
+			{Type: "image", Payload: Payload{URL: "picture"}},
+			{Type: "audio", Payload: Payload{URL: "broken"}},
+			{Type: "audio", Payload: Payload{URL: "second"}},
+		},
+	}
+	m.transcribe(context.Background(), &msg)
+
+	assert.Equal(t, "transcript of first\ntranscript of second", msg.TranscribedText)
+}
@@ -0,0 +1,124 @@
+package messenger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// TranscriptInbound marks a record of a webhook event received from a user.
+	TranscriptInbound = "inbound"
+	// TranscriptOutbound marks a record of a message sent to a user.
+	TranscriptOutbound = "outbound"
+)
+
+// TranscriptRecord is a single line of a conversation transcript.
+type TranscriptRecord struct {
+	// Time is when the record was written.
+	Time time.Time `json:"time"`
+	// Direction is either TranscriptInbound or TranscriptOutbound.
+	Direction string `json:"direction"`
+	// PSID is the page-scoped ID of the user on the other end of the
+	// conversation.
+	PSID int64 `json:"psid,string"`
+	// Event is the webhook event or the message which was sent.
+	Event interface{} `json:"event"`
+	// Error is the reason the message could not be sent, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// TranscriptWriter streams every dispatched event and every send as JSON
+// Lines to an io.Writer. It is safe for concurrent use.
+type TranscriptWriter struct {
+	// RotateAfter is the number of bytes after which the transcript is
+	// rotated. Zero disables rotation.
+	RotateAfter int64
+	// OnRotate is called when the transcript needs to be rotated, and returns
+	// the writer subsequent records are written to. The previous writer is
+	// closed if it implements io.Closer.
+	OnRotate func() (io.Writer, error)
+
+	mu      sync.Mutex
+	w       io.Writer
+	written int64
+}
+
+// NewTranscriptWriter creates a TranscriptWriter which writes to w.
+func NewTranscriptWriter(w io.Writer) *TranscriptWriter {
+	return &TranscriptWriter{w: w}
+}
+
+// Write appends a record to the transcript, rotating it beforehand if
+// needed.
+func (t *TranscriptWriter) Write(rec TranscriptRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.RotateAfter > 0 && t.written > 0 && t.written+int64(len(line)) > t.RotateAfter && t.OnRotate != nil {
+		if err := t.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := t.w.Write(line)
+	t.written += int64(n)
+	return err
+}
+
+// Rotate closes the current writer, if possible, and continues the transcript
+// on the writer returned by OnRotate.
+func (t *TranscriptWriter) Rotate() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rotate()
+}
+
+func (t *TranscriptWriter) rotate() error {
+	if t.OnRotate == nil {
+		return nil
+	}
+
+	w, err := t.OnRotate()
+	if err != nil {
+		return err
+	}
+
+	if c, ok := t.w.(io.Closer); ok {
+		c.Close()
+	}
+
+	t.w = w
+	t.written = 0
+	return nil
+}
+
+// writeTranscript records an event in the configured transcript, if any.
+func (m *Messenger) writeTranscript(direction string, psid int64, event interface{}, sendErr error) {
+	if m.transcript == nil {
+		return
+	}
+
+	rec := TranscriptRecord{
+		Time:      m.now(),
+		Direction: direction,
+		PSID:      psid,
+		Event:     event,
+	}
+	if sendErr != nil {
+		rec.Error = sendErr.Error()
+	}
+
+	if err := m.transcript.Write(rec); err != nil {
+		fmt.Println("could not write transcript:", err)
+	}
+}
@@ -0,0 +1,51 @@
+package messenger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptWriter(t *testing.T) {
+	var first, second bytes.Buffer
+
+	tw := NewTranscriptWriter(&first)
+	tw.RotateAfter = 100
+	tw.OnRotate = func() (io.Writer, error) {
+		return &second, nil
+	}
+
+	m := &Messenger{transcript: tw}
+	m.dispatch(Receive{
+		Entry: []Entry{
+			{
+				Messaging: []MessageInfo{
+					{
+						Sender:    Sender{111},
+						Recipient: Recipient{ID: 222},
+						Message:   &Message{Text: "hello"},
+					},
+				},
+			},
+		},
+	})
+	m.afterSend(Recipient{ID: 111}, SendMessage{Message: MessageData{Text: "hi!"}}, nil)
+
+	lines := strings.Split(strings.TrimSpace(first.String()), "\n")
+	require.Len(t, lines, 1)
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	assert.Equal(t, TranscriptInbound, rec["direction"])
+	assert.Equal(t, "111", rec["psid"])
+
+	lines = strings.Split(strings.TrimSpace(second.String()), "\n")
+	require.Len(t, lines, 1)
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &rec))
+	assert.Equal(t, TranscriptOutbound, rec["direction"])
+}
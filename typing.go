@@ -0,0 +1,137 @@
+package messenger
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// TypingOnAction turns the typing indicator on.
+	TypingOnAction = "typing_on"
+	// TypingOffAction turns the typing indicator off.
+	TypingOffAction = "typing_off"
+	// MarkSeenAction marks the last message as read.
+	MarkSeenAction = "mark_seen"
+
+	// TypingTimeout is how long Facebook displays the typing indicator for
+	// before clearing it on its own.
+	TypingTimeout = 20 * time.Second
+)
+
+// typingRefresh is how often a kept alive typing indicator is turned on
+// again, comfortably within TypingTimeout.
+const typingRefresh = 15 * time.Second
+
+// Typing is a typing indicator displayed to a user, as started by
+// Response.TypingOn.
+type Typing struct {
+	r         *Response
+	started   time.Time
+	keepAlive bool
+	stop      chan struct{}
+	// done is closed when the goroutine refreshing a kept alive indicator
+	// exits.
+	done chan struct{}
+	once sync.Once
+}
+
+// TypingOn displays the typing indicator. Facebook clears it on its own after
+// TypingTimeout, unless keepAlive is set, in which case it is refreshed until
+// the returned Typing is stopped or a message is sent by r.
+func (r *Response) TypingOn(keepAlive bool) (*Typing, error) {
+	if err := r.SenderAction(TypingOnAction); err != nil {
+		return nil, err
+	}
+
+	t := &Typing{
+		r:         r,
+		started:   r.now(),
+		keepAlive: keepAlive,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	r.mu.Lock()
+	previous := r.typing
+	r.typing = t
+	r.mu.Unlock()
+
+	if previous != nil {
+		previous.end()
+	}
+
+	if keepAlive {
+		go t.refresh()
+	}
+
+	return t, nil
+}
+
+// Stop turns the typing indicator off, unless it has already been cleared.
+func (t *Typing) Stop() error {
+	if !t.end() {
+		return nil
+	}
+
+	if !t.keepAlive && t.r.now().Sub(t.started) >= TypingTimeout {
+		return nil
+	}
+
+	return t.r.SenderAction(TypingOffAction)
+}
+
+// end stops refreshing the indicator, reporting whether it was still going.
+func (t *Typing) end() bool {
+	ended := false
+	t.once.Do(func() {
+		close(t.stop)
+		ended = true
+	})
+	return ended
+}
+
+func (t *Typing) refresh() {
+	ticks, stop := t.r.tick(typingRefresh)
+	defer stop()
+	defer close(t.done)
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticks:
+			if err := t.r.SenderAction(TypingOnAction); err != nil {
+				logEvent(t.r.Context(), "could not refresh typing indicator:", err)
+			}
+		}
+	}
+}
+
+// stopTyping stops refreshing the typing indicator of r, as sending a message
+// clears it.
+func (r *Response) stopTyping() {
+	r.mu.Lock()
+	t := r.typing
+	r.typing = nil
+	r.mu.Unlock()
+
+	if t != nil {
+		t.end()
+	}
+}
+
+// now returns the current time according to the Messenger of r, if any.
+func (r *Response) now() time.Time {
+	if r.messenger == nil {
+		return time.Now()
+	}
+	return r.messenger.now()
+}
+
+// tick ticks every d according to the Messenger of r, if any.
+func (r *Response) tick(d time.Duration) (<-chan time.Time, func()) {
+	if r.messenger == nil {
+		return SystemClock{}.Tick(d)
+	}
+	return r.messenger.tick(d)
+}
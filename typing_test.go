@@ -0,0 +1,99 @@
+package messenger
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// senderActions returns the sender actions sent to graph, in order.
+func senderActions(graph *fakeGraph) []string {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+
+	var actions []string
+	for _, body := range graph.bodies {
+		for _, action := range []string{TypingOnAction, TypingOffAction} {
+			if strings.Contains(body, `"sender_action":"`+action+`"`) {
+				actions = append(actions, action)
+			}
+		}
+	}
+	return actions
+}
+
+// waitDone waits for the refresh goroutine of typing to exit.
+func waitDone(t *testing.T, typing *Typing) {
+	select {
+	case <-typing.done:
+	case <-time.After(time.Second):
+		t.Fatal("typing indicator still refreshed")
+	}
+}
+
+func TestResponse_TypingOn(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client(), Clock: newFakeClock()})
+	r := m.Response(fixturePSID)
+
+	typing, err := r.TypingOn(false)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"recipient":{"id":"1254459154682919"},"sender_action":"typing_on"}`, graph.bodies[0])
+
+	require.NoError(t, typing.Stop())
+	require.NoError(t, typing.Stop())
+	assert.Equal(t, []string{TypingOnAction, TypingOffAction}, senderActions(graph))
+}
+
+func TestResponse_TypingOnExpired(t *testing.T) {
+	graph := newFakeGraph(`{}`)
+	clock := newFakeClock()
+	m := New(Options{HTTPClient: graph.client(), Clock: clock})
+
+	typing, err := m.Response(fixturePSID).TypingOn(false)
+	require.NoError(t, err)
+	clock.Advance(TypingTimeout)
+
+	// Facebook already cleared the indicator.
+	require.NoError(t, typing.Stop())
+	assert.Equal(t, []string{TypingOnAction}, senderActions(graph))
+}
+
+func TestResponse_TypingOnKeepAlive(t *testing.T) {
+	t.Parallel()
+	clock := newTickingClock()
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client(), Clock: clock})
+
+	typing, err := m.Response(fixturePSID).TypingOn(true)
+	require.NoError(t, err)
+	clock.ticks <- time.Time{}
+	clock.ticks <- time.Time{}
+
+	require.NoError(t, typing.Stop())
+	waitDone(t, typing)
+	actions := senderActions(graph)
+	assert.Len(t, actions, 4)
+	assert.Equal(t, 3, strings.Count(strings.Join(actions, " "), TypingOnAction))
+	assert.Equal(t, 1, strings.Count(strings.Join(actions, " "), TypingOffAction))
+}
+
+func TestResponse_TypingStoppedBySend(t *testing.T) {
+	t.Parallel()
+	graph := newFakeGraph(`{}`)
+	m := New(Options{HTTPClient: graph.client(), Clock: newTickingClock()})
+	r := m.Response(fixturePSID)
+
+	typing, err := r.TypingOn(true)
+	require.NoError(t, err)
+	require.NoError(t, r.Text("hello", ResponseType))
+	waitDone(t, typing)
+
+	// Sending the message cleared the indicator, so none is turned off.
+	require.NoError(t, typing.Stop())
+	assert.Equal(t, []string{TypingOnAction}, senderActions(graph))
+	assert.Equal(t, 2, graph.count())
+}
@@ -0,0 +1,161 @@
+package messenger
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// knownEventFields are the fields of the webhook events the Messenger
+// classifies.
+var knownEventFields = map[string]bool{
+	"sender":                 true,
+	"recipient":              true,
+	"timestamp":              true,
+	"message":                true,
+	"delivery":               true,
+	"postback":               true,
+	"read":                   true,
+	"optin":                  true,
+	"referral":               true,
+	"account_linking":        true,
+	"pass_thread_control":    true,
+	"request_thread_control": true,
+	"payment":                true,
+	"pre_checkout":           true,
+}
+
+// UnknownEvent counts the webhook events with a field the Messenger does not
+// support yet, such as a field added to the Messenger Platform since.
+type UnknownEvent struct {
+	// Field is the name of the field, such as "reaction", or "changes."
+	// followed by the field of a change to the page.
+	Field string `json:"field"`
+	// Count is the number of events with the field.
+	Count int64 `json:"count"`
+	// Sample is the first event with the field, kept when payloads are
+	// captured, see Options.CaptureRate. It contains the data of users and
+	// must be handled accordingly.
+	Sample string `json:"sample,omitempty"`
+}
+
+// unknownEvents counts the events with unknown fields, by field.
+type unknownEvents struct {
+	mu     sync.Mutex
+	events map[string]*UnknownEvent
+}
+
+// findUnknownFields remembers the fields of the events of rec which are not
+// classified, decoding body, the webhook rec was decoded from, a second time
+// only when there are such events.
+func (m *Messenger) findUnknownFields(body []byte, rec *Receive) {
+	unknown := false
+	for _, entry := range rec.Entry {
+		for _, events := range [][]MessageInfo{entry.Messaging, entry.Standby} {
+			for _, info := range events {
+				unknown = unknown || actionOf(info) == UnknownAction
+			}
+		}
+	}
+	if !unknown {
+		return
+	}
+
+	var raw struct {
+		Entry []struct {
+			Messaging []json.RawMessage `json:"messaging"`
+			Standby   []json.RawMessage `json:"standby"`
+		} `json:"entry"`
+	}
+	if err := m.jsonCodec().Unmarshal(body, &raw); err != nil || len(raw.Entry) != len(rec.Entry) {
+		return
+	}
+	for i, entry := range rec.Entry {
+		m.unknownFields(entry.Messaging, raw.Entry[i].Messaging)
+		m.unknownFields(entry.Standby, raw.Entry[i].Standby)
+	}
+}
+
+// unknownFields sets the unknown fields of the unclassified events, whose
+// encoded form is in raw.
+func (m *Messenger) unknownFields(events []MessageInfo, raw []json.RawMessage) {
+	if len(raw) != len(events) {
+		return
+	}
+
+	for i := range events {
+		info := &events[i]
+		if actionOf(*info) != UnknownAction {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		if err := m.jsonCodec().Unmarshal(raw[i], &fields); err != nil {
+			continue
+		}
+		for field := range fields {
+			if !knownEventFields[field] {
+				info.unknown = append(info.unknown, field)
+			}
+		}
+		sort.Strings(info.unknown)
+		info.raw = append([]byte(nil), raw[i]...)
+	}
+}
+
+// noteUnknown counts an event with unknown fields, keeping raw as a sample
+// of each of them when payloads are captured.
+func (m *Messenger) noteUnknown(fields []string, raw []byte) {
+	if len(fields) == 0 {
+		// the event has no field at all
+		fields = []string{""}
+	}
+
+	u := &m.unknownEvents
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.events == nil {
+		u.events = make(map[string]*UnknownEvent)
+	}
+	for _, field := range fields {
+		e, ok := u.events[field]
+		if !ok {
+			e = &UnknownEvent{Field: field}
+			if m.captures.rate > 0 {
+				e.Sample = string(raw)
+			}
+			u.events[field] = e
+		}
+		e.Count++
+	}
+}
+
+// UnknownEvents returns the webhook events received with fields the
+// Messenger does not support, sorted by field.
+func (m *Messenger) UnknownEvents() []UnknownEvent {
+	u := &m.unknownEvents
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	list := make([]UnknownEvent, 0, len(u.events))
+	for _, e := range u.events {
+		list = append(list, *e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Field < list[j].Field })
+	return list
+}
+
+// unknownCounts returns the number of events per unknown field.
+func (m *Messenger) unknownCounts() map[string]int64 {
+	events := m.UnknownEvents()
+	if len(events) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int64, len(events))
+	for _, e := range events {
+		counts[e.Field] = e.Count
+	}
+	return counts
+}
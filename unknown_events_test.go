@@ -0,0 +1,57 @@
+package messenger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessenger_UnknownEvents(t *testing.T) {
+	m := New(Options{CaptureRate: 1})
+	m.HandleMessage(func(Message, *Response) { t.Error("unknown event dispatched") })
+
+	serveFixture(t, m, "reaction.json")
+	serveFixture(t, m, "reaction.json")
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(
+		`{"object":"page","entry":[{"id":"1067280970047460","time":1543095111999,"changes":[{"field":"feed","value":{"item":"comment"}}]}]}`,
+	)))
+
+	fixture, err := os.ReadFile(filepath.Join("testdata", "webhooks", "reaction.json"))
+	require.NoError(t, err)
+	var rec struct {
+		Entry []struct {
+			Messaging []json.RawMessage `json:"messaging"`
+		} `json:"entry"`
+	}
+	require.NoError(t, json.Unmarshal(fixture, &rec))
+
+	assert.Equal(t, []UnknownEvent{
+		{Field: "changes.feed", Count: 1, Sample: `{"item":"comment"}`},
+		{Field: "reaction", Count: 2, Sample: string(rec.Entry[0].Messaging[0])},
+	}, m.UnknownEvents())
+	assert.Equal(t, map[string]int64{"changes.feed": 1, "reaction": 2}, m.EventStats().UnknownEvents)
+
+	req := httptest.NewRequest("GET", "/unknown", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	m.AdminHandler("secret").ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var events []UnknownEvent
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+	assert.Len(t, events, 2)
+}
+
+func TestMessenger_UnknownEventsWithoutCapture(t *testing.T) {
+	m := New(Options{})
+	serveFixture(t, m, "reaction.json")
+	serveFixture(t, m, "message_text.json")
+
+	assert.Equal(t, []UnknownEvent{{Field: "reaction", Count: 1}}, m.UnknownEvents())
+}
@@ -0,0 +1,8 @@
+module github.com/paked/messenger/v2
+
+go 1.16
+
+require (
+	github.com/paked/messenger v0.0.0-20261016113852-7ce3f680084f
+	github.com/stretchr/testify v1.2.2
+)
@@ -0,0 +1,250 @@
+// Package messenger is version 2 of the client for the Messenger Platform.
+//
+// Version 2 gathers the changes which could not be made to version 1
+// without breaking it: Messengers are configured with functional options,
+// handlers receive a context.Context, events are identified by a typed
+// EventType and every handler lives in a single registry.
+//
+// It is built on version 1, which keeps being maintained, and both can be
+// used side by side: Wrap turns a version 1 Messenger into a version 2 one,
+// and V1 goes the other way, so bots can be migrated one handler at a time.
+// See MIGRATION.md.
+package messenger
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	v1 "github.com/paked/messenger"
+)
+
+// The events and the Response are those of version 1.
+type (
+	Message         = v1.Message
+	Delivery        = v1.Delivery
+	Read            = v1.Read
+	PostBack        = v1.PostBack
+	OptIn           = v1.OptIn
+	ReferralMessage = v1.ReferralMessage
+	AccountLinking  = v1.AccountLinking
+	Response        = v1.Response
+	Recipient       = v1.Recipient
+	MessagingType   = v1.MessagingType
+)
+
+// The messaging types of version 1.
+const (
+	ResponseType   = v1.ResponseType
+	UpdateType     = v1.UpdateType
+	MessageTagType = v1.MessageTagType
+)
+
+// EventType is a type of webhook event.
+type EventType int
+
+// The types of webhook events.
+const (
+	EventMessage EventType = iota
+	EventDelivery
+	EventRead
+	EventPostBack
+	EventOptIn
+	EventReferral
+	EventAccountLinking
+)
+
+var eventNames = map[EventType]string{
+	EventMessage:        "message",
+	EventDelivery:       "delivery",
+	EventRead:           "read",
+	EventPostBack:       "postback",
+	EventOptIn:          "optin",
+	EventReferral:       "referral",
+	EventAccountLinking: "account_linking",
+}
+
+func (t EventType) String() string {
+	if name, ok := eventNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// HandlerFunc handles a webhook event: a Message, Delivery, Read, PostBack,
+// OptIn, ReferralMessage or AccountLinking, according to its EventType. The
+// context is the one of the Response.
+type HandlerFunc func(ctx context.Context, event interface{}, r *Response)
+
+// Option configures a Messenger.
+type Option func(*v1.Options)
+
+// WithToken sets the access token of the Page.
+func WithToken(token string) Option {
+	return func(o *v1.Options) { o.Token = token }
+}
+
+// WithVerifyToken sets the token Facebook uses to verify the webhook.
+func WithVerifyToken(token string) Option {
+	return func(o *v1.Options) { o.VerifyToken = token }
+}
+
+// WithAppSecret sets the secret of the app and turns on the verification of
+// the signatures of webhook requests.
+func WithAppSecret(secret string) Option {
+	return func(o *v1.Options) {
+		o.AppSecret = secret
+		o.Verify = true
+	}
+}
+
+// WithWebhookPath sets the path of the webhook on the mux. Defaults to "/".
+func WithWebhookPath(path string) Option {
+	return func(o *v1.Options) { o.WebhookURL = path }
+}
+
+// WithMux registers the webhook on mux.
+func WithMux(mux *http.ServeMux) Option {
+	return func(o *v1.Options) { o.Mux = mux }
+}
+
+// WithHTTPClient sets the client used for the Graph API.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *v1.Options) { o.HTTPClient = client }
+}
+
+// WithV1Options gives access to the settings of version 1 which have no
+// option of their own yet.
+func WithV1Options(f func(*v1.Options)) Option {
+	return Option(f)
+}
+
+// Messenger receives webhook events and dispatches them to handlers.
+type Messenger struct {
+	m *v1.Messenger
+
+	mu       sync.RWMutex
+	handlers map[EventType][]HandlerFunc
+}
+
+// New creates a Messenger.
+func New(opts ...Option) *Messenger {
+	var o v1.Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return Wrap(v1.New(o))
+}
+
+// Wrap returns a version 2 Messenger dispatching the events received by m,
+// in addition to the handlers registered on m.
+func Wrap(m *v1.Messenger) *Messenger {
+	return &Messenger{m: m, handlers: make(map[EventType][]HandlerFunc)}
+}
+
+// V1 returns the version 1 Messenger, for the features version 2 does not
+// expose yet.
+func (m *Messenger) V1() *v1.Messenger {
+	return m.m
+}
+
+// Handler returns the HTTP handler of the webhook.
+func (m *Messenger) Handler() http.Handler {
+	return m.m.Handler()
+}
+
+// Response returns a Response sending messages to the user with the given
+// page-scoped ID.
+func (m *Messenger) Response(psid int64) *Response {
+	return m.m.Response(psid)
+}
+
+// Handle adds a handler for events of type t.
+func (m *Messenger) Handle(t EventType, h HandlerFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.handlers[t]) == 0 {
+		m.subscribe(t)
+	}
+	m.handlers[t] = append(m.handlers[t], h)
+}
+
+// HandlerCounts returns the number of handlers registered per event type.
+func (m *Messenger) HandlerCounts() map[EventType]int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[EventType]int, len(m.handlers))
+	for t, hs := range m.handlers {
+		counts[t] = len(hs)
+	}
+	return counts
+}
+
+// dispatch calls the handlers of events of type t.
+func (m *Messenger) dispatch(t EventType, event interface{}, r *Response) {
+	m.mu.RLock()
+	handlers := m.handlers[t]
+	m.mu.RUnlock()
+
+	for _, h := range handlers {
+		h(r.Context(), event, r)
+	}
+}
+
+// subscribe makes the version 1 Messenger pass the events of type t to the
+// registry.
+func (m *Messenger) subscribe(t EventType) {
+	switch t {
+	case EventMessage:
+		m.m.HandleMessage(func(e Message, r *Response) { m.dispatch(t, e, r) })
+	case EventDelivery:
+		m.m.HandleDelivery(func(e Delivery, r *Response) { m.dispatch(t, e, r) })
+	case EventRead:
+		m.m.HandleRead(func(e Read, r *Response) { m.dispatch(t, e, r) })
+	case EventPostBack:
+		m.m.HandlePostBack(func(e PostBack, r *Response) { m.dispatch(t, e, r) })
+	case EventOptIn:
+		m.m.HandleOptIn(func(e OptIn, r *Response) { m.dispatch(t, e, r) })
+	case EventReferral:
+		m.m.HandleReferral(func(e ReferralMessage, r *Response) { m.dispatch(t, e, r) })
+	case EventAccountLinking:
+		m.m.HandleAccountLinking(func(e AccountLinking, r *Response) { m.dispatch(t, e, r) })
+	}
+}
+
+// HandleMessage adds a handler for messages.
+func (m *Messenger) HandleMessage(f func(context.Context, Message, *Response)) {
+	m.Handle(EventMessage, func(ctx context.Context, e interface{}, r *Response) { f(ctx, e.(Message), r) })
+}
+
+// HandleDelivery adds a handler for delivery receipts.
+func (m *Messenger) HandleDelivery(f func(context.Context, Delivery, *Response)) {
+	m.Handle(EventDelivery, func(ctx context.Context, e interface{}, r *Response) { f(ctx, e.(Delivery), r) })
+}
+
+// HandleRead adds a handler for read receipts.
+func (m *Messenger) HandleRead(f func(context.Context, Read, *Response)) {
+	m.Handle(EventRead, func(ctx context.Context, e interface{}, r *Response) { f(ctx, e.(Read), r) })
+}
+
+// HandlePostBack adds a handler for postbacks.
+func (m *Messenger) HandlePostBack(f func(context.Context, PostBack, *Response)) {
+	m.Handle(EventPostBack, func(ctx context.Context, e interface{}, r *Response) { f(ctx, e.(PostBack), r) })
+}
+
+// HandleOptIn adds a handler for opt-ins.
+func (m *Messenger) HandleOptIn(f func(context.Context, OptIn, *Response)) {
+	m.Handle(EventOptIn, func(ctx context.Context, e interface{}, r *Response) { f(ctx, e.(OptIn), r) })
+}
+
+// HandleReferral adds a handler for referrals.
+func (m *Messenger) HandleReferral(f func(context.Context, ReferralMessage, *Response)) {
+	m.Handle(EventReferral, func(ctx context.Context, e interface{}, r *Response) { f(ctx, e.(ReferralMessage), r) })
+}
+
+// HandleAccountLinking adds a handler for account linking events.
+func (m *Messenger) HandleAccountLinking(f func(context.Context, AccountLinking, *Response)) {
+	m.Handle(EventAccountLinking, func(ctx context.Context, e interface{}, r *Response) { f(ctx, e.(AccountLinking), r) })
+}
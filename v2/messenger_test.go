@@ -0,0 +1,63 @@
+package messenger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/paked/messenger"
+	"github.com/stretchr/testify/assert"
+)
+
+const messageWebhook = `{"object":"page","entry":[{"id":"1067280970047460","time":1543095111999,"messaging":[{"sender":{"id":"1254459154682919"},"recipient":{"id":"1067280970047460"},"timestamp":1543095111999,"message":{"mid":"m_1","text":"hello"}}]}]}`
+
+func TestMessenger(t *testing.T) {
+	mux := http.NewServeMux()
+	m := New(WithToken("token"), WithMux(mux), WithWebhookPath("/webhook"))
+
+	var calls []string
+	m.HandleMessage(func(ctx context.Context, msg Message, r *Response) {
+		assert.NotNil(t, ctx)
+		calls = append(calls, "typed "+msg.Text)
+	})
+	m.Handle(EventMessage, func(ctx context.Context, e interface{}, r *Response) {
+		calls = append(calls, "generic "+e.(Message).Text)
+	})
+	m.HandlePostBack(func(ctx context.Context, p PostBack, r *Response) {
+		calls = append(calls, "postback")
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/webhook", strings.NewReader(messageWebhook)))
+
+	assert.Equal(t, []string{"typed hello", "generic hello"}, calls)
+	assert.Equal(t, map[EventType]int{EventMessage: 2, EventPostBack: 1}, m.HandlerCounts())
+	assert.Equal(t, map[string]int{"message": 1, "postback": 1}, nonZero(m.V1().HandlerCounts()))
+	assert.Equal(t, "postback", EventPostBack.String())
+}
+
+func TestWrap(t *testing.T) {
+	old := v1.New(v1.Options{})
+
+	var calls []string
+	old.HandleMessage(func(msg v1.Message, r *v1.Response) {
+		calls = append(calls, "v1")
+	})
+	m := Wrap(old)
+	m.HandleMessage(func(ctx context.Context, msg Message, r *Response) {
+		calls = append(calls, "v2")
+	})
+
+	m.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(messageWebhook)))
+	assert.Equal(t, []string{"v1", "v2"}, calls)
+}
+
+func nonZero(counts map[string]int) map[string]int {
+	for k, v := range counts {
+		if v == 0 {
+			delete(counts, k)
+		}
+	}
+	return counts
+}
@@ -0,0 +1,114 @@
+package messenger
+
+import (
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// OptionsError is returned by Options.Validate, listing every problem found
+// with the Options.
+type OptionsError struct {
+	Problems []string
+}
+
+func (e *OptionsError) Error() string {
+	return "invalid options: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks that the Options are complete and well formed for a bot
+// serving real traffic, returning an *OptionsError listing every problem
+// found. New does not validate its Options, so that tests can leave them
+// empty.
+func (mo Options) Validate() error {
+	var problems []string
+	problem := func(problem string) {
+		problems = append(problems, problem)
+	}
+
+	switch {
+	case mo.Token == "":
+		problem("Token is empty")
+	case strings.ContainsAny(mo.Token, " \t\r\n"):
+		problem("Token contains whitespace")
+	}
+
+	switch {
+	case mo.VerifyToken == "":
+		problem("VerifyToken is empty")
+	case strings.TrimSpace(mo.VerifyToken) != mo.VerifyToken:
+		problem("VerifyToken starts or ends with whitespace")
+	}
+
+	switch {
+	case mo.AppSecret == "" && mo.Verify:
+		problem("AppSecret is empty, but Verify is set")
+	case mo.AppSecret != "" && !isAppSecret(mo.AppSecret):
+		problem("AppSecret is not 32 hexadecimal characters")
+	}
+
+	if p := mo.WebhookURL; p != "" && (!strings.HasPrefix(p, "/") || strings.ContainsAny(p, "?# \t")) {
+		problem("WebhookURL " + p + " is not a path")
+	}
+
+	if mo.Proxy != nil {
+		switch mo.Proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			problem("Proxy " + mo.Proxy.String() + " is not an HTTP, HTTPS or SOCKS5 URL")
+		}
+	}
+
+	for _, host := range mo.GraphHosts {
+		if host == "" || strings.ContainsAny(host, "/?# ") {
+			problem("GraphHosts entry " + host + " is not a host")
+		}
+	}
+
+	if mo.Endpoints.GraphURL != "" {
+		u, err := url.Parse(mo.Endpoints.GraphURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problem("Endpoints GraphURL " + mo.Endpoints.GraphURL + " is not an HTTP or HTTPS URL")
+		}
+	}
+
+	if mo.CaptureRate < 0 || mo.CaptureRate > 1 {
+		problem("CaptureRate is not between 0 and 1")
+	}
+	if mo.CaptureSize < 0 {
+		problem("CaptureSize is negative")
+	}
+	if mo.MaxBodySize < 0 {
+		problem("MaxBodySize is negative")
+	}
+	if mo.MaxPayloadSize < 0 {
+		problem("MaxPayloadSize is negative")
+	}
+	if mo.HandlerTimeout < 0 {
+		problem("HandlerTimeout is negative")
+	}
+	if mo.MaxEventAge < 0 {
+		problem("MaxEventAge is negative")
+	}
+
+	if len(problems) > 0 {
+		return &OptionsError{Problems: problems}
+	}
+	return nil
+}
+
+// NewWithValidation creates a new Messenger like New, once the Options pass
+// Validate.
+func NewWithValidation(mo Options) (*Messenger, error) {
+	if err := mo.Validate(); err != nil {
+		return nil, err
+	}
+	return New(mo), nil
+}
+
+// isAppSecret reports whether secret looks like the secret of a Facebook
+// app.
+func isAppSecret(secret string) bool {
+	_, err := hex.DecodeString(secret)
+	return err == nil && len(secret) == 32
+}
@@ -0,0 +1,65 @@
+package messenger
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	valid := Options{
+		Token:       "EAAGm0PX4ZCpsBA",
+		VerifyToken: "verify-me",
+		AppSecret:   "0123456789abcdef0123456789abcdef",
+		Verify:      true,
+		WebhookURL:  "/webhook",
+	}
+	require.NoError(t, valid.Validate())
+
+	m, err := NewWithValidation(valid)
+	require.NoError(t, err)
+	assert.NotNil(t, m)
+
+	for name, test := range map[string]struct {
+		change   func(*Options)
+		problems []string
+	}{
+		"empty": {func(o *Options) { *o = Options{Verify: true} }, []string{
+			"Token is empty",
+			"VerifyToken is empty",
+			"AppSecret is empty, but Verify is set",
+		}},
+		"whitespace": {func(o *Options) { o.Token += "\n"; o.VerifyToken = " " + o.VerifyToken }, []string{
+			"Token contains whitespace",
+			"VerifyToken starts or ends with whitespace",
+		}},
+		"app secret":   {func(o *Options) { o.AppSecret = "secret" }, []string{"AppSecret is not 32 hexadecimal characters"}},
+		"webhook":      {func(o *Options) { o.WebhookURL = "webhook?x=1" }, []string{"WebhookURL webhook?x=1 is not a path"}},
+		"proxy":        {func(o *Options) { o.Proxy = &url.URL{Scheme: "ftp", Host: "proxy"} }, []string{"Proxy ftp://proxy is not an HTTP, HTTPS or SOCKS5 URL"}},
+		"graph hosts":  {func(o *Options) { o.GraphHosts = []string{"https://graph.facebook.com"} }, []string{"GraphHosts entry https://graph.facebook.com is not a host"}},
+		"endpoints":    {func(o *Options) { o.Endpoints = MockEndpoints("localhost:8080") }, []string{"Endpoints GraphURL localhost:8080 is not an HTTP or HTTPS URL"}},
+		"capture rate": {func(o *Options) { o.CaptureRate = 2 }, []string{"CaptureRate is not between 0 and 1"}},
+		"negative":     {func(o *Options) { o.MaxBodySize = -1; o.HandlerTimeout = -1 }, []string{"MaxBodySize is negative", "HandlerTimeout is negative"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			o := valid
+			test.change(&o)
+
+			err := o.Validate()
+			var oe *OptionsError
+			require.True(t, xerrors.As(err, &oe))
+			assert.Equal(t, test.problems, oe.Problems)
+
+			_, err = NewWithValidation(o)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOptionsError(t *testing.T) {
+	err := &OptionsError{Problems: []string{"Token is empty", "VerifyToken is empty"}}
+	assert.EqualError(t, err, "invalid options: Token is empty; VerifyToken is empty")
+}
@@ -0,0 +1,108 @@
+package messenger
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/xerrors"
+)
+
+// VerifyRequestPolicy decides whether a webhook verification request may be
+// answered, for deployments which require more than the verify token.
+type VerifyRequestPolicy interface {
+	AllowVerifyRequest(r *http.Request) error
+}
+
+// VerifyRequestPolicyFunc is an adapter to allow the use of ordinary
+// functions as a VerifyRequestPolicy.
+type VerifyRequestPolicyFunc func(r *http.Request) error
+
+// AllowVerifyRequest calls f(r).
+func (f VerifyRequestPolicyFunc) AllowVerifyRequest(r *http.Request) error {
+	return f(r)
+}
+
+// AllowIPRanges returns a policy which only allows verification requests
+// coming from the given CIDR ranges. The remote address of the request is
+// used, so deployments behind a proxy need to restore it beforehand.
+func AllowIPRanges(cidrs ...string) (VerifyRequestPolicy, error) {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+
+	return VerifyRequestPolicyFunc(func(r *http.Request) error {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return xerrors.Errorf("invalid remote address %s", r.RemoteAddr)
+		}
+
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return nil
+			}
+		}
+
+		return xerrors.Errorf("remote address %s is not allowed", ip)
+	}), nil
+}
+
+// RequireQueryParams returns a policy which only allows verification
+// requests carrying the given query parameters with the given values.
+func RequireQueryParams(params map[string]string) VerifyRequestPolicy {
+	return VerifyRequestPolicyFunc(func(r *http.Request) error {
+		query := r.URL.Query()
+		for k, v := range params {
+			if query.Get(k) != v {
+				return xerrors.Errorf("missing or unexpected query parameter %s", k)
+			}
+		}
+		return nil
+	})
+}
+
+// AllVerifyRequestPolicies returns a policy which only allows requests
+// allowed by every one of policies.
+func AllVerifyRequestPolicies(policies ...VerifyRequestPolicy) VerifyRequestPolicy {
+	return VerifyRequestPolicyFunc(func(r *http.Request) error {
+		for _, p := range policies {
+			if err := p.AllowVerifyRequest(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// handleVerify answers a webhook verification request once the
+// VerifyRequestPolicy allows it, and reports its outcome to OnVerify.
+func (m *Messenger) handleVerify(w http.ResponseWriter, r *http.Request) {
+	if m.verifyPolicy != nil {
+		if err := m.verifyPolicy.AllowVerifyRequest(r); err != nil {
+			m.recordError(xerrors.Errorf("verification request rejected: %w", err))
+			fmt.Println("verification request rejected:", err)
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintln(w, "Forbidden.")
+			m.notifyVerify(false, r)
+			return
+		}
+	}
+
+	m.notifyVerify(m.verifyHandler(w, r), r)
+}
+
+func (m *Messenger) notifyVerify(success bool, r *http.Request) {
+	if m.onVerify != nil {
+		m.onVerify(success, r.RemoteAddr)
+	}
+}
@@ -0,0 +1,87 @@
+package messenger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRequestPolicy(t *testing.T) {
+	ips, err := AllowIPRanges("10.0.0.0/8", "2001:db8::/32")
+	require.NoError(t, err)
+
+	m := New(Options{
+		VerifyToken: "token",
+		VerifyRequestPolicy: AllVerifyRequestPolicies(
+			ips,
+			RequireQueryParams(map[string]string{"hub.mode": "subscribe"}),
+		),
+	})
+
+	for name, test := range map[string]struct {
+		remoteAddr string
+		query      string
+		code       int
+	}{
+		"allowed":        {"10.1.2.3:1234", "hub.mode=subscribe", http.StatusOK},
+		"allowed ipv6":   {"[2001:db8::1]:1234", "hub.mode=subscribe", http.StatusOK},
+		"unknown ip":     {"192.168.1.1:1234", "hub.mode=subscribe", http.StatusForbidden},
+		"missing params": {"10.1.2.3:1234", "", http.StatusForbidden},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/?hub.verify_token=token&hub.challenge=42&"+test.query, nil)
+			req.RemoteAddr = test.remoteAddr
+
+			w := httptest.NewRecorder()
+			m.Handler().ServeHTTP(w, req)
+
+			assert.Equal(t, test.code, w.Code)
+			if test.code == http.StatusOK {
+				assert.Equal(t, "42\n", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMessenger_OnVerify(t *testing.T) {
+	ips, err := AllowIPRanges("10.0.0.0/8")
+	require.NoError(t, err)
+
+	type attempt struct {
+		success    bool
+		remoteAddr string
+	}
+	var attempts []attempt
+	m := New(Options{
+		VerifyToken:         "token",
+		VerifyRequestPolicy: ips,
+		OnVerify: func(success bool, remoteAddr string) {
+			attempts = append(attempts, attempt{success, remoteAddr})
+		},
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/verify", m.VerifyHandlerFunc())
+
+	for _, test := range []struct {
+		remoteAddr string
+		token      string
+	}{
+		{"10.1.2.3:1234", "token"},
+		{"10.1.2.3:1234", "wrong"},
+		{"192.168.1.1:1234", "token"},
+	} {
+		req := httptest.NewRequest("GET", "/verify?hub.challenge=42&hub.verify_token="+test.token, nil)
+		req.RemoteAddr = test.remoteAddr
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, []attempt{
+		{true, "10.1.2.3:1234"},
+		{false, "10.1.2.3:1234"},
+		{false, "192.168.1.1:1234"},
+	}, attempts)
+}
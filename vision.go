@@ -0,0 +1,58 @@
+package messenger
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+)
+
+// ImageAnalysis is the result of analysing an incoming image attachment.
+type ImageAnalysis struct {
+	// Attachment is the image which was analysed.
+	Attachment Attachment
+	// Labels describe what was recognised in the image.
+	Labels []string
+	// Text is any text found in the image (OCR).
+	Text string
+}
+
+// ImageAnalyzer labels incoming image attachments and extracts their text.
+// It is invoked for every image attachment before any MessageHandler runs,
+// making the results available in Message.ImageAnalyses.
+type ImageAnalyzer interface {
+	AnalyzeImage(a Attachment) (ImageAnalysis, error)
+}
+
+// ImageAnalyzerFunc is an adapter to allow the use of ordinary functions as
+// an ImageAnalyzer.
+type ImageAnalyzerFunc func(a Attachment) (ImageAnalysis, error)
+
+// AnalyzeImage calls f(a).
+func (f ImageAnalyzerFunc) AnalyzeImage(a Attachment) (ImageAnalysis, error) {
+	return f(a)
+}
+
+// analyzeImages runs the configured ImageAnalyzer over the image attachments
+// of msg. Images which fail to be analysed are left out of the results, and
+// the failures are recorded in RecentErrors.
+func (m *Messenger) analyzeImages(ctx context.Context, msg *Message) {
+	if m.imageAnalyzer == nil {
+		return
+	}
+
+	for _, a := range msg.Attachments {
+		if a.Type != string(ImageAttachment) {
+			continue
+		}
+
+		analysis, err := m.imageAnalyzer.AnalyzeImage(a)
+		if err != nil {
+			logEvent(ctx, "could not analyze image:", err)
+			m.recordError(xerrors.Errorf("could not analyze image: %w", err))
+			continue
+		}
+
+		analysis.Attachment = a
+		msg.ImageAnalyses = append(msg.ImageAnalyses, analysis)
+	}
+}
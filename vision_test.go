@@ -0,0 +1,41 @@
+package messenger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestMessenger_AnalyzeImages(t *testing.T) {
+	var analyzed []string
+	m := New(Options{
+		ImageAnalyzer: ImageAnalyzerFunc(func(a Attachment) (ImageAnalysis, error) {
+			analyzed = append(analyzed, a.Payload.URL)
+			if a.Payload.URL == "broken" {
+				return ImageAnalysis{}, xerrors.New("could not fetch image")
+			}
+			return ImageAnalysis{Labels: []string{"cat"}, Text: "text of " + a.Payload.URL}, nil
+		}),
+	})
+
+	picture := Attachment{Type: "image", Payload: Payload{URL: "picture"}}
+	msg := Message{
+		Attachments: []Attachment{
+			picture,
+			{Type: "audio", Payload: Payload{URL: "voice"}},
+			{Type: "image", Payload: Payload{URL: "broken"}},
+			{Type: "file", Payload: Payload{URL: "document"}},
+		},
+	}
+	m.analyzeImages(context.Background(), &msg)
+
+	assert.Equal(t, []string{"picture", "broken"}, analyzed)
+	assert.Equal(t, []ImageAnalysis{{Attachment: picture, Labels: []string{"cat"}, Text: "text of picture"}}, msg.ImageAnalyses)
+
+	errs := m.RecentErrors()
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "could not fetch image")
+}